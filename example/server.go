@@ -38,5 +38,9 @@ func setup() *server.Http {
 		IdleTimeout:       timeout,
 	}
 
-	return server.New(srv, timeout, logger, nil)
+	hs, err := server.NewServer(srv, server.WithShutdownTimeout(timeout), server.WithLogger(logger))
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	return hs
 }
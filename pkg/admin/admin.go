@@ -0,0 +1,129 @@
+// Package admin provides a mountable group of runtime-control endpoints.
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/response"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/itsLeonB/ungerr"
+)
+
+// Toggle is a runtime on/off switch, such as maintenance mode.
+type Toggle interface {
+	Enable()
+	Disable()
+	Enabled() bool
+}
+
+// Controls are the runtime controls exposed by Mount. Nil controls are not mounted.
+type Controls struct {
+	// Maintenance is exposed at GET/PUT /maintenance.
+	Maintenance Toggle
+	// Drain stops accepting new work and waits for in-flight requests; exposed at POST /drain.
+	// It runs in the background, and its error is logged with the request-scoped logger.
+	Drain func(ctx context.Context) error
+	// SetLogLevel changes the log level at runtime; exposed at PUT /log-level.
+	SetLogLevel func(level string) error
+	// Flags is exposed at GET /flags, PUT /flags/:name and DELETE /flags/:name.
+	Flags *FeatureFlags
+	// Config is dumped at GET /config with secrets redacted (see Redact).
+	Config any
+}
+
+type toggleRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// Mount registers the admin endpoints for controls on group, behind guards.
+// Always pass guards (e.g. an auth middleware and NewIPAllowlistMiddleware) —
+// these endpoints change the behaviour of the running service.
+// It panics if no guards are given.
+func Mount(group *gin.RouterGroup, controls Controls, guards ...gin.HandlerFunc) {
+	if len(guards) == 0 {
+		panic("admin: Mount requires at least one guard, admin endpoints must not be public")
+	}
+	g := group.Group("", guards...)
+
+	if controls.Maintenance != nil {
+		g.GET("/maintenance", server.Handler("admin.getMaintenance", http.StatusOK, func(ctx *gin.Context) (any, error) {
+			return gin.H{"enabled": controls.Maintenance.Enabled()}, nil
+		}))
+		g.PUT("/maintenance", server.Handler("admin.setMaintenance", http.StatusOK, func(ctx *gin.Context) (any, error) {
+			req, err := server.BindJSON[toggleRequest](ctx)
+			if err != nil {
+				return nil, err
+			}
+			if *req.Enabled {
+				controls.Maintenance.Enable()
+			} else {
+				controls.Maintenance.Disable()
+			}
+			return gin.H{"enabled": controls.Maintenance.Enabled()}, nil
+		}))
+	}
+
+	if controls.Drain != nil {
+		g.POST("/drain", server.Handler("admin.drain", http.StatusAccepted, func(ctx *gin.Context) (any, error) {
+			// The Gin context is reused once the handler returns, so nothing from it
+			// may be read inside the goroutine.
+			drainCtx := context.WithoutCancel(ctx.Request.Context())
+			logger := middleware.GetLogger(ctx)
+			go func() {
+				if err := controls.Drain(drainCtx); err != nil {
+					logger.Errorf("error draining: %v", err)
+				}
+			}()
+			return gin.H{"draining": true}, nil
+		}))
+	}
+
+	if controls.SetLogLevel != nil {
+		g.PUT("/log-level", server.Handler("admin.setLogLevel", http.StatusOK, func(ctx *gin.Context) (any, error) {
+			req, err := server.BindJSON[logLevelRequest](ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err = controls.SetLogLevel(req.Level); err != nil {
+				return nil, ungerr.BadRequestError(err.Error())
+			}
+			return gin.H{"level": req.Level}, nil
+		}))
+	}
+
+	if controls.Flags != nil {
+		g.GET("/flags", server.Handler("admin.getFlags", http.StatusOK, func(ctx *gin.Context) (any, error) {
+			return controls.Flags.All(), nil
+		}))
+		g.PUT("/flags/:name", server.Handler("admin.overrideFlag", http.StatusOK, func(ctx *gin.Context) (any, error) {
+			req, err := server.BindJSON[toggleRequest](ctx)
+			if err != nil {
+				return nil, err
+			}
+			controls.Flags.Override(ctx.Param("name"), *req.Enabled)
+			return controls.Flags.All(), nil
+		}))
+		g.DELETE("/flags/:name", server.Handler("admin.resetFlag", http.StatusOK, func(ctx *gin.Context) (any, error) {
+			controls.Flags.Reset(ctx.Param("name"))
+			return controls.Flags.All(), nil
+		}))
+	}
+
+	if controls.Config != nil {
+		g.GET("/config", func(ctx *gin.Context) {
+			redacted, err := Redact(controls.Config)
+			if err != nil {
+				_ = ctx.Error(err)
+				return
+			}
+			ctx.JSON(http.StatusOK, response.NewResponse(redacted))
+		})
+	}
+}
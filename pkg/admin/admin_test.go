@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type toggle struct {
+	enabled atomic.Bool
+}
+
+func (t *toggle) Enable()       { t.enabled.Store(true) }
+func (t *toggle) Disable()      { t.enabled.Store(false) }
+func (t *toggle) Enabled() bool { return t.enabled.Load() }
+
+func TestMount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := middleware.NewMiddlewareProvider(logger)
+
+	maintenance := &toggle{}
+	flags := NewFeatureFlags(map[string]bool{"new-checkout": false})
+	var level string
+	drained := make(chan error, 1)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	Mount(r.Group("/admin"), Controls{
+		Maintenance: maintenance,
+		Drain: func(ctx context.Context) error {
+			drained <- ctx.Err()
+			return nil
+		},
+		SetLogLevel: func(l string) error { level = l; return nil },
+		Flags:       flags,
+		Config: map[string]any{
+			"dbPassword": "hunter2",
+			"dsn":        "postgres://app:hunter2@db:5432/app",
+			"port":       8080,
+		},
+	}, mp.NewIPAllowlistMiddleware("192.0.2.1"))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("maintenance toggle", func(t *testing.T) {
+		w := do("PUT", "/admin/maintenance", `{"enabled": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, maintenance.Enabled())
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		w := do("POST", "/admin/drain", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.NoError(t, <-drained, "drain context outlives the request")
+	})
+
+	t.Run("log level", func(t *testing.T) {
+		w := do("PUT", "/admin/log-level", `{"level": "debug"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "debug", level)
+	})
+
+	t.Run("flag override", func(t *testing.T) {
+		w := do("PUT", "/admin/flags/new-checkout", `{"enabled": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, flags.Enabled("new-checkout"))
+
+		w = do("DELETE", "/admin/flags/new-checkout", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, flags.Enabled("new-checkout"))
+	})
+
+	t.Run("config dump", func(t *testing.T) {
+		w := do("GET", "/admin/config", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "hunter2")
+		assert.Contains(t, w.Body.String(), "[REDACTED]")
+		assert.Contains(t, w.Body.String(), "8080")
+	})
+
+	t.Run("guarded", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/admin/config", nil)
+		req.RemoteAddr = "198.51.100.1:1234"
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestMountWithoutGuards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	assert.Panics(t, func() {
+		Mount(gin.New().Group("/admin"), Controls{})
+	})
+}
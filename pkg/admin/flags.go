@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"maps"
+	"sync"
+)
+
+// FeatureFlags holds boolean feature flags with runtime overrides on top of their defaults.
+type FeatureFlags struct {
+	defaults  map[string]bool
+	overrides map[string]bool
+	mu        sync.RWMutex
+}
+
+// NewFeatureFlags creates FeatureFlags with the given default values.
+func NewFeatureFlags(defaults map[string]bool) *FeatureFlags {
+	return &FeatureFlags{
+		defaults:  maps.Clone(defaults),
+		overrides: make(map[string]bool),
+	}
+}
+
+// Enabled reports the effective value of the flag; unknown flags are disabled.
+func (ff *FeatureFlags) Enabled(name string) bool {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
+	if val, ok := ff.overrides[name]; ok {
+		return val
+	}
+	return ff.defaults[name]
+}
+
+// Override sets a runtime value for the flag, taking precedence over its default.
+func (ff *FeatureFlags) Override(name string, enabled bool) {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	ff.overrides[name] = enabled
+}
+
+// Reset removes the runtime override of the flag.
+func (ff *FeatureFlags) Reset(name string) {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	delete(ff.overrides, name)
+}
+
+// All returns the effective value of every known flag.
+func (ff *FeatureFlags) All() map[string]bool {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
+	all := maps.Clone(ff.defaults)
+	if all == nil {
+		all = make(map[string]bool, len(ff.overrides))
+	}
+	maps.Copy(all, ff.overrides)
+	return all
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/itsLeonB/ungerr"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credential", "privatekey", "private_key"}
+
+// Redact converts cfg to its JSON representation and replaces the values of keys that look
+// sensitive (passwords, secrets, tokens, API keys, credentials) with "[REDACTED]".
+// Passwords embedded in URL-like strings, such as database DSNs, are redacted as well.
+func Redact(cfg any) (any, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ungerr.Wrap(err, "error encoding config")
+	}
+
+	var decoded any
+	if err = json.Unmarshal(raw, &decoded); err != nil {
+		return nil, ungerr.Wrap(err, "error decoding config")
+	}
+
+	return redactValue(decoded), nil
+}
+
+func redactValue(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		return redactURLPassword(v)
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactURLPassword(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, hasPassword := u.User.Password(); !hasPassword {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return u.String()
+}
@@ -0,0 +1,168 @@
+// Package i18n provides translation bundles shared by validation messages,
+// error responses and response message keys.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/itsLeonB/ungerr"
+)
+
+//go:embed locales/*.json
+var builtinLocales embed.FS
+
+// Bundle holds messages per locale and resolves them through a fallback chain:
+// the requested locale ("pt-BR"), its base language ("pt"), then the default locale.
+type Bundle struct {
+	defaultLocale string
+	messages      map[string]map[string]string
+	mu            sync.RWMutex
+}
+
+// NewBundle creates a Bundle with defaultLocale as the last fallback,
+// preloaded with the built-in validation messages.
+func NewBundle(defaultLocale string) *Bundle {
+	b := &Bundle{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+	if err := b.Load(builtinLocales, "locales"); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Load reads every "<locale>.json" file in dir of fsys (typically an embed.FS) and merges
+// its flat key-to-message object into the bundle, overriding existing keys.
+// Messages may contain fmt verbs filled by the args passed to Translate.
+func (b *Bundle) Load(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return ungerr.Wrapf(err, "error listing locale files in %s", dir)
+	}
+
+	for _, file := range files {
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return ungerr.Wrapf(err, "error reading locale file %s", file)
+		}
+
+		var messages map[string]string
+		if err = json.Unmarshal(raw, &messages); err != nil {
+			return ungerr.Wrapf(err, "error decoding locale file %s", file)
+		}
+
+		b.AddMessages(strings.TrimSuffix(path.Base(file), ".json"), messages)
+	}
+
+	return nil
+}
+
+// AddMessages merges messages into the given locale, overriding existing keys.
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	locale = normalizeLocale(locale)
+	if b.messages[locale] == nil {
+		b.messages[locale] = make(map[string]string, len(messages))
+	}
+	for key, msg := range messages {
+		b.messages[locale][key] = msg
+	}
+}
+
+// Translate resolves key for locale through the fallback chain and formats it with args.
+// The boolean is false if no locale in the chain defines key.
+func (b *Bundle) Translate(locale, key string, args ...any) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, candidate := range b.fallbackChain(locale) {
+		if msg, ok := b.messages[candidate][key]; ok {
+			if len(args) == 0 {
+				return msg, true
+			}
+			return fmt.Sprintf(msg, args...), true
+		}
+	}
+	return "", false
+}
+
+// DefaultLocale returns the locale used when no better match exists.
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// MatchLocale picks the best locale for an Accept-Language header value, honoring
+// q-weights. It returns the default locale if none of the requested languages is loaded.
+func (b *Bundle) MatchLocale(acceptLanguage string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		for _, candidate := range b.fallbackChain(tag) {
+			if candidate == b.defaultLocale {
+				break
+			}
+			if _, ok := b.messages[candidate]; ok {
+				return candidate
+			}
+		}
+	}
+	return b.defaultLocale
+}
+
+func (b *Bundle) fallbackChain(locale string) []string {
+	locale = normalizeLocale(locale)
+	chain := make([]string, 0, 3)
+	if locale != "" {
+		chain = append(chain, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			chain = append(chain, base)
+		}
+	}
+	return append(chain, b.defaultLocale)
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if qStr, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(qStr, 64); err == nil {
+				q = parsed
+			}
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
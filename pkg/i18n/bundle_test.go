@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	b := NewBundle("en")
+	err := b.Load(fstest.MapFS{
+		"messages/en.json": {Data: []byte(`{"greeting": "Hello, %s"}`)},
+		"messages/id.json": {Data: []byte(`{"greeting": "Halo, %s"}`)},
+	}, "messages")
+	assert.NoError(t, err)
+
+	t.Run("exact locale", func(t *testing.T) {
+		msg, ok := b.Translate("id", "greeting", "Budi")
+		assert.True(t, ok)
+		assert.Equal(t, "Halo, Budi", msg)
+	})
+
+	t.Run("base language fallback", func(t *testing.T) {
+		msg, ok := b.Translate("id-ID", "greeting", "Budi")
+		assert.True(t, ok)
+		assert.Equal(t, "Halo, Budi", msg)
+	})
+
+	t.Run("default locale fallback", func(t *testing.T) {
+		msg, ok := b.Translate("fr", "greeting", "Marie")
+		assert.True(t, ok)
+		assert.Equal(t, "Hello, Marie", msg)
+	})
+
+	t.Run("built-in validation messages", func(t *testing.T) {
+		msg, ok := b.Translate("id", "validation.required", "Name")
+		assert.True(t, ok)
+		assert.Equal(t, "Name is required", msg)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, ok := b.Translate("en", "missing")
+		assert.False(t, ok)
+	})
+}
+
+func TestMatchLocale(t *testing.T) {
+	b := NewBundle("en")
+	b.AddMessages("id", map[string]string{"greeting": "Halo"})
+	b.AddMessages("pt-BR", map[string]string{"greeting": "Olá"})
+
+	assert.Equal(t, "id", b.MatchLocale("id-ID,id;q=0.9,en;q=0.8"))
+	assert.Equal(t, "pt-br", b.MatchLocale("fr;q=0.5, pt-BR"))
+	assert.Equal(t, "en", b.MatchLocale("fr"))
+	assert.Equal(t, "en", b.MatchLocale(""))
+}
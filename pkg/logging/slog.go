@@ -0,0 +1,102 @@
+// Package logging adapts standard library loggers to the ezutil.Logger interface
+// used throughout ginkgo.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/itsLeonB/ezutil/v2"
+)
+
+// SlogLogger is an ezutil.Logger backed by a *slog.Logger. Fields added with
+// WithField, WithFields and WithError become slog attributes, so structured
+// handlers such as slog.JSONHandler emit them as separate keys.
+type SlogLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// NewSlogLogger creates a SlogLogger writing to logger, or to slog.Default() if logger is nil.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger, context.Background()}
+}
+
+func (sl *SlogLogger) Debug(args ...any) { sl.log(slog.LevelDebug, fmt.Sprint(args...)) }
+func (sl *SlogLogger) Info(args ...any)  { sl.log(slog.LevelInfo, fmt.Sprint(args...)) }
+func (sl *SlogLogger) Warn(args ...any)  { sl.log(slog.LevelWarn, fmt.Sprint(args...)) }
+func (sl *SlogLogger) Error(args ...any) { sl.log(slog.LevelError, fmt.Sprint(args...)) }
+
+// Fatal logs at Error level and exits the process.
+func (sl *SlogLogger) Fatal(args ...any) {
+	sl.log(slog.LevelError, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func (sl *SlogLogger) Debugf(format string, args ...any) {
+	sl.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (sl *SlogLogger) Infof(format string, args ...any) {
+	sl.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (sl *SlogLogger) Warnf(format string, args ...any) {
+	sl.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (sl *SlogLogger) Errorf(format string, args ...any) {
+	sl.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// Fatalf logs at Error level and exits the process.
+func (sl *SlogLogger) Fatalf(format string, args ...any) {
+	sl.log(slog.LevelError, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// Printf logs at Info level; it lets SlogLogger serve as a goose.Logger.
+func (sl *SlogLogger) Printf(format string, args ...any) {
+	sl.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (sl *SlogLogger) WithError(err error) ezutil.Logger {
+	return &SlogLogger{sl.logger.With(slog.Any("error", err)), sl.ctx}
+}
+
+func (sl *SlogLogger) WithField(key string, value any) ezutil.Logger {
+	return &SlogLogger{sl.logger.With(slog.Any(key, value)), sl.ctx}
+}
+
+// WithFields adds fields in key order, so output is stable across calls.
+func (sl *SlogLogger) WithFields(fields map[string]any) ezutil.Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	attrs := make([]any, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	return &SlogLogger{sl.logger.With(attrs...), sl.ctx}
+}
+
+// WithContext passes ctx to the slog handler, e.g. for handlers that add trace IDs.
+func (sl *SlogLogger) WithContext(ctx context.Context) ezutil.Logger {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &SlogLogger{sl.logger, ctx}
+}
+
+func (sl *SlogLogger) log(level slog.Level, msg string) {
+	sl.logger.Log(sl.ctx, level, msg)
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/itsLeonB/ezutil/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ ezutil.Logger = (*SlogLogger)(nil)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	decode := func() map[string]any {
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		buf.Reset()
+		return entry
+	}
+
+	t.Run("levels", func(t *testing.T) {
+		logger.Debugf("debug %d", 1)
+		assert.Equal(t, "DEBUG", decode()["level"])
+		logger.Info("info")
+		assert.Equal(t, "INFO", decode()["level"])
+		logger.Warn("warn")
+		assert.Equal(t, "WARN", decode()["level"])
+		logger.Errorf("error %s", "x")
+		entry := decode()
+		assert.Equal(t, "ERROR", entry["level"])
+		assert.Equal(t, "error x", entry["msg"])
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		logger.WithFields(map[string]any{"http.method": "GET", "http.status_code": 200}).
+			WithError(errors.New("boom")).
+			WithContext(context.Background()).
+			Info("http request")
+
+		entry := decode()
+		assert.Equal(t, "http request", entry["msg"])
+		assert.Equal(t, "GET", entry["http.method"])
+		assert.Equal(t, float64(200), entry["http.status_code"])
+		assert.Equal(t, "boom", entry["error"])
+	})
+
+	t.Run("fields do not leak into the parent", func(t *testing.T) {
+		logger.WithField("tenant", "acme").Info("child")
+		assert.Equal(t, "acme", decode()["tenant"])
+
+		logger.Info("parent")
+		assert.NotContains(t, decode(), "tenant")
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// abortPanic is the value PanicWithError panics with.
+type abortPanic struct {
+	err error
+}
+
+func (ap abortPanic) Error() string {
+	return ap.err.Error()
+}
+
+func (ap abortPanic) Unwrap() error {
+	return ap.err
+}
+
+// PanicWithError attaches err to the Gin context, aborts the request and unwinds the
+// handler chain by panicking. The error middleware recovers the panic and responds to err
+// like to any error added with ctx.Error, without treating it as a crash: no stack trace
+// is logged and panic hooks don't run. It suits failures that mean a bug rather than a bad
+// request, such as a value that a preceding middleware guarantees being missing, so
+// handlers don't need to plumb an error for them.
+//
+// Middlewares that run code after ctx.Next are unwound too, as with any panic. Without the
+// error middleware, the panic propagates like any other.
+func PanicWithError(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	ctx.Abort()
+	panic(abortPanic{err})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicWithError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	var hookCalled, afterPanic bool
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(WithPanicHook(func(*gin.Context, PanicInfo) {
+		hookCalled = true
+	})))
+	r.GET("/internal", func(ctx *gin.Context) {
+		PanicWithError(ctx, errors.New("user missing from context"))
+		afterPanic = true
+	})
+	r.GET("/not-found", func(ctx *gin.Context) {
+		PanicWithError(ctx, ungerr.NotFoundError("order not found"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/internal", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.False(t, afterPanic)
+	assert.False(t, hookCalled)
+	for _, entry := range logger.logged() {
+		assert.NotEqual(t, "panic recovered", entry.message)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/not-found", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "order not found")
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"crypto/subtle"
+	"encoding/base64"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -9,56 +11,281 @@ import (
 )
 
 // NewAuthMiddleware creates an authentication middleware for Gin.
-// It extracts a token using the given strategy (e.g., "Bearer") via extractToken,
+// It extracts a token using the given strategy ("Bearer", "Basic", "ApiKey" or "Cookie") via extractToken,
 // calls tokenCheckFunc to validate the token and retrieve user data,
-// stores user data in the Gin context, and aborts the request on errors.
+// stores user data in the Gin context along with a Principal built from it
+// (see CurrentPrincipal), and aborts the request on errors. The user data and Principal
+// are also stored in the request context, for code that only has a context.Context
+// (see AuthValue).
+// Behaviour can be extended with AuthOption values such as WithRevocationChecker.
 // Returns a Gin HandlerFunc for authentication handling.
 func (mp *MiddlewareProvider) NewAuthMiddleware(
 	authStrategy string,
 	tokenCheckFunc func(ctx *gin.Context, token string) (bool, map[string]any, error),
+	opts ...AuthOption,
 ) gin.HandlerFunc {
 	if tokenCheckFunc == nil {
 		mp.logger.Fatalf("tokenCheckFunc cannot be nil")
 	}
 
+	return mp.newAuthMiddleware(authStrategy, func(ctx *gin.Context, token string) (bool, authResult, error) {
+		exists, data, err := tokenCheckFunc(ctx, token)
+		return exists, authResult{data: data, principal: newPrincipal(token, data)}, err
+	}, opts)
+}
+
+// Keys of the values the auth middleware stores in the request context.
+type (
+	principalRequestKey  struct{}
+	claimsRequestKey     struct{}
+	authValuesRequestKey struct{}
+)
+
+// AuthValue returns the user data value stored under key by NewAuthMiddleware, e.g.
+// "userID". It accepts a *gin.Context or any context derived from the request context,
+// so database calls and loggers can read the identity without depending on Gin.
+// The boolean is false if the request was not authenticated or has no such value.
+func AuthValue(ctx context.Context, key string) (any, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if ginCtx.Request == nil {
+			return nil, false
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	data, _ := ctx.Value(authValuesRequestKey{}).(map[string]any)
+	val, ok := data[key]
+	return val, ok
+}
+
+// authResult is what a successful token check stores in the Gin context.
+type authResult struct {
+	data      map[string]any // set key by key, and passed to the revocation checker
+	claims    any            // set under claimsContextKey, see GetClaims
+	principal Principal
+}
+
+func (mp *MiddlewareProvider) newAuthMiddleware(
+	authStrategy string,
+	check func(ctx *gin.Context, token string) (bool, authResult, error),
+	opts []AuthOption,
+) gin.HandlerFunc {
+	cfg := authConfig{
+		apiKeyHeader: defaultAPIKeyHeader,
+		tokenCookie:  defaultTokenCookie,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	strategies := append([]string{authStrategy}, cfg.fallbackStrategies...)
+
 	return func(ctx *gin.Context) {
-		token, errMsg, err := extractToken(ctx, authStrategy)
+		if cfg.skipPaths.match(ctx.Request) {
+			ctx.Next()
+			return
+		}
+
+		strategy, token, errMsg, err := extractFirstToken(ctx, strategies, cfg)
 		if err != nil {
+			mp.countAuthFailure("unsupported_strategy")
 			_ = ctx.Error(ungerr.Wrap(err, "error extracting token"))
 			ctx.Abort()
 			return
 		}
 		if errMsg != "" {
+			mp.countAuthFailure(strings.ReplaceAll(errMsg, " ", "_"))
 			_ = ctx.Error(ungerr.UnauthorizedError(errMsg))
 			ctx.Abort()
 			return
 		}
 
-		exists, data, err := tokenCheckFunc(ctx, token)
+		authStrategyContextKey.Set(ctx, strategy)
+		exists, result, err := check(ctx, token)
 		if err != nil {
+			mp.countAuthFailure("check_error")
 			_ = ctx.Error(err)
 			ctx.Abort()
 			return
 		}
 		if !exists {
+			mp.countAuthFailure("user_not_found")
 			_ = ctx.Error(ungerr.UnauthorizedError("user data not found"))
 			ctx.Abort()
 			return
 		}
 
-		for key, val := range data {
+		if cfg.revocationChecker != nil {
+			revoked, err := cfg.revocationChecker.IsRevoked(ctx, token, result.data)
+			if err != nil {
+				mp.countAuthFailure("revocation_check_error")
+				_ = ctx.Error(ungerr.Wrap(err, "error checking token revocation"))
+				ctx.Abort()
+				return
+			}
+			if revoked {
+				mp.countAuthFailure("revoked")
+				_ = ctx.Error(ungerr.UnauthorizedError("token has been revoked"))
+				ctx.Abort()
+				return
+			}
+		}
+
+		reqCtx := ctx.Request.Context()
+		for key, val := range result.data {
 			ctx.Set(key, val)
 		}
+		if len(result.data) > 0 {
+			reqCtx = context.WithValue(reqCtx, authValuesRequestKey{}, result.data)
+		}
+		if result.claims != nil {
+			claimsContextKey.Set(ctx, result.claims)
+			reqCtx = context.WithValue(reqCtx, claimsRequestKey{}, result.claims)
+		}
+		principalContextKey.Set(ctx, result.principal)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(reqCtx, principalRequestKey{}, result.principal))
 
 		ctx.Next()
 	}
 }
 
-func extractToken(ctx *gin.Context, authStrategy string) (string, string, error) {
+func (mp *MiddlewareProvider) countAuthFailure(reason string) {
+	mp.metrics.IncCounter(metricAuthFailures, map[string]string{"reason": reason})
+}
+
+// AuthOption configures optional behaviour of NewAuthMiddleware.
+type AuthOption func(*authConfig)
+
+type authConfig struct {
+	revocationChecker  RevocationChecker
+	apiKeyHeader       string
+	apiKeyQueryParam   string
+	tokenCookie        string
+	fallbackStrategies []string
+	skipPaths          pathPatterns
+}
+
+const (
+	defaultAPIKeyHeader = "X-API-Key"
+	defaultTokenCookie  = "access_token"
+)
+
+// WithRevocationChecker makes the auth middleware reject tokens reported as revoked by rc.
+// The check runs after tokenCheckFunc has validated the token.
+func WithRevocationChecker(rc RevocationChecker) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.revocationChecker = rc
+	}
+}
+
+// WithAPIKeyHeader sets the header read by the "ApiKey" strategy. Defaults to X-API-Key.
+func WithAPIKeyHeader(name string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.apiKeyHeader = name
+	}
+}
+
+// WithAPIKeyQueryParam makes the "ApiKey" strategy fall back to the given query parameter
+// when the header is absent. Query parameters tend to end up in access logs,
+// so this is disabled by default.
+func WithAPIKeyQueryParam(name string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.apiKeyQueryParam = name
+	}
+}
+
+// WithTokenCookie sets the cookie read by the "Cookie" strategy. Defaults to access_token.
+func WithTokenCookie(name string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.tokenCookie = name
+	}
+}
+
+// WithFallbackStrategies lets clients that authenticate differently share an endpoint.
+// The strategies are tried in order after the primary one until a token is found,
+// e.g. NewAuthMiddleware("Bearer", check, WithFallbackStrategies("Cookie", "ApiKey")).
+// The check can tell which strategy a token came from with AuthStrategy; StrategyChecks
+// builds one check out of a check per strategy.
+func WithFallbackStrategies(strategies ...string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.fallbackStrategies = append(cfg.fallbackStrategies, strategies...)
+	}
+}
+
+// WithSkipPaths lets requests matching any of the patterns through without authentication,
+// e.g. WithSkipPaths("/healthz", "GET /metrics", "/public/*").
+// Patterns use path.Match syntax against the request path and may be prefixed
+// with an HTTP method to only skip that method.
+func WithSkipPaths(patterns ...string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.skipPaths = append(cfg.skipPaths, compilePathPatterns(patterns)...)
+	}
+}
+
+// AuthStrategy returns the strategy, e.g. "Cookie", that the token being checked or the
+// authenticated request's token was extracted with. It is set before tokenCheckFunc runs,
+// so a check shared by several strategies can tell their tokens apart.
+func AuthStrategy(ctx *gin.Context) string {
+	strategy, _ := authStrategyContextKey.Get(ctx)
+	return strategy
+}
+
+// StrategyChecks combines a token check per strategy into one for an auth middleware with
+// WithFallbackStrategies. Each token is checked by the check of the strategy it was
+// extracted with, e.g.
+//
+//	mp.NewAuthMiddleware("Bearer", middleware.StrategyChecks(map[string]func(*gin.Context, string) (bool, map[string]any, error){
+//		"Bearer": checkToken,
+//		"Basic":  middleware.BasicAuthCheck(checkPassword),
+//	}), middleware.WithFallbackStrategies("Basic"))
+//
+// so Basic credentials are decoded before their check, as with a single "Basic" strategy.
+// A token of a strategy without a check is an error.
+func StrategyChecks[T any](
+	checks map[string]func(ctx *gin.Context, token string) (bool, T, error),
+) func(ctx *gin.Context, token string) (bool, T, error) {
+	return func(ctx *gin.Context, token string) (bool, T, error) {
+		check, ok := checks[AuthStrategy(ctx)]
+		if !ok || check == nil {
+			var zero T
+			return false, zero, ungerr.Unknownf("no token check for auth strategy: %s", AuthStrategy(ctx))
+		}
+		return check(ctx, token)
+	}
+}
+
+// extractFirstToken returns the first strategy that yields a token, and its token.
+// If none does, the first "invalid token" message wins over "missing token",
+// so a malformed credential is not reported as an absent one.
+func extractFirstToken(ctx *gin.Context, strategies []string, cfg authConfig) (string, string, string, error) {
+	var firstErrMsg string
+	for _, strategy := range strategies {
+		token, errMsg, err := extractToken(ctx, strategy, cfg)
+		if err != nil {
+			return "", "", "", err
+		}
+		if errMsg == "" {
+			return strategy, token, "", nil
+		}
+		if firstErrMsg == "" || (firstErrMsg == "missing token" && errMsg != "missing token") {
+			firstErrMsg = errMsg
+		}
+	}
+	return "", "", firstErrMsg, nil
+}
+
+func extractToken(ctx *gin.Context, authStrategy string, cfg authConfig) (string, string, error) {
 	switch authStrategy {
 	case "Bearer":
 		token, errMsg := extractBearerToken(ctx)
 		return token, errMsg, nil
+	case "Basic":
+		token, errMsg := extractBasicCredentials(ctx)
+		return token, errMsg, nil
+	case "ApiKey":
+		token, errMsg := extractAPIKey(ctx, cfg)
+		return token, errMsg, nil
+	case "Cookie":
+		token, errMsg := extractCookieToken(ctx, cfg)
+		return token, errMsg, nil
 	default:
 		return "", "", ungerr.Unknownf("unsupported auth strategy: %s", authStrategy)
 	}
@@ -78,6 +305,67 @@ func extractBearerToken(ctx *gin.Context) (string, string) {
 	return token, ""
 }
 
+// BasicAuthCheck adapts a username/password check into a tokenCheckFunc for the "Basic" strategy,
+// which passes the still-encoded credentials of the Authorization header as the token.
+func BasicAuthCheck(
+	check func(ctx *gin.Context, username, password string) (bool, map[string]any, error),
+) func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+	return func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+		username, password, ok := decodeBasicCredentials(token)
+		if !ok {
+			return false, nil, nil
+		}
+		return check(ctx, username, password)
+	}
+}
+
+func extractBasicCredentials(ctx *gin.Context) (string, string) {
+	header := ctx.GetHeader("Authorization")
+	if header == "" {
+		return "", "missing token"
+	}
+
+	scheme, credentials, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return "", "invalid token"
+	}
+	if _, _, ok := decodeBasicCredentials(credentials); !ok {
+		return "", "invalid token"
+	}
+
+	return credentials, ""
+}
+
+func decodeBasicCredentials(credentials string) (string, string, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func extractAPIKey(ctx *gin.Context, cfg authConfig) (string, string) {
+	if cfg.apiKeyHeader != "" {
+		if key := strings.TrimSpace(ctx.GetHeader(cfg.apiKeyHeader)); key != "" {
+			return key, ""
+		}
+	}
+	if cfg.apiKeyQueryParam != "" {
+		if key := strings.TrimSpace(ctx.Query(cfg.apiKeyQueryParam)); key != "" {
+			return key, ""
+		}
+	}
+	return "", "missing token"
+}
+
+func extractCookieToken(ctx *gin.Context, cfg authConfig) (string, string) {
+	token, err := ctx.Cookie(cfg.tokenCookie)
+	if err != nil || token == "" {
+		return "", "missing token"
+	}
+	return token, ""
+}
+
 func validateAndExtractBearerToken(bearerToken string) (bool, string) {
 	splits := strings.Split(bearerToken, " ")
 
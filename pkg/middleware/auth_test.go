@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,6 +36,21 @@ func TestNewAuthMiddleware(t *testing.T) {
 		userID, exists := c.Get("userID")
 		assert.True(t, exists)
 		assert.Equal(t, "123", userID)
+
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.Equal(t, "123", principal.Subject())
+		assert.Equal(t, "valid-token", principal.(BasicPrincipal).Token)
+
+		reqCtx := c.Request.Context()
+		principal, ok = CurrentPrincipal(reqCtx)
+		assert.True(t, ok)
+		assert.Equal(t, "123", principal.Subject())
+		userID, ok = AuthValue(reqCtx, "userID")
+		assert.True(t, ok)
+		assert.Equal(t, "123", userID)
+		_, ok = AuthValue(c, "email")
+		assert.False(t, ok)
 	})
 
 	t.Run("missing token", func(t *testing.T) {
@@ -95,7 +111,7 @@ func TestNewAuthMiddleware(t *testing.T) {
 			return true, nil, nil
 		}
 
-		mw := mp.NewAuthMiddleware("Basic", tokenCheckFunc)
+		mw := mp.NewAuthMiddleware("Digest", tokenCheckFunc)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -107,4 +123,229 @@ func TestNewAuthMiddleware(t *testing.T) {
 		assert.True(t, c.IsAborted())
 		assert.NotEmpty(t, c.Errors)
 	})
+
+	t.Run("api key header", func(t *testing.T) {
+		var received string
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			received = token
+			return true, map[string]any{"sub": "billing-service"}, nil
+		}
+
+		mw := mp.NewAuthMiddleware("ApiKey", tokenCheckFunc)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("X-API-Key", "secret-key")
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "secret-key", received)
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.Equal(t, "billing-service", principal.Subject())
+	})
+
+	t.Run("api key custom header and query param", func(t *testing.T) {
+		var received string
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			received = token
+			return true, nil, nil
+		}
+
+		mw := mp.NewAuthMiddleware("ApiKey", tokenCheckFunc,
+			WithAPIKeyHeader("X-Service-Key"), WithAPIKeyQueryParam("api_key"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/?api_key=query-key", nil)
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "query-key", received)
+	})
+
+	t.Run("api key missing", func(t *testing.T) {
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			return true, nil, nil
+		}
+
+		mw := mp.NewAuthMiddleware("ApiKey", tokenCheckFunc)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/?api_key=ignored", nil)
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+
+	t.Run("basic credentials", func(t *testing.T) {
+		tokenCheckFunc := BasicAuthCheck(func(ctx *gin.Context, username, password string) (bool, map[string]any, error) {
+			if username != "admin" || password != "p@ss:word" {
+				return false, nil, nil
+			}
+			return true, map[string]any{"sub": username}, nil
+		})
+
+		mw := mp.NewAuthMiddleware("Basic", tokenCheckFunc)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.SetBasicAuth("admin", "p@ss:word")
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.Equal(t, "admin", principal.Subject())
+	})
+
+	t.Run("basic wrong password", func(t *testing.T) {
+		tokenCheckFunc := BasicAuthCheck(func(ctx *gin.Context, username, password string) (bool, map[string]any, error) {
+			return password == "secret", nil, nil
+		})
+
+		mw := mp.NewAuthMiddleware("Basic", tokenCheckFunc)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.SetBasicAuth("admin", "wrong")
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+
+	t.Run("basic malformed credentials", func(t *testing.T) {
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			return true, nil, nil
+		}
+
+		mw := mp.NewAuthMiddleware("Basic", tokenCheckFunc)
+
+		for _, header := range []string{"Bearer token", "Basic not-base64!", "Basic bm9jb2xvbg=="} {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			c.Request.Header.Set("Authorization", header)
+
+			mw(c)
+
+			assert.True(t, c.IsAborted(), header)
+		}
+	})
+
+	t.Run("fallback strategies", func(t *testing.T) {
+		var received string
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			received = token
+			return true, nil, nil
+		}
+
+		mw := mp.NewAuthMiddleware("Bearer", tokenCheckFunc, WithFallbackStrategies("Cookie", "ApiKey"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("X-API-Key", "api-key")
+		c.Request.AddCookie(&http.Cookie{Name: "access_token", Value: "cookie-token"})
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "cookie-token", received)
+		assert.Equal(t, "Cookie", AuthStrategy(c))
+	})
+
+	t.Run("fallback strategies with a check per strategy", func(t *testing.T) {
+		checks := StrategyChecks(map[string]func(*gin.Context, string) (bool, map[string]any, error){
+			"Bearer": func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+				return token == "valid-token", map[string]any{"via": "token"}, nil
+			},
+			"Basic": BasicAuthCheck(func(ctx *gin.Context, username, password string) (bool, map[string]any, error) {
+				return username == "alice" && password == "secret", map[string]any{"via": username}, nil
+			}),
+		})
+		mw := mp.NewAuthMiddleware("Bearer", checks, WithFallbackStrategies("Basic", "ApiKey"))
+
+		serve := func(header, value string) *gin.Context {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			c.Request.Header.Set(header, value)
+			mw(c)
+			return c
+		}
+
+		c := serve("Authorization", "Bearer valid-token")
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "token", c.GetString("via"))
+
+		c = serve("Authorization", "Basic YWxpY2U6c2VjcmV0") // alice:secret
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "alice", c.GetString("via"))
+
+		c = serve("Authorization", "Basic YWxpY2U6d3Jvbmc=") // alice:wrong
+		assert.True(t, c.IsAborted())
+
+		c = serve("X-API-Key", "api-key")
+		assert.True(t, c.IsAborted(), "strategy without a check")
+		assert.Error(t, c.Errors.Last().Err)
+	})
+
+	t.Run("fallback strategies none match", func(t *testing.T) {
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			return true, nil, nil
+		}
+
+		mw := mp.NewAuthMiddleware("Cookie", tokenCheckFunc, WithFallbackStrategies("Bearer"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer")
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, "invalid token", c.Errors.Last().Err.(ungerr.AppError).Details())
+	})
+
+	t.Run("skip paths", func(t *testing.T) {
+		tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+			return true, nil, nil
+		}
+
+		mw := mp.NewAuthMiddleware("Bearer", tokenCheckFunc, WithSkipPaths("/healthz", "GET /metrics", "/public/*"))
+
+		cases := []struct {
+			method  string
+			path    string
+			skipped bool
+		}{
+			{"GET", "/healthz", true},
+			{"GET", "/metrics", true},
+			{"POST", "/metrics", false},
+			{"GET", "/public/logo.png", true},
+			{"GET", "/public/images/logo.png", false},
+			{"GET", "/api/users", false},
+		}
+		for _, tc := range cases {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(tc.method, tc.path, nil)
+
+			mw(c)
+
+			assert.Equal(t, !tc.skipped, c.IsAborted(), tc.method+" "+tc.path)
+		}
+	})
 }
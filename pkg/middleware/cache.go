@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/itsLeonB/ungerr"
+)
+
+// ResponseCacheConfig configures a ResponseCache.
+type ResponseCacheConfig struct {
+	// Store keeps cached responses. Defaults to an in-memory LRU store of 1000 entries;
+	// use a store.RedisStore to share the cache across instances.
+	Store store.Store
+	// TTL is how long responses are cached. Defaults to 1 minute.
+	TTL time.Duration
+	// KeyFunc picks the cache key of a request. Defaults to the path and raw query, in
+	// which case requests carrying an Authorization or Cookie header bypass the cache,
+	// so one caller's response is never served to another. A custom KeyFunc opts those
+	// requests in, and must include the caller in the key of responses that depend on it.
+	KeyFunc func(ctx *gin.Context) string
+	// Prefix is prepended to every store key. Defaults to "respcache:".
+	Prefix string
+}
+
+// ResponseCache caches GET responses for NewResponseCacheMiddleware.
+// Entries are grouped by request path so they can be dropped with Invalidate.
+type ResponseCache struct {
+	cfg ResponseCacheConfig
+	now func() time.Time
+	// cachesCredentialed is set when KeyFunc is given, and is trusted to key by caller.
+	cachesCredentialed bool
+}
+
+// NewResponseCache creates a ResponseCache from cfg.
+func NewResponseCache(cfg ResponseCacheConfig) *ResponseCache {
+	if cfg.Store == nil {
+		cfg.Store = store.NewLRUStore(1000)
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+	cachesCredentialed := cfg.KeyFunc != nil
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(ctx *gin.Context) string {
+			return ctx.Request.URL.Path + "?" + ctx.Request.URL.RawQuery
+		}
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "respcache:"
+	}
+	return &ResponseCache{cfg: cfg, now: time.Now, cachesCredentialed: cachesCredentialed}
+}
+
+// NewResponseCacheMiddleware creates a middleware that serves GET requests from rc and
+// caches successful responses, marking them with an X-Cache header of HIT or MISS.
+// Responses are varied on the request headers named by their Vary header, and are not
+// cached if they set cookies or forbid it with Cache-Control (no-store, no-cache or private).
+// Requests sending Cache-Control: no-cache skip the lookup and refresh the entry, and
+// requests with credentials bypass the cache unless ResponseCacheConfig.KeyFunc is set.
+// Store errors are logged and the request is handled as a miss.
+func (mp *MiddlewareProvider) NewResponseCacheMiddleware(rc *ResponseCache) gin.HandlerFunc {
+	if rc == nil {
+		mp.logger.Fatal("response cache cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet || cacheControlHas(ctx.GetHeader("Cache-Control"), "no-store") ||
+			(!rc.cachesCredentialed && hasCredentials(ctx.Request)) {
+			ctx.Next()
+			return
+		}
+
+		base, err := rc.baseKey(ctx)
+		if err != nil {
+			mp.logger.WithContext(ctx).WithError(err).Error("response cache unavailable")
+			ctx.Next()
+			return
+		}
+
+		if !cacheControlHas(ctx.GetHeader("Cache-Control"), "no-cache") {
+			entry, err := rc.lookup(ctx, base)
+			if err != nil {
+				mp.logger.WithContext(ctx).WithError(err).Error("error reading cached response")
+			} else if entry != nil {
+				ctx.Header("X-Cache", "HIT")
+				ctx.Header("Age", strconv.Itoa(int(rc.now().Sub(entry.StoredAt)/time.Second)))
+				entry.Response.replay(ctx)
+				return
+			}
+		}
+
+		ctx.Header("X-Cache", "MISS")
+		resp := recordResponse(ctx)
+		if resp == nil || !cacheableResponse(resp) {
+			return
+		}
+		if err := rc.save(ctx, base, resp); err != nil {
+			mp.logger.WithContext(ctx).WithError(err).Error("error caching response")
+		}
+	}
+}
+
+// NewCacheInvalidationMiddleware creates a middleware that drops the cached responses of
+// paths after a successful (2xx) request, e.g. on the POST and PUT routes of a resource.
+// Without paths, the request's own path is invalidated.
+func (mp *MiddlewareProvider) NewCacheInvalidationMiddleware(rc *ResponseCache, paths ...string) gin.HandlerFunc {
+	if rc == nil {
+		mp.logger.Fatal("response cache cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) > 0 || ctx.Writer.Status() < 200 || ctx.Writer.Status() >= 300 {
+			return
+		}
+		targets := paths
+		if len(targets) == 0 {
+			targets = []string{ctx.Request.URL.Path}
+		}
+		if err := rc.Invalidate(ctx, targets...); err != nil {
+			mp.logger.WithContext(ctx).WithError(err).Error("error invalidating cached responses")
+		}
+	}
+}
+
+// Invalidate drops every cached response of paths, whatever their query or variant.
+func (rc *ResponseCache) Invalidate(ctx context.Context, paths ...string) error {
+	generation := []byte(strconv.FormatInt(rc.now().UnixNano(), 36))
+	for _, path := range paths {
+		// Entries live for at most TTL, so the generation only needs to outlive them.
+		if err := rc.cfg.Store.Set(ctx, rc.generationKey(path), generation, 2*rc.cfg.TTL); err != nil {
+			return ungerr.Wrap(err, "error invalidating cached responses")
+		}
+	}
+	return nil
+}
+
+// cacheEntry is stored under the base key of a request. Responses with a Vary header
+// store only the header names there, and the response itself under a variant key.
+type cacheEntry struct {
+	Vary     []string          `json:"vary,omitempty"`
+	Response *recordedResponse `json:"response,omitempty"`
+	StoredAt time.Time         `json:"stored_at"`
+}
+
+func (rc *ResponseCache) generationKey(path string) string {
+	return rc.cfg.Prefix + "gen:" + path
+}
+
+// baseKey combines the path generation with the request key, so bumping the generation
+// orphans every entry of the path until it expires.
+func (rc *ResponseCache) baseKey(ctx *gin.Context) (string, error) {
+	generation, _, err := rc.cfg.Store.Get(ctx, rc.generationKey(ctx.Request.URL.Path))
+	if err != nil {
+		return "", ungerr.Wrap(err, "error reading cache generation")
+	}
+	return rc.cfg.Prefix + string(generation) + ":" + rc.cfg.KeyFunc(ctx), nil
+}
+
+func (rc *ResponseCache) lookup(ctx *gin.Context, base string) (*cacheEntry, error) {
+	entry, err := rc.get(ctx, base)
+	if err != nil || entry == nil || len(entry.Vary) == 0 {
+		return entry, err
+	}
+	return rc.get(ctx, variantKey(base, entry.Vary, ctx.Request.Header))
+}
+
+func (rc *ResponseCache) get(ctx context.Context, key string) (*cacheEntry, error) {
+	data, ok, err := rc.cfg.Store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, ungerr.Wrap(err, "error decoding cached response")
+	}
+	if len(entry.Vary) == 0 && entry.Response == nil {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (rc *ResponseCache) save(ctx *gin.Context, base string, resp *recordedResponse) error {
+	vary := varyHeaders(resp.Header)
+	entry := cacheEntry{Response: resp, StoredAt: rc.now()}
+	if len(vary) == 0 {
+		return rc.set(ctx, base, entry)
+	}
+
+	if err := rc.set(ctx, base, cacheEntry{Vary: vary, StoredAt: entry.StoredAt}); err != nil {
+		return err
+	}
+	return rc.set(ctx, variantKey(base, vary, ctx.Request.Header), entry)
+}
+
+func (rc *ResponseCache) set(ctx context.Context, key string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return ungerr.Wrap(err, "error encoding cached response")
+	}
+	return rc.cfg.Store.Set(ctx, key, data, rc.cfg.TTL)
+}
+
+// hasCredentials reports whether req identifies its caller, which a shared cache must not
+// serve others a response to (RFC 9111, section 3.5).
+func hasCredentials(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+}
+
+func cacheableResponse(resp *recordedResponse) bool {
+	return resp.Status == http.StatusOK && shareableResponse(resp)
+}
+
+// shareableResponse reports whether resp may be sent to clients other than the one it
+// was written for: it sets no cookies and is not marked private or no-store.
+func shareableResponse(resp *recordedResponse) bool {
+	if len(resp.Header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	cacheControl := strings.Join(resp.Header.Values("Cache-Control"), ",")
+	if cacheControlHas(cacheControl, "no-store") ||
+		cacheControlHas(cacheControl, "no-cache") ||
+		cacheControlHas(cacheControl, "private") {
+		return false
+	}
+	return !slices.Contains(varyHeaders(resp.Header), "*")
+}
+
+// varyHeaders returns the canonical header names listed in the Vary headers, sorted.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, textproto.CanonicalMIMEHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func variantKey(base string, vary []string, header http.Header) string {
+	hash := sha256.New()
+	for _, name := range vary {
+		hash.Write([]byte(name + "=" + strings.Join(header.Values(name), ",") + "\n"))
+	}
+	return base + "|" + hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+func cacheControlHas(cacheControl, directive string) bool {
+	for part := range strings.SplitSeq(cacheControl, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResponseCacheMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	calls := 0
+	rc := NewResponseCache(ResponseCacheConfig{TTL: time.Minute})
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Use(mp.NewResponseCacheMiddleware(rc))
+	r.GET("/items", func(ctx *gin.Context) {
+		calls++
+		ctx.Header("X-Call", strconv.Itoa(calls))
+		ctx.String(http.StatusOK, "items "+ctx.Query("page"))
+	})
+	r.GET("/greeting", func(ctx *gin.Context) {
+		calls++
+		ctx.Header("Vary", "Accept-Language")
+		ctx.String(http.StatusOK, "hello "+ctx.GetHeader("Accept-Language"))
+	})
+	r.GET("/private", func(ctx *gin.Context) {
+		calls++
+		ctx.Header("Cache-Control", "private, max-age=60")
+		ctx.String(http.StatusOK, "mine")
+	})
+	r.GET("/no-store", func(ctx *gin.Context) {
+		calls++
+		ctx.Header("Cache-Control", "no-store")
+		ctx.String(http.StatusOK, "fresh")
+	})
+	r.GET("/missing", func(ctx *gin.Context) {
+		calls++
+		_ = ctx.Error(ungerr.NotFoundError("no item"))
+	})
+	r.POST("/items", mp.NewCacheInvalidationMiddleware(rc), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	serve := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("caches by path and query", func(t *testing.T) {
+		calls = 0
+		w := serve("GET", "/items?page=1", nil)
+		assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+		assert.Equal(t, "items 1", w.Body.String())
+
+		w = serve("GET", "/items?page=1", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+		assert.Equal(t, "items 1", w.Body.String())
+		assert.Equal(t, "1", w.Header().Get("X-Call"))
+		assert.Equal(t, "0", w.Header().Get("Age"))
+
+		assert.Equal(t, "items 2", serve("GET", "/items?page=2", nil).Body.String())
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("no-cache request refreshes", func(t *testing.T) {
+		calls = 0
+		w := serve("GET", "/items?page=1", http.Header{"Cache-Control": {"no-cache"}})
+		assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "1", serve("GET", "/items?page=1", nil).Header().Get("X-Call"))
+	})
+
+	t.Run("invalidation", func(t *testing.T) {
+		calls = 0
+		assert.Equal(t, http.StatusCreated, serve("POST", "/items", nil).Code)
+
+		assert.Equal(t, "MISS", serve("GET", "/items?page=1", nil).Header().Get("X-Cache"))
+		assert.Equal(t, "MISS", serve("GET", "/items?page=2", nil).Header().Get("X-Cache"))
+		assert.Equal(t, "HIT", serve("GET", "/items?page=2", nil).Header().Get("X-Cache"))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("varies on request headers", func(t *testing.T) {
+		calls = 0
+		en := http.Header{"Accept-Language": {"en"}}
+		id := http.Header{"Accept-Language": {"id"}}
+
+		assert.Equal(t, "hello en", serve("GET", "/greeting", en).Body.String())
+		assert.Equal(t, "hello id", serve("GET", "/greeting", id).Body.String())
+
+		w := serve("GET", "/greeting", en)
+		assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+		assert.Equal(t, "hello en", w.Body.String())
+		w = serve("GET", "/greeting", id)
+		assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+		assert.Equal(t, "hello id", w.Body.String())
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("uncacheable responses", func(t *testing.T) {
+		calls = 0
+		serve("GET", "/private", nil)
+		assert.Equal(t, "MISS", serve("GET", "/private", nil).Header().Get("X-Cache"))
+		serve("GET", "/no-store", nil)
+		assert.Equal(t, "MISS", serve("GET", "/no-store", nil).Header().Get("X-Cache"))
+
+		serve("GET", "/missing", nil)
+		w := serve("GET", "/missing", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+		assert.Equal(t, 6, calls)
+	})
+
+	t.Run("credentialed requests bypass the cache", func(t *testing.T) {
+		calls = 0
+		serve("GET", "/items?page=9", nil)
+
+		for _, header := range []http.Header{
+			{"Authorization": {"Bearer alice"}},
+			{"Cookie": {"session=bob"}},
+		} {
+			w := serve("GET", "/items?page=9", header)
+			assert.Empty(t, w.Header().Get("X-Cache"), "not served from the cache")
+			assert.Equal(t, "items 9", w.Body.String())
+		}
+		assert.Equal(t, 3, calls)
+
+		w := serve("GET", "/items?page=9", nil)
+		assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+		assert.Equal(t, "1", w.Header().Get("X-Call"), "credentialed responses were not stored")
+	})
+}
+
+func TestResponseCacheCustomKeyFunc(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	calls := 0
+	rc := NewResponseCache(ResponseCacheConfig{
+		KeyFunc: func(ctx *gin.Context) string { return ctx.GetHeader("Authorization") + ctx.Request.URL.Path },
+	})
+	r := gin.New()
+	r.Use(mp.NewResponseCacheMiddleware(rc))
+	r.GET("/me", func(ctx *gin.Context) {
+		calls++
+		ctx.String(http.StatusOK, ctx.GetHeader("Authorization"))
+	})
+
+	serve := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	serve("Bearer alice")
+	w := serve("Bearer alice")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "Bearer alice", w.Body.String())
+	assert.Equal(t, "Bearer bob", serve("Bearer bob").Body.String())
+	assert.Equal(t, 2, calls)
+}
+
+func TestVaryHeaders(t *testing.T) {
+	header := http.Header{"Vary": {"accept-language, Accept", "Accept-Language"}}
+	assert.Equal(t, []string{"Accept", "Accept-Language"}, varyHeaders(header))
+	assert.Empty(t, varyHeaders(http.Header{}))
+}
+
+func TestCacheControlHas(t *testing.T) {
+	assert.True(t, cacheControlHas("max-age=0, No-Cache", "no-cache"))
+	assert.True(t, cacheControlHas(`private="Set-Cookie"`, "private"))
+	assert.False(t, cacheControlHas("max-age=60", "no-store"))
+}
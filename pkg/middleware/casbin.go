@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// CasbinEnforcer is the subset of the Casbin enforcer API used by NewCasbinMiddleware.
+// *casbin.Enforcer and *casbin.SyncedEnforcer satisfy it, so this package doesn't
+// depend on Casbin itself.
+type CasbinEnforcer interface {
+	Enforce(rvals ...any) (bool, error)
+}
+
+// NewCasbinMiddleware creates an authorization middleware that asks enforcer whether
+// (subject, request path, request method) is allowed, matching a model whose request
+// definition is "r = sub, obj, act". The subject is read with subjectFunc, or from the
+// current Principal if subjectFunc is nil.
+// Denied requests are aborted with a ForbiddenError.
+func (mp *MiddlewareProvider) NewCasbinMiddleware(
+	enforcer CasbinEnforcer,
+	subjectFunc func(ctx *gin.Context) string,
+) gin.HandlerFunc {
+	if enforcer == nil {
+		mp.logger.Fatal("enforcer cannot be nil")
+	}
+	if subjectFunc == nil {
+		subjectFunc = principalSubject
+	}
+
+	return func(ctx *gin.Context) {
+		subject := subjectFunc(ctx)
+		if subject == "" {
+			_ = ctx.Error(ungerr.Unknownf("subject not found in context"))
+			ctx.Abort()
+			return
+		}
+
+		allowed, err := enforcer.Enforce(subject, ctx.Request.URL.Path, ctx.Request.Method)
+		if err != nil {
+			_ = ctx.Error(ungerr.Wrap(err, "error enforcing casbin policy"))
+			ctx.Abort()
+			return
+		}
+		if !allowed {
+			_ = ctx.Error(ungerr.ForbiddenError("no permission"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
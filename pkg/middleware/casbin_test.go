@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEnforcer mimics a Casbin model with keyMatch-style object globs.
+type fakeEnforcer struct {
+	policies [][3]string
+	err      error
+}
+
+func (fe fakeEnforcer) Enforce(rvals ...any) (bool, error) {
+	if fe.err != nil {
+		return false, fe.err
+	}
+	for _, p := range fe.policies {
+		if ok, _ := path.Match(p[1], rvals[1].(string)); ok && p[0] == rvals[0] && p[2] == rvals[2] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestNewCasbinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	enforcer := fakeEnforcer{policies: [][3]string{{"alice", "/orders/*", "GET"}}}
+
+	run := func(mw gin.HandlerFunc, method, target string, principal Principal) *gin.Context {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, target, nil)
+		if principal != nil {
+			SetPrincipal(c, principal)
+		}
+		mw(c)
+		return c
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		c := run(mp.NewCasbinMiddleware(enforcer, nil), "GET", "/orders/1", BasicPrincipal{ID: "alice"})
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		c := run(mp.NewCasbinMiddleware(enforcer, nil), "DELETE", "/orders/1", BasicPrincipal{ID: "alice"})
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusForbidden, c.Errors.Last().Err.(ungerr.AppError).HttpStatus())
+	})
+
+	t.Run("custom subject", func(t *testing.T) {
+		mw := mp.NewCasbinMiddleware(enforcer, func(ctx *gin.Context) string { return ctx.GetHeader("X-User") })
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/orders/1", nil)
+		c.Request.Header.Set("X-User", "alice")
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		c := run(mp.NewCasbinMiddleware(enforcer, nil), "GET", "/orders/1", nil)
+		assert.True(t, c.IsAborted())
+	})
+
+	t.Run("enforcer error", func(t *testing.T) {
+		c := run(mp.NewCasbinMiddleware(fakeEnforcer{err: errors.New("adapter down")}, nil), "GET", "/orders/1", BasicPrincipal{ID: "alice"})
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// NewCoalesceMiddleware creates a middleware that collapses identical concurrent GET and HEAD
+// requests into a single handler execution and sends its response to every waiting client,
+// so a burst of requests for the same resource hits caches and databases only once.
+// Requests are identical when their method, path, query, Accept and Accept-Language match.
+// Requests with an Authorization header or cookies are not coalesced unless WithCoalesceKey
+// is set, since their responses usually depend on the caller; the key function must then
+// tell callers apart, e.g. by the authenticated user.
+// Only attach it to idempotent routes whose response is the same for everyone sharing a key.
+// Responses that end with errors on the Gin context, set cookies or are marked private or
+// no-store are not shared: waiting requests then run the handler themselves. Headers set
+// before this middleware runs are not copied between requests.
+func (mp *MiddlewareProvider) NewCoalesceMiddleware(opts ...CoalesceOption) gin.HandlerFunc {
+	cfg := coalesceConfig{keyFunc: defaultCoalesceKey}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var group singleflight.Group
+
+	return func(ctx *gin.Context) {
+		method := ctx.Request.Method
+		if (method != http.MethodGet && method != http.MethodHead) ||
+			(!cfg.customKey && hasCredentials(ctx.Request)) {
+			ctx.Next()
+			return
+		}
+
+		leader := false
+		result, _, _ := group.Do(cfg.keyFunc(ctx), func() (any, error) {
+			leader = true
+			if resp := recordResponse(ctx); resp != nil && shareableResponse(resp) {
+				return resp, nil
+			}
+			return nil, nil
+		})
+		if leader {
+			return
+		}
+
+		resp, _ := result.(*recordedResponse)
+		if resp == nil {
+			ctx.Next()
+			return
+		}
+		resp.replay(ctx)
+	}
+}
+
+// CoalesceOption configures optional behaviour of NewCoalesceMiddleware.
+type CoalesceOption func(*coalesceConfig)
+
+type coalesceConfig struct {
+	keyFunc func(ctx *gin.Context) string
+	// customKey is set by WithCoalesceKey, whose key is trusted to tell callers apart.
+	customKey bool
+}
+
+// WithCoalesceKey sets the function that decides which requests share a response, and
+// makes requests with credentials coalesce too. Defaults to the method, path, raw query,
+// Accept and Accept-Language of the request.
+func WithCoalesceKey(keyFunc func(ctx *gin.Context) string) CoalesceOption {
+	return func(cfg *coalesceConfig) {
+		if keyFunc != nil {
+			cfg.keyFunc = keyFunc
+			cfg.customKey = true
+		}
+	}
+}
+
+func defaultCoalesceKey(ctx *gin.Context) string {
+	req := ctx.Request
+	return req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery +
+		"\n" + req.Header.Get("Accept") + "\n" + req.Header.Get("Accept-Language")
+}
+
+// recordedResponse is a response captured from a handler so it can be replayed to other requests.
+type recordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// recordResponse runs the rest of the chain, recording the response as it is written.
+// It returns nil if the handler attached errors or wrote nothing, as such responses
+// are finished by the error middleware and can't be shared.
+// Headers set before it was called are left out.
+func recordResponse(ctx *gin.Context) *recordedResponse {
+	preset := ctx.Writer.Header().Clone()
+	writer := &teeWriter{ResponseWriter: ctx.Writer}
+	ctx.Writer = writer
+	defer func() { ctx.Writer = writer.ResponseWriter }()
+
+	ctx.Next()
+
+	if len(ctx.Errors) > 0 || !writer.Written() {
+		return nil
+	}
+
+	header := make(http.Header)
+	for key, values := range writer.Header() {
+		if _, ok := preset[key]; !ok {
+			header[key] = slices.Clone(values)
+		}
+	}
+	return &recordedResponse{writer.Status(), header, writer.body.Bytes()}
+}
+
+// replay writes the recorded response and aborts the chain.
+func (rr *recordedResponse) replay(ctx *gin.Context) {
+	header := ctx.Writer.Header()
+	for key, values := range rr.Header {
+		header[key] = slices.Clone(values)
+	}
+	ctx.Status(rr.Status)
+	_, _ = ctx.Writer.Write(rr.Body)
+	ctx.Abort()
+}
+
+// teeWriter copies the response body into a buffer as it is written.
+type teeWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
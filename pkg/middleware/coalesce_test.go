@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCoalesceMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	// serveConcurrently sends n requests at once while the handler is held back,
+	// so they all join the group of the first one. header, if not nil, sets the
+	// headers of the i-th request.
+	serveConcurrently := func(
+		r *gin.Engine, n int, target string, entered, release chan struct{}, header func(i int, h http.Header),
+	) []*httptest.ResponseRecorder {
+		recorders := make([]*httptest.ResponseRecorder, n)
+		var wg sync.WaitGroup
+		for i := range recorders {
+			recorders[i] = httptest.NewRecorder()
+			req := httptest.NewRequest("GET", target, nil)
+			if header != nil {
+				header(i, req.Header)
+			}
+			wg.Go(func() {
+				r.ServeHTTP(recorders[i], req)
+			})
+		}
+		<-entered
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		return recorders
+	}
+
+	t.Run("identical requests share one execution", func(t *testing.T) {
+		var calls atomic.Int32
+		entered := make(chan struct{}, 10)
+		release := make(chan struct{})
+
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Header("X-Outer", ctx.Query("id"))
+			ctx.Next()
+		})
+		r.Use(mp.NewCoalesceMiddleware())
+		r.GET("/items", func(ctx *gin.Context) {
+			calls.Add(1)
+			entered <- struct{}{}
+			<-release
+			ctx.Header("X-Handler", "yes")
+			ctx.String(http.StatusAccepted, "items "+ctx.Query("id"))
+		})
+
+		for _, w := range serveConcurrently(r, 5, "/items?id=1", entered, release, nil) {
+			assert.Equal(t, http.StatusAccepted, w.Code)
+			assert.Equal(t, "items 1", w.Body.String())
+			assert.Equal(t, "yes", w.Header().Get("X-Handler"))
+			assert.Equal(t, []string{"1"}, w.Header().Values("X-Outer"))
+		}
+		assert.Equal(t, int32(1), calls.Load())
+
+		// later requests run the handler again
+		release = make(chan struct{})
+		close(release)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/items?id=2", nil))
+		assert.Equal(t, "items 2", w.Body.String())
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("errors are not shared", func(t *testing.T) {
+		var calls atomic.Int32
+		entered := make(chan struct{}, 10)
+		release := make(chan struct{})
+
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware())
+		r.Use(mp.NewCoalesceMiddleware())
+		r.GET("/items", func(ctx *gin.Context) {
+			if calls.Add(1) == 1 {
+				entered <- struct{}{}
+				<-release
+			}
+			_ = ctx.Error(ungerr.NotFoundError("no items"))
+		})
+
+		for _, w := range serveConcurrently(r, 3, "/items", entered, release, nil) {
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Contains(t, w.Body.String(), "no items")
+		}
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("requests with credentials are not coalesced", func(t *testing.T) {
+		var calls atomic.Int32
+		entered := make(chan struct{}, 10)
+		release := make(chan struct{})
+
+		r := gin.New()
+		r.Use(mp.NewCoalesceMiddleware())
+		r.GET("/me", func(ctx *gin.Context) {
+			calls.Add(1)
+			entered <- struct{}{}
+			<-release
+			ctx.String(http.StatusOK, ctx.GetHeader("Authorization"))
+		})
+
+		recorders := serveConcurrently(r, 2, "/me", entered, release, func(i int, h http.Header) {
+			h.Set("Authorization", fmt.Sprintf("Bearer user-%d", i))
+		})
+		for i, w := range recorders {
+			assert.Equal(t, fmt.Sprintf("Bearer user-%d", i), w.Body.String())
+		}
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("responses setting cookies are not shared", func(t *testing.T) {
+		var calls atomic.Int32
+		entered := make(chan struct{}, 10)
+		release := make(chan struct{})
+
+		r := gin.New()
+		r.Use(mp.NewCoalesceMiddleware())
+		r.GET("/items", func(ctx *gin.Context) {
+			n := calls.Add(1)
+			if n == 1 {
+				entered <- struct{}{}
+				<-release
+			}
+			ctx.SetCookie("session", fmt.Sprint(n), 0, "/", "", true, true)
+			ctx.String(http.StatusOK, "items")
+		})
+
+		sessions := map[string]bool{}
+		for _, w := range serveConcurrently(r, 3, "/items", entered, release, nil) {
+			assert.Equal(t, "items", w.Body.String())
+			if assert.Len(t, w.Result().Cookies(), 1) {
+				sessions[w.Result().Cookies()[0].Value] = true
+			}
+		}
+		assert.Len(t, sessions, 3, "every client gets its own cookie")
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("other methods pass through", func(t *testing.T) {
+		var calls atomic.Int32
+		r := gin.New()
+		r.Use(mp.NewCoalesceMiddleware())
+		r.POST("/items", func(ctx *gin.Context) {
+			calls.Add(1)
+			ctx.Status(http.StatusCreated)
+		})
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/items", nil))
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(3), calls.Load())
+	})
+}
+
+func TestDefaultCoalesceKey(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/items?page=2", nil)
+	ctx.Request.Header.Set("Accept", "application/xml")
+	ctx.Request.Header.Set("Accept-Language", "id")
+	assert.Equal(t, "GET /items?page=2\napplication/xml\nid", defaultCoalesceKey(ctx))
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewConcurrencyLimitMiddleware creates a load shedding middleware that caps the number of
+// requests handled at once to maxInFlight. Excess requests wait up to the queue timeout
+// (see WithConcurrencyQueueTimeout, none by default) for a slot, and are then rejected with
+// 503 Service Unavailable and a Retry-After of retryAfter, so a traffic spike can't exhaust
+// goroutines and memory.
+// The limit is shared by every route the returned handler is attached to: use it with
+// router.Use for a global cap, or create one per route or group for separate caps.
+func (mp *MiddlewareProvider) NewConcurrencyLimitMiddleware(
+	maxInFlight int,
+	retryAfter time.Duration,
+	opts ...ConcurrencyLimitOption,
+) gin.HandlerFunc {
+	if maxInFlight <= 0 {
+		mp.logger.Fatal("maxInFlight must be positive")
+	}
+
+	var cfg concurrencyLimitConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	slots := make(chan struct{}, maxInFlight)
+
+	return func(ctx *gin.Context) {
+		if !acquireSlot(ctx, slots, cfg.queueTimeout) {
+			mp.logger.Warnf("concurrency limit of %d reached, shedding %s %s", maxInFlight, ctx.Request.Method, ctx.Request.URL.Path)
+			mp.metrics.IncCounter(metricLoadShed, nil)
+			abortServiceUnavailable(ctx, retryAfter, "server is overloaded")
+			return
+		}
+		defer func() { <-slots }()
+
+		ctx.Next()
+	}
+}
+
+// ConcurrencyLimitOption configures optional behaviour of NewConcurrencyLimitMiddleware.
+type ConcurrencyLimitOption func(*concurrencyLimitConfig)
+
+type concurrencyLimitConfig struct {
+	queueTimeout time.Duration
+}
+
+// WithConcurrencyQueueTimeout lets requests wait up to timeout for a slot before being shed,
+// smoothing short bursts. Waiting stops early if the client goes away.
+func WithConcurrencyQueueTimeout(timeout time.Duration) ConcurrencyLimitOption {
+	return func(cfg *concurrencyLimitConfig) {
+		cfg.queueTimeout = timeout
+	}
+}
+
+func acquireSlot(ctx *gin.Context, slots chan struct{}, timeout time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	if timeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Request.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConcurrencyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+
+	newRouter := func(mw gin.HandlerFunc) (*gin.Engine, chan struct{}, chan struct{}) {
+		entered := make(chan struct{}, 10)
+		release := make(chan struct{})
+		r := gin.New()
+		r.Use(mw)
+		r.GET("/slow", func(ctx *gin.Context) {
+			entered <- struct{}{}
+			<-release
+			ctx.Status(http.StatusOK)
+		})
+		r.GET("/fast", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		return r, entered, release
+	}
+
+	serve := func(r *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	t.Run("sheds requests over the limit", func(t *testing.T) {
+		metrics := NewOpenMetricsRecorder()
+		mp := NewMiddlewareProvider(logger, WithMetricsRecorder(metrics))
+		r, entered, release := newRouter(mp.NewConcurrencyLimitMiddleware(1, 5*time.Second))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		var slow *httptest.ResponseRecorder
+		go func() {
+			defer wg.Done()
+			slow = serve(r, "/slow")
+		}()
+		<-entered
+
+		w := serve(r, "/fast")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "5", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "server is overloaded")
+		assert.Contains(t, metrics.Expose(), "ginkgo_load_shed_requests_total 1\n")
+
+		close(release)
+		wg.Wait()
+		assert.Equal(t, http.StatusOK, slow.Code)
+		assert.Equal(t, http.StatusOK, serve(r, "/fast").Code)
+	})
+
+	t.Run("queued request gets the freed slot", func(t *testing.T) {
+		mp := NewMiddlewareProvider(logger)
+		r, entered, release := newRouter(mp.NewConcurrencyLimitMiddleware(1, time.Second, WithConcurrencyQueueTimeout(5*time.Second)))
+
+		go serve(r, "/slow")
+		<-entered
+
+		done := make(chan int)
+		go func() { done <- serve(r, "/fast").Code }()
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		assert.Equal(t, http.StatusOK, <-done)
+	})
+
+	t.Run("queue timeout expires", func(t *testing.T) {
+		mp := NewMiddlewareProvider(logger)
+		r, entered, release := newRouter(mp.NewConcurrencyLimitMiddleware(1, time.Second, WithConcurrencyQueueTimeout(10*time.Millisecond)))
+		defer close(release)
+
+		go serve(r, "/slow")
+		<-entered
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(r, "/fast").Code)
+	})
+}
@@ -1,3 +1,25 @@
 package middleware
 
+import (
+	"github.com/itsLeonB/ezutil/v2"
+	"github.com/itsLeonB/ginkgo/pkg/i18n"
+)
+
 const packageName = "github.com/itsLeonB/ginkgo/pkg/middleware"
+
+const headerRequestID = "X-Request-ID"
+
+var (
+	loggerContextKey       = NewContextKey[ezutil.Logger](packageName + ".logger")
+	debugContextKey        = NewContextKey[bool](packageName + ".debug")
+	principalContextKey    = NewContextKey[Principal](packageName + ".principal")
+	claimsContextKey       = NewContextKey[any](packageName + ".claims")
+	authStrategyContextKey = NewContextKey[string](packageName + ".authStrategy")
+	sessionContextKey      = NewContextKey[Session](packageName + ".session")
+	deviceContextKey       = NewContextKey[string](packageName + ".device")
+	newDeviceContextKey    = NewContextKey[bool](packageName + ".newDevice")
+	oneTimeTokenContextKey = NewContextKey[string](packageName + ".oneTimeToken")
+	bundleContextKey       = NewContextKey[*i18n.Bundle](packageName + ".bundle")
+	localeContextKey       = NewContextKey[string](packageName + ".locale")
+	errorHandlerContextKey = NewContextKey[ErrorHandler](packageName + ".errorHandler")
+)
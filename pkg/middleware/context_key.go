@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+var contextKeySeq atomic.Uint64
+
+// ContextKey identifies a value of type T stored in the Gin context, such as the user ID
+// set by an auth middleware and read by handlers. Each key created by NewContextKey is
+// distinct, even from other keys with the same name, so middlewares can't overwrite each
+// other's values by accident, and Get can't return a value of the wrong type.
+type ContextKey[T any] struct {
+	name string
+	key  string
+}
+
+// NewContextKey creates a ContextKey for values of type T. The name only describes the
+// key, e.g. in String; declare keys once as package-level variables and share them.
+func NewContextKey[T any](name string) ContextKey[T] {
+	return ContextKey[T]{
+		name: name,
+		key:  name + "#" + strconv.FormatUint(contextKeySeq.Add(1), 10),
+	}
+}
+
+// Set stores value under the key in ctx.
+func (k ContextKey[T]) Set(ctx *gin.Context, value T) {
+	ctx.Set(k.key, value)
+}
+
+// Get returns the value stored under the key in ctx. The boolean is false if no value
+// was stored.
+func (k ContextKey[T]) Get(ctx *gin.Context) (T, bool) {
+	val, exists := ctx.Get(k.key)
+	if !exists {
+		var zero T
+		return zero, false
+	}
+	value, ok := val.(T)
+	return value, ok
+}
+
+// Value is like Get but returns the zero value of T if no value was stored.
+func (k ContextKey[T]) Value(ctx *gin.Context) T {
+	value, _ := k.Get(ctx)
+	return value
+}
+
+// String returns the name the key was created with.
+func (k ContextKey[T]) String() string {
+	return k.name
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := NewContextKey[string]("userID")
+	otherUserID := NewContextKey[string]("userID")
+	attempts := NewContextKey[int]("attempts")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	_, ok := userID.Get(c)
+	assert.False(t, ok)
+	assert.Zero(t, attempts.Value(c))
+
+	userID.Set(c, "user-1")
+	otherUserID.Set(c, "user-2")
+	attempts.Set(c, 3)
+	c.Set("userID", "raw")
+
+	val, ok := userID.Get(c)
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", val)
+	assert.Equal(t, "user-2", otherUserID.Value(c), "keys with the same name don't collide")
+	assert.Equal(t, 3, attempts.Value(c))
+	assert.Equal(t, "userID", userID.String())
+}
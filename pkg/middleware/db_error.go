@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/itsLeonB/ungerr"
+)
+
+// Error codes of database errors recognised by the error middleware.
+const (
+	ErrorCodeDuplicateResource  ErrorCode = "DUPLICATE_RESOURCE"
+	ErrorCodeResourceReferenced ErrorCode = "RESOURCE_REFERENCED"
+	ErrorCodeInvalidReference   ErrorCode = "INVALID_REFERENCE"
+	ErrorCodeConstraintViolated ErrorCode = "CONSTRAINT_VIOLATED"
+)
+
+// PostgreSQL SQLSTATE codes of integrity constraint violations.
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// WithNoRowsAsNotFound makes the error middleware answer sql.ErrNoRows with 404 Not Found.
+// It is opt-in because a missing row is not always the requested resource, e.g. when a
+// lookup of a related record fails.
+func WithNoRowsAsNotFound() ErrorOption {
+	return func(em *errorMiddleware) {
+		em.noRowsNotFound = true
+	}
+}
+
+// identifyDatabaseError maps well-known database errors to AppErrors: unique violations and
+// deleting or updating a referenced row to 409 Conflict, and references to missing rows,
+// NULLs in NOT NULL columns and failed CHECK constraints to 422 Unprocessable Entity, plus
+// sql.ErrNoRows to 404 Not Found with WithNoRowsAsNotFound. PostgreSQL errors are
+// recognised by their SQLState method, which *pgconn.PgError and *pq.Error both have;
+// register mysqlerrors.Mapper for MySQL.
+// The details never include the constraint or table name.
+// Register an ErrorMapper to map any of these differently, since mappers run first.
+func (em *errorMiddleware) identifyDatabaseError(err error) ungerr.AppError {
+	if em.noRowsNotFound && errors.Is(err, sql.ErrNoRows) {
+		return WithErrorCode(ungerr.NotFoundError("resource not found"), ErrorCodeNotFound)
+	}
+
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		switch e.SQLState() {
+		case pgUniqueViolation:
+			return duplicateResourceError()
+		case pgForeignKeyViolation:
+			// Raised both for inserting a dangling reference and deleting a referenced row;
+			// PostgreSQL tells them apart only in the message.
+			if strings.Contains(err.Error(), "update or delete on table") {
+				return WithErrorCode(ungerr.ConflictError("resource is still referenced"), ErrorCodeResourceReferenced)
+			}
+			return WithErrorCode(
+				ungerr.UnprocessableEntityError("referenced resource does not exist"),
+				ErrorCodeInvalidReference,
+			)
+		case pgNotNullViolation, pgCheckViolation:
+			return constraintViolatedError()
+		}
+	}
+	return nil
+}
+
+func duplicateResourceError() ungerr.AppError {
+	return WithErrorCode(ungerr.ConflictError("resource already exists"), ErrorCodeDuplicateResource)
+}
+
+func constraintViolatedError() ungerr.AppError {
+	return WithErrorCode(ungerr.UnprocessableEntityError("resource violates a constraint"), ErrorCodeConstraintViolated)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+// pgError mimics *pgconn.PgError, which reports its SQLSTATE through SQLState.
+type pgError struct {
+	code, message string
+}
+
+func (pe *pgError) Error() string    { return "ERROR: " + pe.message + " (SQLSTATE " + pe.code + ")" }
+func (pe *pgError) SQLState() string { return pe.code }
+
+func TestErrorMiddlewareDatabaseErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	cases := map[string]struct {
+		err    error
+		status int
+		code   ErrorCode
+	}{
+		"no rows":        {sql.ErrNoRows, http.StatusInternalServerError, ErrorCodeInternal},
+		"pg unique":      {&pgError{"23505", "duplicate key value violates unique constraint"}, http.StatusConflict, ErrorCodeDuplicateResource},
+		"pg foreign key": {&pgError{"23503", `insert or update on table "orders" violates foreign key constraint`}, http.StatusUnprocessableEntity, ErrorCodeInvalidReference},
+		"pg referenced":  {&pgError{"23503", `update or delete on table "users" violates foreign key constraint`}, http.StatusConflict, ErrorCodeResourceReferenced},
+		"pg not null":    {&pgError{"23502", "null value in column violates not-null constraint"}, http.StatusUnprocessableEntity, ErrorCodeConstraintViolated},
+		"pg other":       {&pgError{"40001", "could not serialize access"}, http.StatusInternalServerError, ErrorCodeInternal},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(mp.NewErrorMiddleware())
+			r.GET("/", func(ctx *gin.Context) {
+				_ = ctx.Error(ungerr.Wrap(fmt.Errorf("inserting user: %w", tc.err), "error creating user"))
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Contains(t, w.Body.String(), fmt.Sprintf(`"errorCode":"%s"`, tc.code))
+			assert.NotContains(t, w.Body.String(), "SQLSTATE")
+		})
+	}
+
+	t.Run("no rows as not found", func(t *testing.T) {
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware(WithNoRowsAsNotFound()))
+		r.GET("/", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.Wrap(sql.ErrNoRows, "error finding user"))
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), fmt.Sprintf(`"errorCode":"%s"`, ErrorCodeNotFound))
+	})
+
+	t.Run("registered mappers take precedence", func(t *testing.T) {
+		mp := NewMiddlewareProvider(logger)
+		mp.RegisterMapper(func(err error) (ungerr.AppError, bool) {
+			if err == sql.ErrNoRows {
+				return ungerr.BadRequestError("unknown user"), true
+			}
+			return nil, false
+		})
+
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware(WithNoRowsAsNotFound()))
+		r.GET("/", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.Wrap(sql.ErrNoRows, "error finding user"))
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
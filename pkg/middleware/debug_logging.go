@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	headerDebug   = "X-Debug"
+	queryDebug    = "debug"
+	maxDebugBytes = 64 << 10
+)
+
+// debugRedactor masks credentials in the headers and bodies logged for debug requests.
+var debugRedactor = newRedactor(defaultRedactedFields, defaultRedactedHeaders)
+
+// NewDebugOverrideMiddleware creates a middleware that elevates logging verbosity for a
+// single request when it carries an "X-Debug: true" header or a "debug=true" query flag.
+// The override only applies when allow returns true (see AllowRoles and AllowIPs), so
+// arbitrary clients cannot turn it on. Debug requests get their headers, request body and
+// response body logged through the request-scoped logger, with credentials such as the
+// Authorization header and password fields redacted, and IsDebugRequest reports true
+// so handlers can log extra fields. Register it after NewRequestLoggerMiddleware.
+func (mp *MiddlewareProvider) NewDebugOverrideMiddleware(allow func(ctx *gin.Context) bool) gin.HandlerFunc {
+	if allow == nil {
+		mp.logger.Fatal("allow cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		if !debugRequested(ctx) || !allow(ctx) {
+			ctx.Next()
+			return
+		}
+
+		debugContextKey.Set(ctx, true)
+		logger := GetLogger(ctx).WithField("debug", true)
+
+		reqBody := captureRequestBody(ctx, maxDebugBytes)
+		logger.
+			WithFields(map[string]any{
+				"http.request.headers": debugRedactor.redactHeaders(ctx.Request.Header),
+				"http.request.body":    debugRedactor.redactBody(ctx.GetHeader("Content-Type"), reqBody, len(reqBody) >= maxDebugBytes),
+			}).
+			Info("debug request")
+
+		writer := newBodyCaptureWriter(ctx.Writer, maxDebugBytes)
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		logger.
+			WithFields(map[string]any{
+				"http.status_code":   writer.Status(),
+				"http.response.body": debugRedactor.redactBody(writer.Header().Get("Content-Type"), writer.body.Bytes(), writer.body.Len() >= maxDebugBytes),
+			}).
+			Info("debug response")
+	}
+}
+
+// IsDebugRequest reports whether verbose logging was enabled for the current request
+// by NewDebugOverrideMiddleware.
+func IsDebugRequest(ctx *gin.Context) bool {
+	return debugContextKey.Value(ctx)
+}
+
+// AllowRoles returns a predicate for NewDebugOverrideMiddleware that permits the override
+// only when the role stored under roleContextKey is one of roles.
+func AllowRoles(roleContextKey string, roles ...string) func(ctx *gin.Context) bool {
+	return func(ctx *gin.Context) bool {
+		return slices.Contains(roles, ctx.GetString(roleContextKey))
+	}
+}
+
+// AllowIPs returns a predicate for NewDebugOverrideMiddleware that permits the override
+// only for the given client IPs or CIDR ranges. Invalid entries are ignored.
+func AllowIPs(ips ...string) func(ctx *gin.Context) bool {
+	var nets []*net.IPNet
+	for _, ip := range ips {
+		if !strings.Contains(ip, "/") {
+			if strings.Contains(ip, ":") {
+				ip += "/128"
+			} else {
+				ip += "/32"
+			}
+		}
+		if _, ipNet, err := net.ParseCIDR(ip); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+
+	return func(ctx *gin.Context) bool {
+		clientIP := net.ParseIP(ctx.ClientIP())
+		if clientIP == nil {
+			return false
+		}
+		for _, ipNet := range nets {
+			if ipNet.Contains(clientIP) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func debugRequested(ctx *gin.Context) bool {
+	return strings.EqualFold(ctx.GetHeader(headerDebug), "true") ||
+		strings.EqualFold(ctx.Query(queryDebug), "true")
+}
+
+// captureRequestBody reads up to limit bytes of the request body and restores it
+// so downstream handlers can still bind it.
+func captureRequestBody(ctx *gin.Context, limit int64) []byte {
+	if ctx.Request.Body == nil {
+		return nil
+	}
+
+	captured, err := io.ReadAll(io.LimitReader(ctx.Request.Body, limit))
+	if err != nil {
+		return nil
+	}
+	ctx.Request.Body = readCloser{io.MultiReader(bytes.NewReader(captured), ctx.Request.Body), ctx.Request.Body}
+
+	return captured
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyCaptureWriter tees up to limit bytes of the response body into a buffer.
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body  *bytes.Buffer
+	limit int
+}
+
+func newBodyCaptureWriter(w gin.ResponseWriter, limit int) *bodyCaptureWriter {
+	return &bodyCaptureWriter{w, &bytes.Buffer{}, limit}
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDebugOverrideMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	newRouter := func(allow func(ctx *gin.Context) bool, debug *bool) *gin.Engine {
+		r := gin.New()
+		r.Use(mp.NewDebugOverrideMiddleware(allow))
+		r.POST("/", func(c *gin.Context) {
+			*debug = IsDebugRequest(c)
+			body, _ := io.ReadAll(c.Request.Body)
+			c.String(http.StatusOK, string(body))
+		})
+		return r
+	}
+
+	t.Run("allowed debug header", func(t *testing.T) {
+		var debug bool
+		r := newRouter(func(*gin.Context) bool { return true }, &debug)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+		req.Header.Set("X-Debug", "true")
+		r.ServeHTTP(w, req)
+
+		assert.True(t, debug)
+		assert.Equal(t, "payload", w.Body.String())
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		var debug bool
+		r := newRouter(func(*gin.Context) bool { return false }, &debug)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/?debug=true", strings.NewReader("payload"))
+		r.ServeHTTP(w, req)
+
+		assert.False(t, debug)
+		assert.Equal(t, "payload", w.Body.String())
+	})
+}
+
+func TestAllowIPs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	allow := AllowIPs("10.0.0.0/8", "127.0.0.1")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	c.Request.RemoteAddr = "10.1.2.3:1234"
+	assert.True(t, allow(c))
+
+	c.Request.RemoteAddr = "127.0.0.1:1234"
+	assert.True(t, allow(c))
+
+	c.Request.RemoteAddr = "192.168.0.1:1234"
+	assert.False(t, allow(c))
+}
+
+func TestAllowRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	allow := AllowRoles("role", "admin")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Set("role", "admin")
+	assert.True(t, allow(c))
+
+	c.Set("role", "user")
+	assert.False(t, allow(c))
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceFingerprintConfig configures how NewDeviceFingerprintMiddleware identifies devices.
+type DeviceFingerprintConfig struct {
+	// Headers are hashed into the fingerprint. Defaults to User-Agent and Accept-Language.
+	Headers []string
+	// CookieName, if set, issues a long-lived random device cookie that is mixed into the
+	// fingerprint, making it stable across IP changes and distinct between identical browsers.
+	CookieName string
+	// IsKnownDevice reports whether the fingerprint has been seen for the current identity.
+	// If nil, new-device detection is disabled.
+	IsKnownDevice func(ctx *gin.Context, fingerprint string) (bool, error)
+	// OnNewDevice is called when IsKnownDevice returns false, e.g. to trigger step-up
+	// authentication or send a notification. Returning an error aborts the request.
+	OnNewDevice func(ctx *gin.Context, fingerprint string) error
+}
+
+const deviceCookieMaxAge = 400 * 24 * 60 * 60
+
+// NewDeviceFingerprintMiddleware creates a middleware that derives a stable device fingerprint
+// and stores it in the Gin context (see DeviceFingerprint). When cfg.IsKnownDevice is set,
+// unknown devices are flagged (see IsNewDevice) and reported to cfg.OnNewDevice.
+// Register it after the auth middleware so the hooks can look up the current Principal.
+func (mp *MiddlewareProvider) NewDeviceFingerprintMiddleware(cfg DeviceFingerprintConfig) gin.HandlerFunc {
+	if len(cfg.Headers) == 0 {
+		cfg.Headers = []string{"User-Agent", "Accept-Language"}
+	}
+
+	return func(ctx *gin.Context) {
+		deviceID, err := deviceCookie(ctx, cfg.CookieName)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		fingerprint := computeFingerprint(ctx, deviceID, cfg.Headers)
+		deviceContextKey.Set(ctx, fingerprint)
+
+		if cfg.IsKnownDevice == nil {
+			ctx.Next()
+			return
+		}
+
+		known, err := cfg.IsKnownDevice(ctx, fingerprint)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+		if !known {
+			newDeviceContextKey.Set(ctx, true)
+			if cfg.OnNewDevice != nil {
+				if err = cfg.OnNewDevice(ctx, fingerprint); err != nil {
+					_ = ctx.Error(err)
+					ctx.Abort()
+					return
+				}
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// DeviceFingerprint returns the fingerprint computed by NewDeviceFingerprintMiddleware.
+func DeviceFingerprint(ctx *gin.Context) string {
+	return deviceContextKey.Value(ctx)
+}
+
+// IsNewDevice reports whether the current request comes from a device not yet known
+// for the current identity.
+func IsNewDevice(ctx *gin.Context) bool {
+	return newDeviceContextKey.Value(ctx)
+}
+
+func deviceCookie(ctx *gin.Context, cookieName string) (string, error) {
+	if cookieName == "" {
+		return "", nil
+	}
+	if deviceID, err := ctx.Cookie(cookieName); err == nil && deviceID != "" {
+		return deviceID, nil
+	}
+
+	deviceID, err := newRandomID()
+	if err != nil {
+		return "", err
+	}
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(cookieName, deviceID, deviceCookieMaxAge, "/", "", ctx.Request.TLS != nil, true)
+	return deviceID, nil
+}
+
+func computeFingerprint(ctx *gin.Context, deviceID string, headers []string) string {
+	h := sha256.New()
+	h.Write([]byte(deviceID))
+	for _, header := range headers {
+		h.Write([]byte{0})
+		h.Write([]byte(ctx.GetHeader(header)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
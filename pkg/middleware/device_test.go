@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeviceFingerprintMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	t.Run("stable fingerprint", func(t *testing.T) {
+		mw := mp.NewDeviceFingerprintMiddleware(DeviceFingerprintConfig{})
+
+		fingerprint := func() string {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			c.Request.Header.Set("User-Agent", "test-agent")
+			mw(c)
+			return DeviceFingerprint(c)
+		}
+
+		first := fingerprint()
+		assert.NotEmpty(t, first)
+		assert.Equal(t, first, fingerprint())
+	})
+
+	t.Run("issues device cookie", func(t *testing.T) {
+		mw := mp.NewDeviceFingerprintMiddleware(DeviceFingerprintConfig{CookieName: "device_id"})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		mw(c)
+
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.Equal(t, "device_id", cookies[0].Name)
+	})
+
+	t.Run("new device hook", func(t *testing.T) {
+		var reported string
+		mw := mp.NewDeviceFingerprintMiddleware(DeviceFingerprintConfig{
+			IsKnownDevice: func(ctx *gin.Context, fingerprint string) (bool, error) { return false, nil },
+			OnNewDevice: func(ctx *gin.Context, fingerprint string) error {
+				reported = fingerprint
+				return nil
+			},
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		assert.True(t, IsNewDevice(c))
+		assert.Equal(t, DeviceFingerprint(c), reported)
+	})
+
+	t.Run("new device hook aborts", func(t *testing.T) {
+		mw := mp.NewDeviceFingerprintMiddleware(DeviceFingerprintConfig{
+			IsKnownDevice: func(ctx *gin.Context, fingerprint string) (bool, error) { return false, nil },
+			OnNewDevice: func(ctx *gin.Context, fingerprint string) error {
+				return ungerr.UnauthorizedError("step-up authentication required")
+			},
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, c.Errors)
+	})
+}
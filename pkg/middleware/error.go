@@ -1,13 +1,22 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"mime"
+	"net/http"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/itsLeonB/ezutil/v2"
 	"github.com/itsLeonB/ginkgo/pkg/response"
@@ -18,13 +27,90 @@ import (
 )
 
 type errorMiddleware struct {
-	logger ezutil.Logger
-	tracer trace.Tracer
+	logger         ezutil.Logger
+	tracer         trace.Tracer
+	metrics        MetricsRecorder
+	reporter       ErrorReporter
+	mappers        *errorMappers
+	translator     *ut.UniversalTranslator
+	problemDetails ProblemDetailsMode
+	mode           ErrorMode
+	panicHooks     []PanicHook
+	retryAfter     time.Duration
+	conciseClient  bool
+	clientLevel    LogLevel
+	dedup          *errorDeduplicator
+	noRowsNotFound bool
+}
+
+// ErrorOption configures optional behaviour of NewErrorMiddleware.
+type ErrorOption func(*errorMiddleware)
+
+// ProblemDetailsMode selects when the error middleware answers with RFC 7807 problem details.
+type ProblemDetailsMode int
+
+const (
+	// ProblemDetailsOff always renders errors in the JSONResponse envelope. This is the default.
+	ProblemDetailsOff ProblemDetailsMode = iota
+	// ProblemDetailsAlways always renders errors as application/problem+json.
+	ProblemDetailsAlways
+	// ProblemDetailsNegotiated renders application/problem+json for clients that list it
+	// in their Accept header, and the JSONResponse envelope for everyone else.
+	ProblemDetailsNegotiated
+)
+
+// WithProblemDetails makes the error middleware render errors as RFC 7807 problem details
+// (type, title, status, detail and instance, plus request_id and, for validation errors,
+// an errors list) according to mode.
+func WithProblemDetails(mode ProblemDetailsMode) ErrorOption {
+	return func(em *errorMiddleware) {
+		em.problemDetails = mode
+	}
+}
+
+// ErrorMode selects how much of an error the error middleware reveals to clients.
+type ErrorMode int
+
+const (
+	// ErrorModeProduction masks errors behind their AppError. This is the default.
+	ErrorModeProduction ErrorMode = iota
+	// ErrorModeDevelopment additionally includes the original error message, its type and,
+	// for panics, the stack trace in every error response. Never enable it in production.
+	ErrorModeDevelopment
+)
+
+// WithErrorMode sets the ErrorMode of the error middleware, e.g. ErrorModeDevelopment
+// when running locally so 500s can be debugged without tailing the logs.
+func WithErrorMode(mode ErrorMode) ErrorOption {
+	return func(em *errorMiddleware) {
+		em.mode = mode
+	}
+}
+
+// WithConciseClientErrors makes the error middleware log 4xx errors as a single "client error"
+// line at level, with only the status, error code and detail, instead of the full error chain
+// with the location of every wrap. Full diagnostics are then only logged for 5xx errors and
+// panics, so malformed client requests don't drown real problems.
+func WithConciseClientErrors(level LogLevel) ErrorOption {
+	return func(em *errorMiddleware) {
+		em.conciseClient = true
+		em.clientLevel = level
+	}
 }
 
 type errorObject struct {
-	Code   string `json:"code"`
-	Detail any    `json:"detail"`
+	Code      string      `json:"code"`
+	ErrorCode ErrorCode   `json:"errorCode,omitempty"`
+	Detail    any         `json:"detail"`
+	Debug     *errorDebug `json:"debug,omitempty"`
+}
+
+// errorDebug is the original error included in responses in ErrorModeDevelopment.
+// For errors created by ungerr, Error includes the location of every wrap.
+type errorDebug struct {
+	Error      string     `json:"error"`
+	Type       string     `json:"type"`
+	StackTrace stackTrace `json:"stackTrace,omitempty"`
 }
 
 func (eo errorObject) Error() string {
@@ -36,18 +122,106 @@ func (eo errorObject) Error() string {
 // from all subsequent middlewares and handlers, even if they abort.
 // This converts them into AppError or validation errors, and sends a structured JSON response
 // with the appropriate HTTP status code. Returns a Gin HandlerFunc.
-func newErrorMiddleware(logger ezutil.Logger) gin.HandlerFunc {
-	m := &errorMiddleware{logger: logger, tracer: otel.GetTracerProvider().Tracer(packageName)}
+// Server errors and panics are also sent to the ErrorReporter, if one is configured.
+// Validation errors are rendered as a message per field keyed by its JSON name; see
+// RegisterJSONFieldNames, which this registers on Gin's default validator.
+func newErrorMiddleware(
+	logger ezutil.Logger,
+	metrics MetricsRecorder,
+	reporter ErrorReporter,
+	mappers *errorMappers,
+	opts []ErrorOption,
+) gin.HandlerFunc {
+	useJSONFieldNamesForBinding()
+
+	m := &errorMiddleware{
+		logger:   logger,
+		tracer:   otel.GetTracerProvider().Tracer(packageName),
+		metrics:  metrics,
+		reporter: reporter,
+		mappers:  mappers,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
 	return m.handle
 }
 
-func appErrorToErrorObject(appError ungerr.AppError) any {
+func appErrorToErrorObject(ctx *gin.Context, appError ungerr.AppError, debug *errorDebug) response.JSONResponse {
+	detail := appError.Details()
+	if msg, ok := detail.(string); ok {
+		detail = Translate(ctx, msg)
+	}
 	return response.NewErrorResponse(errorObject{
-		Code:   appError.Error(),
-		Detail: appError.Details(),
+		Code:      appError.Error(),
+		ErrorCode: errorCode(appError),
+		Detail:    detail,
+		Debug:     debug,
 	})
 }
 
+func appErrorToProblem(ctx *gin.Context, appError ungerr.AppError, debug *errorDebug) response.ProblemDetails {
+	problem := response.NewProblemDetails(appError.HttpStatus(), "")
+	problem.Title = appError.Error()
+	problem.Instance = ctx.Request.URL.Path
+	problem.Extensions = map[string]any{"code": errorCode(appError)}
+
+	switch detail := appError.Details().(type) {
+	case nil:
+	case string:
+		problem.Detail = Translate(ctx, detail)
+	default:
+		problem.Extensions["errors"] = detail
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		problem.Extensions["request_id"] = requestID
+	}
+	if debug != nil {
+		problem.Extensions["debug"] = debug
+	}
+	return problem
+}
+
+// respond aborts the request with appError, as problem details or in the JSONResponse envelope.
+// In ErrorModeDevelopment the response also reveals err and, for panics, stack.
+func (em *errorMiddleware) respond(ctx *gin.Context, appError ungerr.AppError, err error, stack stackTrace) {
+	var debug *errorDebug
+	if em.mode == ErrorModeDevelopment {
+		debug = &errorDebug{Error: err.Error(), Type: fmt.Sprintf("%T", err), StackTrace: stack}
+	}
+	if ctx.Writer.Written() {
+		ctx.Abort()
+		em.requestLogger(ctx).
+			WithField("http.status_code", ctx.Writer.Status()).
+			Warn("response already written, could not send error response")
+		return
+	}
+	em.setRetryAfterHeader(ctx, appError)
+
+	if em.problemDetails == ProblemDetailsAlways ||
+		(em.problemDetails == ProblemDetailsNegotiated && acceptsProblemDetails(ctx.GetHeader("Accept"))) {
+		response.AbortWithProblem(ctx, appErrorToProblem(ctx, appError, debug))
+		return
+	}
+	response.AbortWithResponse(ctx, appError.HttpStatus(), appErrorToErrorObject(ctx, appError, debug))
+}
+
+// acceptsProblemDetails reports whether an Accept header lists application/problem+json
+// with a non-zero quality.
+func acceptsProblemDetails(accept string) bool {
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != response.ProblemContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
 func (em *errorMiddleware) handle(ctx *gin.Context) {
 	c, span := em.tracer.Start(ctx.Request.Context(), "ErrorMiddleware.handle")
 	defer span.End()
@@ -55,119 +229,266 @@ func (em *errorMiddleware) handle(ctx *gin.Context) {
 
 	defer func() {
 		if r := recover(); r != nil {
+			if _, ok := r.(abortPanic); ok {
+				em.handleErrors(ctx, span)
+				return
+			}
 			em.handlePanic(r, ctx, span)
 		}
 	}()
 
 	ctx.Next()
+	em.handleErrors(ctx, span)
+}
 
+// handleErrors responds to the last error attached to the Gin context, if any.
+func (em *errorMiddleware) handleErrors(ctx *gin.Context, span trace.Span) {
 	ginErr := ctx.Errors.Last()
 	if ginErr == nil {
 		return
 	}
 
 	err := ginErr.Err
-	logCtx := em.logger.WithContext(ctx)
+	if em.handledByOverride(ctx, err, span) {
+		return
+	}
+	logCtx := em.requestLogger(ctx)
 
 	// Already a well-typed AppError — warn and respond.
 	if appError, ok := err.(ungerr.AppError); ok {
 		span.RecordError(appError)
 		span.SetStatus(codes.Error, "application error")
-		logCtx.WithError(appError).Warn("application error")
-		ctx.AbortWithStatusJSON(appError.HttpStatus(), appErrorToErrorObject(appError))
+		if !em.logClientError(ctx, appError) {
+			logCtx.WithError(appError).Warn("application error")
+		}
+		em.countOutcome("app_error", appError)
+		em.report(ctx, appError, appError)
+		em.respond(ctx, appError, appError, nil)
 		return
 	}
 
 	// UnknownError has two distinct log messages depending on whether a cause is present.
 	if unknownErr, ok := err.(*ungerr.UnknownError); ok {
 		logCtx = logCtx.WithError(unknownErr)
+		var outcome string
 		if cause := ungerr.Unwrap(err); cause != nil {
 			span.RecordError(cause)
 			span.SetStatus(codes.Error, "wrapped error")
-			if appError := em.identifyKnownError(cause); appError != nil {
+			if appError := em.identifyKnownError(ctx, cause); appError != nil {
 				span.SetStatus(codes.Error, "identified error")
-				logCtx.WithError(appError).Warn("identified wrapped error")
-				ctx.AbortWithStatusJSON(appError.HttpStatus(), appErrorToErrorObject(appError))
+				if !em.logClientError(ctx, appError) {
+					logCtx.WithError(appError).Warn("identified wrapped error")
+				}
+				em.countOutcome("identified_error", appError)
+				em.report(ctx, err, appError)
+				em.respond(ctx, appError, err, nil)
 				return
 			}
-			logCtx.Error("unhandled error") // only if truly unidentifiable
+			em.logError(ctx, logCtx, err, "unhandled error") // only if truly unidentifiable
+			outcome = "unhandled_error"
 		} else {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "unexpected error")
-			logCtx.Error("unexpected error")
+			em.logError(ctx, logCtx, err, "unexpected error")
+			outcome = "unexpected_error"
 		}
 		appError := ungerr.InternalServerError()
-		ctx.AbortWithStatusJSON(appError.HttpStatus(), appErrorToErrorObject(appError))
+		em.countOutcome(outcome, appError)
+		em.report(ctx, err, appError)
+		em.respond(ctx, appError, err, nil)
 		return
 	}
 
 	// Try to map remaining known error types (validation, JSON, network, etc.).
-	appError := em.identifyKnownError(err)
+	appError := em.identifyKnownError(ctx, err)
 	if appError != nil {
-		logCtx.WithError(appError).Warn("application error")
+		if !em.logClientError(ctx, appError) {
+			logCtx.WithError(appError).Warn("application error")
+		}
+		em.countOutcome("identified_error", appError)
 	} else {
 		// Completely unrecognised error — developer forgot to wrap with ungerr.Wrap().
-		logCtx.
-			WithError(err).
-			WithField("handler", ctx.HandlerName()).
-			Error("unwrapped error detected — wrap with ungerr.Wrap()")
+		em.logError(ctx,
+			logCtx.WithError(err).WithField("handler", ctx.HandlerName()),
+			err,
+			"unwrapped error detected — wrap with ungerr.Wrap()",
+		)
 		appError = ungerr.InternalServerError()
+		em.countOutcome("unwrapped_error", appError)
 	}
 
 	span.RecordError(appError)
 	span.SetStatus(codes.Error, "application error")
-	ctx.AbortWithStatusJSON(appError.HttpStatus(), appErrorToErrorObject(appError))
+	em.report(ctx, err, appError)
+	em.respond(ctx, appError, err, nil)
+}
+
+// report sends err to the ErrorReporter if the response is a server error.
+func (em *errorMiddleware) report(ctx *gin.Context, err error, appError ungerr.AppError) {
+	if em.reporter == nil || appError.HttpStatus() < http.StatusInternalServerError {
+		return
+	}
+	em.reporter.Report(ctx, err, errorReportMeta(ctx, appError.HttpStatus()))
+}
+
+// logClientError logs appError concisely if WithConciseClientErrors is set and it is a
+// client error, reporting whether it did.
+func (em *errorMiddleware) logClientError(ctx *gin.Context, appError ungerr.AppError) bool {
+	if !em.conciseClient || appError.HttpStatus() >= http.StatusInternalServerError {
+		return false
+	}
+	logger := em.requestLogger(ctx).WithFields(map[string]any{
+		"http.status_code": appError.HttpStatus(),
+		"error.code":       errorCode(appError),
+		"error.detail":     appError.Details(),
+	})
+	logAccess(logger, em.clientLevel, "client error")
+	return true
+}
+
+// requestLogger returns the logger for the current request, tagged with its request ID if any.
+func (em *errorMiddleware) requestLogger(ctx *gin.Context) ezutil.Logger {
+	logger := em.logger.WithContext(ctx.Request.Context())
+	if requestID := RequestID(ctx); requestID != "" {
+		logger = logger.WithField("http.request_id", requestID)
+	}
+	return logger
+}
+
+func (em *errorMiddleware) countOutcome(outcome string, appError ungerr.AppError) {
+	em.metrics.IncCounter(metricErrorOutcomes, map[string]string{
+		"outcome": outcome,
+		"status":  strconv.Itoa(appError.HttpStatus()),
+	})
+}
+
+// identifyKnownError maps the first recognisable error in err's chain to an AppError,
+// or returns nil if none is recognised. Each error in the chain is offered to the registered
+// mappers first, then matched against AppError and the known validation, JSON and
+// connection errors.
+func (em *errorMiddleware) identifyKnownError(ctx *gin.Context, err error) ungerr.AppError {
+	for cause := range errorChain(err) {
+		if appError := em.identifyError(ctx, cause); appError != nil {
+			return appError
+		}
+	}
+	return nil
 }
 
-func (em *errorMiddleware) identifyKnownError(err error) ungerr.AppError {
+func (em *errorMiddleware) identifyError(ctx *gin.Context, err error) ungerr.AppError {
+	if appError := em.mappers.mapError(err); appError != nil {
+		return appError
+	}
+
 	switch e := err.(type) {
+	case ungerr.AppError:
+		return e
+
 	case validator.ValidationErrors:
-		msgs := make([]string, len(e))
-		for i, ve := range e {
-			msgs[i] = ve.Error()
-		}
-		return ungerr.ValidationError(msgs)
+		return WithErrorCode(ungerr.ValidationError(em.validationDetails(ctx, e)), ErrorCodeValidationFailed)
 
 	case *json.SyntaxError:
-		return ungerr.BadRequestError("invalid json")
+		return WithErrorCode(ungerr.BadRequestError("invalid json"), ErrorCodeInvalidJSON)
 
 	case *json.UnmarshalTypeError:
-		return ungerr.BadRequestError(fmt.Sprintf("invalid value for field %s", e.Field))
+		return WithErrorCode(
+			ungerr.BadRequestError(fmt.Sprintf("invalid value for field %s", e.Field)),
+			ErrorCodeInvalidFieldValue,
+		)
+	}
 
-	default:
-		errStr := e.Error()
-		if e == io.EOF || errStr == "EOF" {
-			return ungerr.BadRequestError("missing request body")
-		}
+	if appError := em.identifyDatabaseError(err); appError != nil {
+		return appError
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return WithErrorCode(gatewayTimeoutError("request timed out"), ErrorCodeGatewayTimeout)
+	}
+	if errors.Is(err, context.Canceled) {
+		return WithErrorCode(clientClosedRequestError("request canceled"), ErrorCodeClientClosedRequest)
+	}
+	if errors.Is(err, io.EOF) || err.Error() == "EOF" {
+		return WithErrorCode(ungerr.BadRequestError("missing request body"), ErrorCodeMissingBody)
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return WithErrorCode(ungerr.BadRequestError("connection error"), ErrorCodeConnectionError)
+	}
+	if _, ok := err.(interface{ Unwrap() error }); !ok {
+		// Errors from some transports only carry the reason in their message.
+		errStr := err.Error()
 		if strings.Contains(errStr, "connection reset by peer") ||
 			strings.Contains(errStr, "broken pipe") {
-			return ungerr.BadRequestError("connection error")
+			return WithErrorCode(ungerr.BadRequestError("connection error"), ErrorCodeConnectionError)
+		}
+	}
+	return nil
+}
+
+// maxErrorChainDepth bounds errorChain against cyclic or pathological wrapping.
+const maxErrorChainDepth = 64
+
+// errorChain yields err and every error it wraps, depth first. Besides Unwrap() error and
+// Unwrap() []error, it also follows the cause of *ungerr.UnknownError, which has no Unwrap
+// method and would otherwise end the chain for errors.As and errors.Is.
+func errorChain(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		walkErrorChain(err, 0, yield)
+	}
+}
+
+func walkErrorChain(err error, depth int, yield func(error) bool) bool {
+	if err == nil || depth > maxErrorChainDepth {
+		return true
+	}
+	if !yield(err) {
+		return false
+	}
+
+	switch e := err.(type) {
+	case *ungerr.UnknownError:
+		return walkErrorChain(ungerr.Unwrap(e), depth+1, yield)
+	case interface{ Unwrap() error }:
+		return walkErrorChain(e.Unwrap(), depth+1, yield)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if !walkErrorChain(wrapped, depth+1, yield) {
+				return false
+			}
 		}
-		return nil
 	}
+	return true
 }
 
 func (em *errorMiddleware) handlePanic(r any, ctx *gin.Context, span trace.Span) {
-	em.logger.
-		WithContext(ctx.Request.Context()).
+	stack := stackTrace(debug.Stack())
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", r)
+	}
+	logger := em.requestLogger(ctx).
 		WithFields(map[string]any{
 			"handler":     ctx.HandlerName(),
 			"panic.type":  fmt.Sprintf("%T", r),
 			"panic.value": fmt.Sprintf("%v", r),
-			"stack_trace": string(debug.Stack()),
-		}).
-		Error("panic recovered")
+			"stack_trace": stack,
+		})
+	em.logError(ctx, logger, err, "panic recovered")
+	em.metrics.IncCounter(metricPanicsRecovered, nil)
+	em.runPanicHooks(ctx, r, stack)
+	if em.reporter != nil {
+		meta := errorReportMeta(ctx, http.StatusInternalServerError)
+		meta["stack_trace"] = stack.String()
+		em.reporter.Report(ctx, err, meta)
+	}
 
 	appError := ungerr.InternalServerError()
 	span.RecordError(appError)
 	span.SetStatus(codes.Error, "panic recovered")
 
 	if ctx.Writer.Written() {
-		em.logger.
-			WithContext(ctx.Request.Context()).
+		em.requestLogger(ctx).
 			WithField("http.status_code", ctx.Writer.Status()).
 			Error("response already written after panic, could not send error JSON")
 		return
 	}
-	ctx.AbortWithStatusJSON(appError.HttpStatus(), appErrorToErrorObject(appError))
+	em.respond(ctx, appError, err, stack)
 }
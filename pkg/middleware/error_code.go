@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/itsLeonB/ungerr"
+)
+
+// ErrorCode is a stable, machine-readable identifier of an error, rendered as errorCode in
+// error responses so clients can branch on it instead of parsing the human-readable detail.
+type ErrorCode string
+
+// Error codes set by ginkgo. Applications define their own codes as further ErrorCode constants.
+const (
+	ErrorCodeBadRequest          ErrorCode = "BAD_REQUEST"
+	ErrorCodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	ErrorCodeForbidden           ErrorCode = "FORBIDDEN"
+	ErrorCodeNotFound            ErrorCode = "NOT_FOUND"
+	ErrorCodeMethodNotAllowed    ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrorCodeConflict            ErrorCode = "CONFLICT"
+	ErrorCodePayloadTooLarge     ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrorCodeUnprocessableEntity ErrorCode = "UNPROCESSABLE_ENTITY"
+	ErrorCodeRateLimited         ErrorCode = "RATE_LIMITED"
+	ErrorCodeInternal            ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeServiceUnavailable  ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrorCodeGatewayTimeout      ErrorCode = "GATEWAY_TIMEOUT"
+	ErrorCodeClientClosedRequest ErrorCode = "CLIENT_CLOSED_REQUEST"
+	ErrorCodeValidationFailed    ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeInvalidJSON         ErrorCode = "INVALID_JSON"
+	ErrorCodeInvalidFieldValue   ErrorCode = "INVALID_FIELD_VALUE"
+	ErrorCodeMissingBody         ErrorCode = "MISSING_BODY"
+	ErrorCodeConnectionError     ErrorCode = "CONNECTION_ERROR"
+)
+
+// statusErrorCodes are the codes of errors that have no code attached, by HTTP status.
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusBadRequest:            ErrorCodeBadRequest,
+	http.StatusUnauthorized:          ErrorCodeUnauthorized,
+	http.StatusForbidden:             ErrorCodeForbidden,
+	http.StatusNotFound:              ErrorCodeNotFound,
+	http.StatusMethodNotAllowed:      ErrorCodeMethodNotAllowed,
+	http.StatusConflict:              ErrorCodeConflict,
+	http.StatusRequestEntityTooLarge: ErrorCodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   ErrorCodeUnprocessableEntity,
+	http.StatusTooManyRequests:       ErrorCodeRateLimited,
+	http.StatusInternalServerError:   ErrorCodeInternal,
+	http.StatusServiceUnavailable:    ErrorCodeServiceUnavailable,
+	http.StatusGatewayTimeout:        ErrorCodeGatewayTimeout,
+	StatusClientClosedRequest:        ErrorCodeClientClosedRequest,
+}
+
+// codedError is an AppError with an ErrorCode attached.
+type codedError struct {
+	ungerr.AppError
+	code ErrorCode
+}
+
+func (ce codedError) ErrorCode() ErrorCode {
+	return ce.code
+}
+
+func (ce codedError) Unwrap() error {
+	return ce.AppError
+}
+
+// WithErrorCode attaches code to appError, e.g.
+//
+//	middleware.WithErrorCode(ungerr.ConflictError("email is already registered"), "EMAIL_TAKEN")
+//
+// The result is still an AppError with the same status and details, so it can be returned,
+// wrapped with ungerr.Wrap or produced by an ErrorMapper like any other AppError.
+func WithErrorCode(appError ungerr.AppError, code ErrorCode) ungerr.AppError {
+	return codedError{appError, code}
+}
+
+// ErrorCodeOf returns the code attached to the first error in err's chain that has one.
+func ErrorCodeOf(err error) (ErrorCode, bool) {
+	for cause := range errorChain(err) {
+		if coded, ok := cause.(interface{ ErrorCode() ErrorCode }); ok {
+			return coded.ErrorCode(), true
+		}
+	}
+	return "", false
+}
+
+// errorCode returns the code attached to appError, or the default code of its HTTP status.
+func errorCode(appError ungerr.AppError) ErrorCode {
+	if code, ok := ErrorCodeOf(appError); ok {
+		return code
+	}
+	return statusErrorCode(appError.HttpStatus())
+}
+
+func statusErrorCode(status int) ErrorCode {
+	if code, ok := statusErrorCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return ErrorCodeInternal
+	}
+	return ErrorCodeBadRequest
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	const errorCodeEmailTaken ErrorCode = "EMAIL_TAKEN"
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.GET("/coded", func(ctx *gin.Context) {
+		appError := WithErrorCode(ungerr.ConflictError("email is already registered"), errorCodeEmailTaken)
+		_ = ctx.Error(ungerr.Wrap(appError, "error registering user"))
+	})
+	r.GET("/plain", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("user not found"))
+	})
+	r.GET("/json", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(json.Unmarshal([]byte("{"), &struct{}{}), "error binding"))
+	})
+	r.GET("/raw", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("something broke"))
+	})
+
+	cases := map[string]struct {
+		status int
+		code   ErrorCode
+	}{
+		"/coded": {http.StatusConflict, errorCodeEmailTaken},
+		"/plain": {http.StatusNotFound, ErrorCodeNotFound},
+		"/json":  {http.StatusBadRequest, ErrorCodeInvalidJSON},
+		"/raw":   {http.StatusInternalServerError, ErrorCodeInternal},
+	}
+	for path, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		var body struct {
+			Errors []errorObject `json:"errors"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), path)
+		assert.Equal(t, tc.status, w.Code, path)
+		if assert.Len(t, body.Errors, 1, path) {
+			assert.Equal(t, http.StatusText(tc.status), body.Errors[0].Code, path)
+			assert.Equal(t, tc.code, body.Errors[0].ErrorCode, path)
+		}
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	appError := WithErrorCode(ungerr.ForbiddenError("not your order"), "ORDER_FORBIDDEN")
+
+	code, ok := ErrorCodeOf(ungerr.Wrap(appError, "error loading order"))
+	assert.True(t, ok)
+	assert.Equal(t, ErrorCode("ORDER_FORBIDDEN"), code)
+	assert.Equal(t, http.StatusForbidden, appError.HttpStatus())
+	assert.Equal(t, "not your order", appError.Details())
+
+	_, ok = ErrorCodeOf(ungerr.ForbiddenError("not your order"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2"
+)
+
+// maxTrackedFingerprints bounds the memory of an errorDeduplicator. Past it, the fingerprints
+// whose window started first are forgotten early.
+const maxTrackedFingerprints = 10000
+
+// WithErrorDeduplication makes the error middleware log each distinct server error at most
+// once per window. Errors are told apart by a fingerprint of their type, message and route,
+// logged as error.fingerprint. Repeats within the window are suppressed and then reported
+// once, e.g. "unhandled error (seen 132 more times in the last 1m0s)", either with the next
+// occurrence or when the fingerprint is forgotten, so a failing dependency can't flood the
+// logs with identical lines. Metrics and the ErrorReporter still see every occurrence.
+func WithErrorDeduplication(window time.Duration) ErrorOption {
+	return func(em *errorMiddleware) {
+		if window > 0 {
+			em.dedup = newErrorDeduplicator(window, maxTrackedFingerprints)
+		}
+	}
+}
+
+type fingerprintEntry struct {
+	fingerprint string
+	msg         string
+	windowStart time.Time
+	suppressed  int
+}
+
+// errorDeduplicator tracks fingerprints in the order their window started, so expired
+// ones are always at the front and forgetting them costs O(1) each.
+type errorDeduplicator struct {
+	window  time.Duration
+	limit   int
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*list.Element // of *fingerprintEntry
+	order   *list.List
+}
+
+func newErrorDeduplicator(window time.Duration, limit int) *errorDeduplicator {
+	return &errorDeduplicator{
+		window:  window,
+		limit:   limit,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// observe records an occurrence of fingerprint, reporting whether it should be logged and
+// how many occurrences were suppressed since it was last logged. It also returns the
+// forgotten fingerprints that had suppressed occurrences, which are yet to be reported.
+func (ed *errorDeduplicator) observe(fingerprint, msg string) (bool, int, []fingerprintEntry) {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+
+	now := ed.now()
+	suppressed := 0
+	if el, ok := ed.entries[fingerprint]; ok {
+		entry := el.Value.(*fingerprintEntry)
+		if now.Sub(entry.windowStart) < ed.window {
+			entry.suppressed++
+			return false, 0, nil
+		}
+		suppressed = entry.suppressed
+		ed.order.Remove(el)
+		delete(ed.entries, fingerprint)
+	}
+
+	forgotten := ed.evict(now)
+	ed.entries[fingerprint] = ed.order.PushBack(&fingerprintEntry{fingerprint: fingerprint, msg: msg, windowStart: now})
+	return true, suppressed, forgotten
+}
+
+// evict forgets expired fingerprints and, past the limit, the oldest ones.
+func (ed *errorDeduplicator) evict(now time.Time) []fingerprintEntry {
+	var forgotten []fingerprintEntry
+	for el := ed.order.Front(); el != nil; el = ed.order.Front() {
+		entry := el.Value.(*fingerprintEntry)
+		if now.Sub(entry.windowStart) < ed.window && ed.order.Len() < ed.limit {
+			break
+		}
+		ed.order.Remove(el)
+		delete(ed.entries, entry.fingerprint)
+		if entry.suppressed > 0 {
+			forgotten = append(forgotten, *entry)
+		}
+	}
+	return forgotten
+}
+
+// errorFingerprint identifies err by its type, message and the route it occurred on.
+func errorFingerprint(ctx *gin.Context, err error) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%T\n%s\n%s", err, err.Error(), ctx.FullPath()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// logError logs err at error level with msg, unless WithErrorDeduplication suppresses it.
+func (em *errorMiddleware) logError(ctx *gin.Context, logger ezutil.Logger, err error, msg string) {
+	if em.dedup == nil {
+		logger.Error(msg)
+		return
+	}
+
+	fingerprint := errorFingerprint(ctx, err)
+	log, suppressed, forgotten := em.dedup.observe(fingerprint, msg)
+	for _, entry := range forgotten {
+		em.logger.
+			WithField("error.fingerprint", entry.fingerprint).
+			WithField("error.suppressed", entry.suppressed).
+			Error(em.dedup.summary(entry.msg, entry.suppressed))
+	}
+	if !log {
+		return
+	}
+	logger = logger.WithField("error.fingerprint", fingerprint)
+	if suppressed > 0 {
+		logger = logger.WithField("error.suppressed", suppressed)
+		msg = em.dedup.summary(msg, suppressed)
+	}
+	logger.Error(msg)
+}
+
+func (ed *errorDeduplicator) summary(msg string, suppressed int) string {
+	return fmt.Sprintf("%s (seen %d more times in the last %s)", msg, suppressed, ed.window)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithErrorDeduplication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	now := time.Now()
+	withClock := func(em *errorMiddleware) {
+		em.dedup.now = func() time.Time { return now }
+	}
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(WithErrorDeduplication(time.Minute), withClock))
+	r.GET("/orders", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(errors.New("connection refused"), "error listing orders"))
+	})
+	r.GET("/users", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(errors.New("connection refused"), "error listing orders"))
+	})
+	serve := func(path string, times int) {
+		for range times {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+	}
+
+	serve("/orders", 5)
+	serve("/users", 2)
+	now = now.Add(30 * time.Second)
+	serve("/orders", 1)
+	now = now.Add(31 * time.Second)
+	serve("/orders", 2)
+	now = now.Add(2 * time.Minute)
+	serve("/orders", 1)
+
+	assert.Equal(t, []logEntry{
+		{"error", "unhandled error"},
+		{"error", "unhandled error"},
+		{"error", "unhandled error (seen 1 more times in the last 1m0s)"},
+		{"error", "unhandled error (seen 5 more times in the last 1m0s)"},
+		{"error", "unhandled error (seen 1 more times in the last 1m0s)"},
+	}, logger.logged(), "the expired /users repeats are reported when it is forgotten")
+}
+
+func TestErrorDeduplicatorEviction(t *testing.T) {
+	now := time.Now()
+	ed := newErrorDeduplicator(time.Minute, 3)
+	ed.now = func() time.Time { return now }
+
+	for _, fingerprint := range []string{"a", "b", "c", "a", "a"} {
+		ed.observe(fingerprint, "unhandled error")
+	}
+	log, _, forgotten := ed.observe("d", "unhandled error")
+	assert.True(t, log)
+	assert.Equal(t, []fingerprintEntry{{fingerprint: "a", msg: "unhandled error", windowStart: now, suppressed: 2}},
+		forgotten, "the limit evicts the oldest fingerprint even with suppressed repeats")
+	assert.Len(t, ed.entries, 3)
+
+	ed.observe("b", "unhandled error")
+	now = now.Add(time.Minute)
+	_, _, forgotten = ed.observe("e", "unhandled error")
+	assert.Len(t, forgotten, 1, "expired fingerprints are forgotten whatever their count")
+	assert.Equal(t, "b", forgotten[0].fingerprint)
+	assert.Len(t, ed.entries, 1)
+	assert.Equal(t, 1, ed.order.Len())
+}
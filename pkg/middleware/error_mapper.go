@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"sync"
+
+	"github.com/itsLeonB/ungerr"
+)
+
+// ErrorMapper maps an application's own error to an AppError, reporting whether it recognised err.
+// A typical mapper uses errors.Is or errors.As, e.g. mapping ErrDuplicateEmail to a 409 Conflict.
+type ErrorMapper func(err error) (ungerr.AppError, bool)
+
+// errorMappers is the registry of ErrorMappers shared by the provider's error middlewares.
+type errorMappers struct {
+	mu      sync.RWMutex
+	mappers []ErrorMapper
+}
+
+func (em *errorMappers) register(mapper ErrorMapper) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.mappers = append(em.mappers, mapper)
+}
+
+// mapError returns the AppError of the first mapper recognising err, in registration order.
+func (em *errorMappers) mapError(err error) ungerr.AppError {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	for _, mapper := range em.mappers {
+		if appError, ok := mapper(err); ok && appError != nil {
+			return appError
+		}
+	}
+	return nil
+}
+
+// RegisterMapper adds mapper to the error middlewares created by this provider, including
+// ones created before the call. Each error in the chain of an error that is not already an
+// AppError is passed to the registered mappers, before the built-in mapping of validation,
+// JSON and connection errors, instead of being answered with a masked 500 Internal Server Error.
+func (mp *MiddlewareProvider) RegisterMapper(mapper ErrorMapper) {
+	if mapper == nil {
+		mp.logger.Fatal("error mapper cannot be nil")
+	}
+	mp.mappers.register(mapper)
+}
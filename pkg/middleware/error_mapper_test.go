@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+var errDuplicateEmail = errors.New("duplicate email")
+
+func TestRegisterMapper(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	mp.RegisterMapper(func(err error) (ungerr.AppError, bool) {
+		if errors.Is(err, errDuplicateEmail) {
+			return ungerr.ConflictError("email is already registered"), true
+		}
+		return nil, false
+	})
+	mp.RegisterMapper(func(err error) (ungerr.AppError, bool) {
+		return ungerr.NotFoundError("unreachable"), errors.Is(err, errDuplicateEmail)
+	})
+
+	r.GET("/raw", func(ctx *gin.Context) {
+		_ = ctx.Error(fmt.Errorf("creating user: %w", errDuplicateEmail))
+	})
+	r.GET("/wrapped", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(errDuplicateEmail, "error inserting user"))
+	})
+	r.GET("/unmapped", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("something broke"))
+	})
+
+	cases := map[string]int{
+		"/raw":      http.StatusConflict,
+		"/wrapped":  http.StatusConflict,
+		"/unmapped": http.StatusInternalServerError,
+	}
+	for path, status := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		assert.Equal(t, status, w.Code, path)
+		if status == http.StatusConflict {
+			assert.Contains(t, w.Body.String(), "email is already registered", path)
+		}
+	}
+}
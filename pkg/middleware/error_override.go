@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorHandler handles an error attached to the context of a route in place of the error
+// middleware's default mapping. It writes the response itself and reports true, or reports
+// false to let the error middleware respond as usual.
+type ErrorHandler func(ctx *gin.Context, err error) bool
+
+// NewErrorHandlerOverride creates a middleware that makes the error middleware consult handler
+// before its default mapping for the routes it is registered on, e.g. so a webhook endpoint
+// always answers 200 with an error payload:
+//
+//	webhooks.Use(mp.NewErrorHandlerOverride(func(ctx *gin.Context, err error) bool {
+//		ctx.JSON(http.StatusOK, gin.H{"ok": false, "error": err.Error()})
+//		return true
+//	}))
+//
+// An override registered on a route replaces one registered on its group.
+// Recovered panics are always answered by the error middleware.
+func (mp *MiddlewareProvider) NewErrorHandlerOverride(handler ErrorHandler) gin.HandlerFunc {
+	if handler == nil {
+		mp.logger.Fatal("error handler cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		errorHandlerContextKey.Set(ctx, handler)
+		ctx.Next()
+	}
+}
+
+// handledByOverride offers err to the route's ErrorHandler, reporting whether it responded.
+func (em *errorMiddleware) handledByOverride(ctx *gin.Context, err error, span trace.Span) bool {
+	handler, ok := errorHandlerContextKey.Get(ctx)
+	if !ok || !handler(ctx, err) {
+		return false
+	}
+
+	ctx.Abort()
+	span.RecordError(err)
+	em.requestLogger(ctx).
+		WithError(err).
+		WithField("handler", ctx.HandlerName()).
+		Warn("error handled by route error handler")
+	em.metrics.IncCounter(metricErrorOutcomes, map[string]string{
+		"outcome": "route_override",
+		"status":  strconv.Itoa(ctx.Writer.Status()),
+	})
+	return true
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorHandlerOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+
+	webhooks := r.Group("/webhooks", mp.NewErrorHandlerOverride(func(ctx *gin.Context, err error) bool {
+		ctx.JSON(http.StatusOK, gin.H{"ok": false})
+		return true
+	}))
+	webhooks.POST("/payments", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(errors.New("signature mismatch"), "error verifying webhook"))
+	})
+	webhooks.POST("/refunds", mp.NewErrorHandlerOverride(func(ctx *gin.Context, err error) bool {
+		ctx.String(http.StatusAccepted, "retry later")
+		return true
+	}), func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("queue full"))
+	})
+	webhooks.POST("/declined", mp.NewErrorHandlerOverride(func(ctx *gin.Context, err error) bool {
+		return false
+	}), func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("unknown event"))
+	})
+	r.GET("/api", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("not found"))
+	})
+
+	cases := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"POST", "/webhooks/payments", http.StatusOK, `{"ok":false}`},
+		{"POST", "/webhooks/refunds", http.StatusAccepted, "retry later"},
+		{"POST", "/webhooks/declined", http.StatusNotFound, "unknown event"},
+		{"GET", "/api", http.StatusNotFound, "not found"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+		assert.Equal(t, tc.status, w.Code, tc.path)
+		assert.Contains(t, w.Body.String(), tc.body, tc.path)
+	}
+}
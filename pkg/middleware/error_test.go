@@ -1,13 +1,20 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/response"
 	"github.com/itsLeonB/ungerr"
 	"github.com/stretchr/testify/assert"
 )
@@ -74,3 +81,353 @@ func TestNewErrorMiddleware(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Internal Server Error")
 	})
 }
+
+func TestErrorMiddlewareWrappedErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	syntaxErr := json.Unmarshal([]byte("{"), &struct{}{})
+
+	cases := map[string]struct {
+		err    error
+		status int
+	}{
+		"json error behind fmt and ungerr wraps": {
+			err:    ungerr.Wrap(fmt.Errorf("decoding body: %w", ungerr.Wrap(syntaxErr, "error binding")), "error creating order"),
+			status: http.StatusBadRequest,
+		},
+		"app error behind fmt wrap": {
+			err:    fmt.Errorf("loading order: %w", ungerr.NotFoundError("order not found")),
+			status: http.StatusNotFound,
+		},
+		"app error behind ungerr wraps": {
+			err:    ungerr.Wrap(ungerr.Wrap(ungerr.ForbiddenError("not your order"), "error loading order"), "error in handler"),
+			status: http.StatusForbidden,
+		},
+		"joined EOF": {
+			err:    errors.Join(errors.New("reading body"), fmt.Errorf("read: %w", io.EOF)),
+			status: http.StatusBadRequest,
+		},
+		"connection reset": {
+			err:    ungerr.Wrap(fmt.Errorf("write: %w", syscall.ECONNRESET), "error streaming"),
+			status: http.StatusBadRequest,
+		},
+		"unidentifiable": {
+			err:    ungerr.Wrap(fmt.Errorf("query: %w", errors.New("deadlock detected")), "error saving"),
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(mp.NewErrorMiddleware())
+			r.GET("/", func(ctx *gin.Context) {
+				_ = ctx.Error(tc.err)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestErrorMiddlewareErrorMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	newRouter := func(mode ErrorMode) *gin.Engine {
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware(WithErrorMode(mode)))
+		r.GET("/db", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.Wrap(errors.New("connection refused"), "error querying orders"))
+		})
+		r.GET("/panic", func(ctx *gin.Context) {
+			panic("oops")
+		})
+		return r
+	}
+
+	type body struct {
+		Errors []struct {
+			Detail any `json:"detail"`
+			Debug  *struct {
+				Error      string `json:"error"`
+				Type       string `json:"type"`
+				StackTrace string `json:"stackTrace"`
+			} `json:"debug"`
+		} `json:"errors"`
+	}
+	serve := func(r *gin.Engine, path string) body {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var resp body
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Errors, 1)
+		return resp
+	}
+
+	t.Run("production masks errors", func(t *testing.T) {
+		r := newRouter(ErrorModeProduction)
+		for _, path := range []string{"/db", "/panic"} {
+			resp := serve(r, path)
+			assert.Nil(t, resp.Errors[0].Debug, path)
+		}
+	})
+
+	t.Run("development reveals errors", func(t *testing.T) {
+		r := newRouter(ErrorModeDevelopment)
+
+		debug := serve(r, "/db").Errors[0].Debug
+		if assert.NotNil(t, debug) {
+			assert.Contains(t, debug.Error, "error querying orders")
+			assert.Contains(t, debug.Error, "connection refused")
+			assert.Contains(t, debug.Error, "error_test.go")
+			assert.Equal(t, "*ungerr.UnknownError", debug.Type)
+			assert.Empty(t, debug.StackTrace)
+		}
+
+		debug = serve(r, "/panic").Errors[0].Debug
+		if assert.NotNil(t, debug) {
+			assert.Equal(t, "panic: oops", debug.Error)
+			assert.Contains(t, debug.StackTrace, "runtime/debug.Stack")
+		}
+	})
+}
+
+func TestErrorMiddlewareProblemDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	newRouter := func(mode ProblemDetailsMode) *gin.Engine {
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware(WithProblemDetails(mode)))
+		r.Use(mp.NewRequestIDMiddleware())
+		r.GET("/orders/:id", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.NotFoundError("order not found"))
+		})
+		r.GET("/invalid", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.ValidationError([]string{"name is required"}))
+		})
+		return r
+	}
+
+	t.Run("always", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/orders/7", nil)
+		req.Header.Set(headerRequestID, "req-1")
+		newRouter(ProblemDetailsAlways).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, response.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Not Found",
+			"status": 404,
+			"detail": "order not found",
+			"instance": "/orders/7",
+			"code": "NOT_FOUND",
+			"request_id": "req-1"
+		}`, w.Body.String())
+	})
+
+	t.Run("validation errors as extension", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/invalid", nil)
+		newRouter(ProblemDetailsAlways).ServeHTTP(w, req)
+
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, []any{"name is required"}, body["errors"])
+		assert.NotContains(t, body, "detail")
+	})
+
+	t.Run("negotiated", func(t *testing.T) {
+		r := newRouter(ProblemDetailsNegotiated)
+		cases := map[string]string{
+			"":                         "application/json; charset=utf-8",
+			"application/json":         "application/json; charset=utf-8",
+			"application/problem+json": response.ProblemContentType,
+			"application/json, application/problem+json;q=0.9": response.ProblemContentType,
+			"application/problem+json;q=0":                     "application/json; charset=utf-8",
+		}
+		for accept, contentType := range cases {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/orders/7", nil)
+			req.Header.Set("Accept", accept)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code, accept)
+			assert.Equal(t, contentType, w.Header().Get("Content-Type"), accept)
+		}
+	})
+}
+
+func BenchmarkErrorMiddleware(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	discardStdout(b)
+	mp := NewMiddlewareProvider(simple.NewLogger("bench", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.GET("/app", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("not found"))
+	})
+	r.GET("/wrapped", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(errors.New("db down"), "query failed"))
+	})
+	r.GET("/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/app", "/wrapped", "/panic"} {
+		b.Run(path[1:], func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			b.ReportAllocs()
+			for b.Loop() {
+				r.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
+
+func TestErrorMiddlewareContextErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	reporter := &recordingReporter{}
+	mp := NewMiddlewareProvider(logger, WithErrorReporter(reporter))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.GET("/slow", func(ctx *gin.Context) {
+		c, cancel := context.WithTimeout(ctx.Request.Context(), time.Nanosecond)
+		defer cancel()
+		<-c.Done()
+		_ = ctx.Error(ungerr.Wrap(fmt.Errorf("querying orders: %w", c.Err()), "error listing orders"))
+	})
+	r.GET("/gone", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(context.Canceled, "error listing orders"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), `"errorCode":"GATEWAY_TIMEOUT"`)
+	assert.Len(t, reporter.reports, 1)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/gone", nil))
+	assert.Equal(t, StatusClientClosedRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"Client Closed Request"`)
+	assert.Len(t, reporter.reports, 1, "canceled requests are not reported")
+}
+
+func TestErrorMiddlewareConciseClientErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(opts ...ErrorOption) []logEntry {
+		logger := newRecordingLogger()
+		mp := NewMiddlewareProvider(logger)
+
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware(opts...))
+		r.GET("/not-found", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.NotFoundError("order not found"))
+		})
+		r.GET("/bad-json", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.Wrap(json.Unmarshal([]byte("{"), &struct{}{}), "error binding"))
+		})
+		r.GET("/db", func(ctx *gin.Context) {
+			_ = ctx.Error(ungerr.Wrap(errors.New("connection refused"), "error querying orders"))
+		})
+
+		for _, path := range []string{"/not-found", "/bad-json", "/db"} {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+		return logger.logged()
+	}
+
+	assert.Equal(t, []logEntry{
+		{"warn", "application error"},
+		{"warn", "identified wrapped error"},
+		{"error", "unhandled error"},
+	}, serve())
+
+	assert.Equal(t, []logEntry{
+		{"info", "client error"},
+		{"info", "client error"},
+		{"error", "unhandled error"},
+	}, serve(WithConciseClientErrors(LogLevelInfo)))
+}
+
+func TestErrorMiddlewareNegotiatesFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.GET("/", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("order not found"))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<detail>order not found</detail>")
+}
+
+func TestErrorMiddlewareResponseAlreadyWritten(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "partial")
+		_ = ctx.Error(ungerr.Wrap(errors.New("disk read failed"), "error streaming file"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "partial", w.Body.String())
+	assert.Equal(t, []logEntry{
+		{"error", "unhandled error"},
+		{"warn", "response already written, could not send error response"},
+	}, logger.logged())
+}
+
+func TestErrorMiddlewareJSONAPIFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Group("/v2", response.UseFormat(response.FormatJSONAPI)).GET("/orders/:id", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("order not found"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v2/orders/7", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, response.JSONAPIContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"jsonapi": {"version": "1.1"},
+		"errors": [{"status": "404", "code": "NOT_FOUND", "title": "Not Found", "detail": "order not found"}]
+	}`, w.Body.String())
+}
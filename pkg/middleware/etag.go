@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewETagMiddleware creates a middleware that gives successful GET and HEAD responses a
+// strong ETag computed over the response body, and answers 304 Not Modified without
+// the body when the request's If-None-Match matches it.
+// An ETag set by the handler is kept as is. Responses are buffered until the handler
+// returns; a handler that flushes switches to streaming and gets no ETag.
+func (mp *MiddlewareProvider) NewETagMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		method := ctx.Request.Method
+		if method != http.MethodGet && method != http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		buf := getBuffer()
+		defer putBuffer(buf)
+		writer := &bufferedWriter{ResponseWriter: ctx.Writer, buf: buf}
+		ctx.Writer = writer
+		defer func() { ctx.Writer = writer.ResponseWriter }()
+
+		ctx.Next()
+
+		if writer.streaming {
+			return
+		}
+		if writer.Status() == http.StatusOK && writer.Written() {
+			header := writer.Header()
+			etag := header.Get("ETag")
+			if etag == "" {
+				etag = computeETag(buf.Bytes())
+				header.Set("ETag", etag)
+			}
+			if etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+				header.Del("Content-Length")
+				header.Del("Content-Type")
+				writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+				writer.ResponseWriter.WriteHeaderNow()
+				return
+			}
+		}
+		writer.flush()
+	}
+}
+
+// computeETag returns a strong entity tag for body.
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag,
+// using the weak comparison RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds back the status and body until flush is called,
+// so a middleware can inspect or replace the response after the handler ran.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buf       *bytes.Buffer
+	status    int
+	written   bool
+	streaming bool
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	w.written = true
+	return w.buf.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.WriteString(s)
+	}
+	w.written = true
+	return w.buf.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.streaming || w.status == 0 {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written || w.ResponseWriter.Written()
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.streaming || !w.written {
+		return w.ResponseWriter.Size()
+	}
+	return w.buf.Len()
+}
+
+// Flush sends what was buffered so far and passes later writes straight through.
+func (w *bufferedWriter) Flush() {
+	w.flush()
+	w.streaming = true
+	w.ResponseWriter.Flush()
+}
+
+// flush writes the buffered status and body to the underlying writer.
+func (w *bufferedWriter) flush() {
+	if w.streaming {
+		return
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	} else if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewETagMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Use(mp.NewETagMiddleware())
+	r.GET("/items", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"items": []int{1, 2, 3}})
+	})
+	r.GET("/custom", func(ctx *gin.Context) {
+		ctx.Header("ETag", `W/"v1"`)
+		ctx.String(http.StatusOK, "custom")
+	})
+	r.GET("/missing", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("no item"))
+	})
+	r.GET("/stream", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "part1")
+		ctx.Writer.Flush()
+		ctx.String(http.StatusOK, "part2")
+	})
+	r.POST("/items", func(ctx *gin.Context) {
+		ctx.String(http.StatusCreated, "created")
+	})
+
+	serve := func(method, path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("sets etag", func(t *testing.T) {
+		w := serve("GET", "/items", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"items":[1,2,3]}`, w.Body.String())
+		assert.Equal(t, computeETag(w.Body.Bytes()), w.Header().Get("ETag"))
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		etag := serve("GET", "/items", "").Header().Get("ETag")
+
+		w := serve("GET", "/items", `"other", `+etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusOK, serve("GET", "/items", `"other"`).Code)
+	})
+
+	t.Run("keeps handler etag", func(t *testing.T) {
+		w := serve("GET", "/custom", "")
+		assert.Equal(t, `W/"v1"`, w.Header().Get("ETag"))
+		assert.Equal(t, "custom", w.Body.String())
+		assert.Equal(t, http.StatusNotModified, serve("GET", "/custom", `"v1"`).Code)
+	})
+
+	t.Run("errors get no etag", func(t *testing.T) {
+		w := serve("GET", "/missing", "*")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), "no item")
+	})
+
+	t.Run("streaming responses pass through", func(t *testing.T) {
+		w := serve("GET", "/stream", "")
+		assert.Equal(t, "part1part2", w.Body.String())
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("other methods are untouched", func(t *testing.T) {
+		w := serve("POST", "/items", "*")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "created", w.Body.String())
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.False(t, etagMatches("", `"a"`))
+	assert.True(t, etagMatches("*", `"a"`))
+	assert.True(t, etagMatches(`"a"`, `"a"`))
+	assert.True(t, etagMatches(`W/"a"`, `"a"`))
+	assert.True(t, etagMatches(`"b" , "a"`, `W/"a"`))
+	assert.False(t, etagMatches(`"b"`, `"a"`))
+}
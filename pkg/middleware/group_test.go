@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestGroupBuilder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	checkToken := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+		switch token {
+		case "admin":
+			return true, map[string]any{"sub": "1", "roles": []string{"admin"}}, nil
+		case "guest":
+			return true, map[string]any{"sub": "2", "roles": []string{"guest"}}, nil
+		}
+		return false, nil, nil
+	}
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	var order []string
+	// The presets are added in the reverse of their running order.
+	mp.Group(r, "/api/v1").
+		Use(func(ctx *gin.Context) { order = append(order, "custom") }).
+		WithPermission("", "users:read", map[string][]string{"admin": {"users:*"}, "guest": {"orders:read"}}).
+		WithRateLimit(rate.Every(time.Hour), 4).
+		WithAuth("Bearer", checkToken).
+		Build().
+		GET("/users", func(ctx *gin.Context) {
+			principal, _ := CurrentPrincipal(ctx)
+			order = append(order, "handler:"+principal.Subject())
+			ctx.Status(http.StatusOK)
+		})
+
+	serve := func(token string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("admin"))
+	assert.Equal(t, []string{"custom", "handler:1"}, order)
+	assert.Equal(t, http.StatusForbidden, serve("guest"))
+	assert.Equal(t, http.StatusUnauthorized, serve(""))
+	assert.Equal(t, http.StatusUnauthorized, serve("unknown"))
+	// The rate limit runs first, so it also counts rejected requests.
+	assert.Equal(t, http.StatusTooManyRequests, serve("admin"))
+	assert.Equal(t, []string{"custom", "handler:1"}, order)
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/itsLeonB/ungerr"
+)
+
+// IntrospectionConfig configures a TokenIntrospector.
+type IntrospectionConfig struct {
+	// URL of the RFC 7662 introspection endpoint.
+	URL string
+	// ClientID and ClientSecret authenticate this service to the endpoint with HTTP Basic auth.
+	ClientID     string
+	ClientSecret string
+	// TokenTypeHint is sent as token_type_hint if set, e.g. "access_token".
+	TokenTypeHint string
+	// Cache stores introspection results, keyed by a hash of the token.
+	// Defaults to an in-memory store; use a shared store to cache across instances.
+	Cache store.Store
+	// ActiveTTL is how long active results are cached, capped at the token's "exp". Defaults to 5 minutes.
+	ActiveTTL time.Duration
+	// InactiveTTL is how long inactive results are cached. Defaults to 1 minute.
+	InactiveTTL time.Duration
+	// HTTPClient calls the endpoint. Defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// TokenIntrospector validates opaque OAuth2 tokens against an RFC 7662 introspection endpoint.
+// Its Check method is a tokenCheckFunc for NewAuthMiddleware.
+type TokenIntrospector struct {
+	cfg IntrospectionConfig
+	now func() time.Time
+}
+
+// NewTokenIntrospector creates a TokenIntrospector from cfg.
+func NewTokenIntrospector(cfg IntrospectionConfig) *TokenIntrospector {
+	if cfg.URL == "" {
+		log.Fatal("introspection URL cannot be empty")
+	}
+	if cfg.Cache == nil {
+		cfg.Cache = store.NewMemoryStore()
+	}
+	if cfg.ActiveTTL <= 0 {
+		cfg.ActiveTTL = 5 * time.Minute
+	}
+	if cfg.InactiveTTL <= 0 {
+		cfg.InactiveTTL = time.Minute
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &TokenIntrospector{cfg, time.Now}
+}
+
+// NewIntrospectionAuthMiddleware creates a Bearer auth middleware that validates tokens with ti.
+// The introspection response (sub, scope, client_id...) is stored in the Gin context
+// and used to build the Principal.
+func (mp *MiddlewareProvider) NewIntrospectionAuthMiddleware(ti *TokenIntrospector, opts ...AuthOption) gin.HandlerFunc {
+	if ti == nil {
+		mp.logger.Fatal("token introspector cannot be nil")
+	}
+	return mp.NewAuthMiddleware("Bearer", ti.Check, opts...)
+}
+
+// Check introspects token, using a cached result when available.
+// Inactive tokens are rejected with an UnauthorizedError.
+func (ti *TokenIntrospector) Check(ctx *gin.Context, token string) (bool, map[string]any, error) {
+	claims, err := ti.Introspect(ctx, token)
+	if err != nil {
+		return false, nil, err
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return false, nil, ungerr.UnauthorizedError("token is not active")
+	}
+	return true, claims, nil
+}
+
+// Introspect returns the introspection response for token. Numeric values are json.Number.
+func (ti *TokenIntrospector) Introspect(ctx context.Context, token string) (map[string]any, error) {
+	sum := sha256.Sum256([]byte(token))
+	cacheKey := "introspection:" + hex.EncodeToString(sum[:])
+
+	if cached, ok, err := ti.cfg.Cache.Get(ctx, cacheKey); err != nil {
+		return nil, ungerr.Wrap(err, "error reading cached introspection result")
+	} else if ok {
+		return decodeIntrospection(cached)
+	}
+
+	body, err := ti.request(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := decodeIntrospection(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ti.cfg.Cache.Set(ctx, cacheKey, body, ti.cacheTTL(claims)); err != nil {
+		return nil, ungerr.Wrap(err, "error caching introspection result")
+	}
+
+	return claims, nil
+}
+
+// maxIntrospectionBytes bounds the size of an introspection response.
+const maxIntrospectionBytes = 1 << 20
+
+func (ti *TokenIntrospector) request(ctx context.Context, token string) ([]byte, error) {
+	form := url.Values{"token": {token}}
+	if ti.cfg.TokenTypeHint != "" {
+		form.Set("token_type_hint", ti.cfg.TokenTypeHint)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ti.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, ungerr.Wrap(err, "error creating introspection request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if ti.cfg.ClientID != "" {
+		// RFC 6749 section 2.3.1: client credentials are form-encoded before Basic auth.
+		req.SetBasicAuth(url.QueryEscape(ti.cfg.ClientID), url.QueryEscape(ti.cfg.ClientSecret))
+	}
+
+	resp, err := ti.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, ungerr.Wrap(err, "error calling introspection endpoint")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ungerr.Unknownf("unexpected introspection response status: %d", resp.StatusCode)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(io.LimitReader(resp.Body, maxIntrospectionBytes+1)); err != nil {
+		return nil, ungerr.Wrap(err, "error reading introspection response")
+	}
+	if buf.Len() > maxIntrospectionBytes {
+		return nil, ungerr.Unknownf("introspection response exceeds %d bytes", maxIntrospectionBytes)
+	}
+	return buf.Bytes(), nil
+}
+
+func (ti *TokenIntrospector) cacheTTL(claims map[string]any) time.Duration {
+	if active, _ := claims["active"].(bool); !active {
+		return ti.cfg.InactiveTTL
+	}
+	ttl := ti.cfg.ActiveTTL
+	if exp, ok, err := numericDateClaim(claims, "exp"); ok && err == nil {
+		ttl = min(ttl, exp.Sub(ti.now()))
+	}
+	// Keep the ttl positive for tokens already past their expiry; the store treats 0 as no expiry.
+	return max(ttl, time.Millisecond)
+}
+
+func decodeIntrospection(data []byte) (map[string]any, error) {
+	var claims map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, ungerr.Wrap(err, "error decoding introspection response")
+	}
+	return claims, nil
+}
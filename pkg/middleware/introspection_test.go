@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIntrospectionAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != "api" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.PostFormValue("token") {
+		case "active-token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"active": true,
+				"sub":    "123",
+				"scope":  "read write",
+				"exp":    time.Now().Add(time.Hour).Unix(),
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+		}
+	}))
+	defer srv.Close()
+
+	ti := NewTokenIntrospector(IntrospectionConfig{URL: srv.URL, ClientID: "api", ClientSecret: "s3cret"})
+	mw := mp.NewIntrospectionAuthMiddleware(ti)
+
+	serve := func(token string) *gin.Context {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+		mw(c)
+		return c
+	}
+
+	t.Run("active token", func(t *testing.T) {
+		c := serve("active-token")
+
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "123", c.GetString("sub"))
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.True(t, principal.HasScope("write"))
+	})
+
+	t.Run("caches results", func(t *testing.T) {
+		before := hits.Load()
+
+		assert.False(t, serve("active-token").IsAborted())
+		assert.True(t, serve("revoked-token").IsAborted())
+		assert.True(t, serve("revoked-token").IsAborted())
+
+		assert.Equal(t, before+1, hits.Load())
+	})
+
+	t.Run("bad client credentials", func(t *testing.T) {
+		ti := NewTokenIntrospector(IntrospectionConfig{URL: srv.URL, ClientID: "api", ClientSecret: "wrong"})
+
+		_, _, err := ti.Check(&gin.Context{}, "active-token")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTokenIntrospectorResponseLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"active": true,
+			"sub":    strings.Repeat("x", maxIntrospectionBytes),
+		})
+	}))
+	defer srv.Close()
+
+	ti := NewTokenIntrospector(IntrospectionConfig{URL: srv.URL})
+
+	_, err := ti.Introspect(context.Background(), "active-token")
+
+	assert.ErrorContains(t, err, "introspection response exceeds")
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// NewIPAllowlistMiddleware creates a middleware that only lets through clients whose IP
+// matches one of ips (single addresses or CIDR ranges), aborting with a ForbiddenError otherwise.
+// Make sure the engine's trusted proxies are configured so ClientIP cannot be spoofed.
+func (mp *MiddlewareProvider) NewIPAllowlistMiddleware(ips ...string) gin.HandlerFunc {
+	if len(ips) == 0 {
+		mp.logger.Fatal("ip allowlist cannot be empty")
+	}
+	allow := AllowIPs(ips...)
+
+	return func(ctx *gin.Context) {
+		if !allow(ctx) {
+			_ = ctx.Error(ungerr.ForbiddenError("access denied"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
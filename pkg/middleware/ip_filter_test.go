@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIPAllowlistMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+	mw := mp.NewIPAllowlistMiddleware("10.0.0.0/8")
+
+	t.Run("allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "10.0.0.5:1234"
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "192.168.0.5:1234"
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/itsLeonB/ungerr"
+)
+
+// JWKSConfig configures a JWKS key source.
+type JWKSConfig struct {
+	// URL of the JSON Web Key Set, e.g. https://tenant.auth0.com/.well-known/jwks.json.
+	URL string
+	// TTL is how long fetched keys are cached before they are refreshed. Defaults to 1 hour.
+	TTL time.Duration
+	// MinRefreshInterval throttles refreshes triggered by tokens with an unknown "kid",
+	// so forged tokens cannot hammer the JWKS endpoint. Defaults to 1 minute.
+	MinRefreshInterval time.Duration
+	// HTTPClient fetches the key set. Defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// JWKS fetches and caches the signing keys of an identity provider (Auth0, Keycloak, Cognito...).
+// Use its KeyFunc as JWTConfig.KeyFunc.
+type JWKS struct {
+	cfg JWKSConfig
+	now func() time.Time
+
+	refreshMu   sync.Mutex // serializes fetches and guards lastAttempt
+	lastAttempt time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// maxJWKSBytes bounds the size of a fetched key set.
+const maxJWKSBytes = 1 << 20
+
+// NewJWKS creates a JWKS key source. Keys are fetched lazily on first use.
+func NewJWKS(cfg JWKSConfig) *JWKS {
+	if cfg.URL == "" {
+		log.Fatal("JWKS URL cannot be empty")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if cfg.MinRefreshInterval <= 0 {
+		cfg.MinRefreshInterval = time.Minute
+	}
+	cfg.MinRefreshInterval = min(cfg.MinRefreshInterval, cfg.TTL)
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKS{cfg: cfg, now: time.Now}
+}
+
+// KeyFunc returns the key matching the "kid" of header, refreshing the key set when it
+// has expired or the kid is unknown. Tokens without a kid match a key set holding a single key.
+// If a refresh fails, the previously fetched keys keep being used.
+func (j *JWKS) KeyFunc(ctx context.Context, header JWTHeader) (any, error) {
+	key, found, fresh := j.lookup(header.Kid)
+	if found && fresh {
+		return key, nil
+	}
+
+	if err := j.refresh(ctx); err != nil {
+		if found {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	if key, found, _ = j.lookup(header.Kid); !found {
+		return nil, ungerr.UnauthorizedError("unknown token signing key")
+	}
+	return key, nil
+}
+
+func (j *JWKS) lookup(kid string) (key any, found, fresh bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	fresh = !j.fetchedAt.IsZero() && j.now().Before(j.fetchedAt.Add(j.cfg.TTL))
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true, fresh
+		}
+	}
+	key, found = j.keys[kid]
+	return key, found, fresh
+}
+
+// refresh fetches the key set unless a fetch was attempted less than MinRefreshInterval ago,
+// which also keeps an unavailable endpoint from being retried on every request.
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	if !j.lastAttempt.IsZero() && j.now().Sub(j.lastAttempt) < j.cfg.MinRefreshInterval {
+		return nil
+	}
+	j.lastAttempt = j.now()
+
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = j.now()
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.cfg.URL, nil)
+	if err != nil {
+		return nil, ungerr.Wrap(err, "error creating JWKS request")
+	}
+
+	resp, err := j.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, ungerr.Wrap(err, "error fetching JWKS")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ungerr.Unknownf("unexpected JWKS response status: %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBytes)).Decode(&set); err != nil {
+		return nil, ungerr.Wrap(err, "error decoding JWKS")
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip keys we cannot use rather than failing the whole set.
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+
+	case "oct":
+		// A published symmetric key is a shared HMAC secret: anyone who can read the
+		// key set could sign tokens with it.
+		return nil, fmt.Errorf("symmetric keys are not accepted from a JWKS")
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
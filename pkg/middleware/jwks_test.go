@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	b64 := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	keys := []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+	}
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	jwks := NewJWKS(JWKSConfig{URL: srv.URL, TTL: time.Hour, MinRefreshInterval: time.Minute})
+	jwks.now = func() time.Time { return now }
+	verifier := NewJWTVerifier(JWTConfig{KeyFunc: jwks.KeyFunc})
+	claims := map[string]any{"sub": "123"}
+
+	t.Run("fetches and caches keys", func(t *testing.T) {
+		token := signTestJWT(t, AlgRS256, rsaKey, map[string]any{"kid": "rsa-1"}, claims)
+
+		for range 3 {
+			_, err := verifier.Verify(context.Background(), token)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("refreshes on unknown kid after min interval", func(t *testing.T) {
+		keys = append(keys, map[string]string{
+			"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y),
+		})
+		token := signTestJWT(t, AlgES256, ecKey, map[string]any{"kid": "ec-1"}, claims)
+
+		_, err := verifier.Verify(context.Background(), token)
+		assert.Error(t, err, "refresh is throttled")
+		assert.Equal(t, int32(1), hits.Load())
+
+		now = now.Add(2 * time.Minute)
+		_, err = verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), hits.Load())
+	})
+
+	t.Run("refreshes after ttl", func(t *testing.T) {
+		token := signTestJWT(t, AlgRS256, rsaKey, map[string]any{"kid": "rsa-1"}, claims)
+
+		now = now.Add(2 * time.Hour)
+		_, err := verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), hits.Load())
+	})
+
+	t.Run("keeps stale keys when refresh fails", func(t *testing.T) {
+		token := signTestJWT(t, AlgRS256, rsaKey, map[string]any{"kid": "rsa-1"}, claims)
+		srv.Close()
+
+		now = now.Add(2 * time.Hour)
+		_, err := verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+	})
+}
+
+func TestJWKSRejectsSymmetricKeys(t *testing.T) {
+	secret := []byte("published-secret")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "oct", "kid": "hmac-1", "k": base64.RawURLEncoding.EncodeToString(secret)},
+		}})
+	}))
+	defer srv.Close()
+
+	jwks := NewJWKS(JWKSConfig{URL: srv.URL})
+	verifier := NewJWTVerifier(JWTConfig{KeyFunc: jwks.KeyFunc})
+	token := signTestJWT(t, AlgHS256, secret, map[string]any{"kid": "hmac-1"}, map[string]any{"sub": "123"})
+
+	_, err := verifier.Verify(context.Background(), token)
+	assert.Error(t, err, "token signed with the published secret is refused")
+
+	key, err := jwks.KeyFunc(context.Background(), JWTHeader{Kid: "hmac-1"})
+	assert.Error(t, err)
+	assert.Nil(t, key)
+}
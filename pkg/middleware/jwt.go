@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// Supported JWT signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// JWTHeader is the decoded JOSE header of a token, passed to JWTConfig.KeyFunc.
+type JWTHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// JWTConfig configures a JWTVerifier.
+type JWTConfig struct {
+	// Key verifies every token: a []byte secret for HS256, *rsa.PublicKey for RS256
+	// or *ecdsa.PublicKey (P-256) for ES256. Ignored if KeyFunc is set.
+	Key any
+	// KeyFunc looks up the verification key of a token, e.g. by its "kid" header.
+	KeyFunc func(ctx context.Context, header JWTHeader) (any, error)
+	// Algorithms lists the accepted "alg" values. Defaults to HS256, RS256 and ES256;
+	// a token is only accepted if its key also has the type required by its algorithm.
+	Algorithms []string
+	// Issuer, if set, must equal the "iss" claim.
+	Issuer string
+	// Audience, if set, must be contained in the "aud" claim.
+	Audience string
+	// Leeway is the clock skew tolerated when checking "exp" and "nbf".
+	Leeway time.Duration
+	// RequireExpiration rejects tokens without an "exp" claim, which would otherwise never expire.
+	RequireExpiration bool
+}
+
+// JWTVerifier verifies the signature and registered claims of compact-serialized JWTs.
+type JWTVerifier struct {
+	cfg JWTConfig
+	now func() time.Time
+}
+
+// NewJWTVerifier creates a JWTVerifier from cfg. Either Key or KeyFunc must be set.
+func NewJWTVerifier(cfg JWTConfig) *JWTVerifier {
+	if cfg.Key == nil && cfg.KeyFunc == nil {
+		log.Fatal("either Key or KeyFunc must be set")
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = []string{AlgHS256, AlgRS256, AlgES256}
+	}
+	return &JWTVerifier{cfg, time.Now}
+}
+
+// NewJWTAuthMiddleware creates a Bearer auth middleware that verifies JWTs with the given config.
+// The token claims are stored in the Gin context and used to build the Principal,
+// exactly as if they were returned by the tokenCheckFunc of NewAuthMiddleware.
+// Invalid tokens are rejected with an UnauthorizedError.
+func (mp *MiddlewareProvider) NewJWTAuthMiddleware(cfg JWTConfig, opts ...AuthOption) gin.HandlerFunc {
+	verifier := NewJWTVerifier(cfg)
+	return mp.NewAuthMiddleware("Bearer", func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+		claims, err := verifier.Verify(ctx, token)
+		if err != nil {
+			return false, nil, err
+		}
+		return true, claims, nil
+	}, opts...)
+}
+
+// Verify checks the signature, "exp", "nbf", "iss" and "aud" of token and returns its claims.
+// Numeric claims are returned as json.Number.
+// It returns an UnauthorizedError if the token is not valid, or the KeyFunc error if the key lookup fails.
+func (jv *JWTVerifier) Verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ungerr.UnauthorizedError("malformed token")
+	}
+
+	var header JWTHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, ungerr.UnauthorizedError("malformed token header")
+	}
+	if !slices.Contains(jv.cfg.Algorithms, header.Alg) {
+		return nil, ungerr.UnauthorizedError("unsupported token algorithm")
+	}
+
+	key := jv.cfg.Key
+	if jv.cfg.KeyFunc != nil {
+		var err error
+		if key, err = jv.cfg.KeyFunc(ctx, header); err != nil {
+			return nil, err
+		}
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ungerr.UnauthorizedError("malformed token signature")
+	}
+	if !verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature) {
+		return nil, ungerr.UnauthorizedError("invalid token signature")
+	}
+
+	var claims map[string]any
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, ungerr.UnauthorizedError("malformed token claims")
+	}
+	if err := jv.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (jv *JWTVerifier) validateClaims(claims map[string]any) error {
+	now := jv.now()
+
+	exp, ok, err := numericDateClaim(claims, "exp")
+	if err != nil {
+		return ungerr.UnauthorizedError("malformed token expiration")
+	}
+	if !ok && jv.cfg.RequireExpiration {
+		return ungerr.UnauthorizedError("token has no expiration")
+	}
+	if ok && !now.Before(exp.Add(jv.cfg.Leeway)) {
+		return ungerr.UnauthorizedError("token has expired")
+	}
+	nbf, ok, err := numericDateClaim(claims, "nbf")
+	if err != nil {
+		return ungerr.UnauthorizedError("malformed token not before")
+	}
+	if ok && now.Add(jv.cfg.Leeway).Before(nbf) {
+		return ungerr.UnauthorizedError("token is not valid yet")
+	}
+	if jv.cfg.Issuer != "" && claims["iss"] != jv.cfg.Issuer {
+		return ungerr.UnauthorizedError("invalid token issuer")
+	}
+	if jv.cfg.Audience != "" && !slices.Contains(stringSliceClaim(claims, "aud"), jv.cfg.Audience) {
+		return ungerr.UnauthorizedError("invalid token audience")
+	}
+
+	return nil
+}
+
+func decodeJWTSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// maxNumericDate is 9999-12-31T23:59:59Z, the latest NumericDate claim accepted.
+const maxNumericDate = 253402300799
+
+// numericDateClaim returns the NumericDate claim key, seconds since the Unix epoch. The
+// boolean is false if the claim is absent; the error is set if it is present but not a
+// number between 0 and maxNumericDate.
+func numericDateClaim(claims map[string]any, key string) (time.Time, bool, error) {
+	val, ok := claims[key]
+	if !ok || val == nil {
+		return time.Time{}, false, nil
+	}
+	num, ok := val.(json.Number)
+	if !ok {
+		return time.Time{}, true, fmt.Errorf("%s is not a number", key)
+	}
+	seconds, err := num.Float64()
+	if err != nil || seconds < 0 || seconds > maxNumericDate {
+		return time.Time{}, true, fmt.Errorf("%s is out of range", key)
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))), true, nil
+}
+
+// verifyJWTSignature checks signature over signingInput. The key type must match alg,
+// which prevents e.g. an RSA public key from being used as an HMAC secret.
+func verifyJWTSignature(alg string, key any, signingInput string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case AlgHS256:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(signature, mac.Sum(nil))
+
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+
+	case AlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+
+	default:
+		return false
+	}
+}
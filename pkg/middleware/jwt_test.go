@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func signTestJWT(t *testing.T, alg string, key any, header map[string]any, claims map[string]any) string {
+	t.Helper()
+
+	if header == nil {
+		header = map[string]any{}
+	}
+	header["alg"] = alg
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		assert.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"sub": "123",
+			"iss": "https://issuer.example.com",
+			"aud": []string{"api", "admin"},
+			"exp": now.Add(time.Hour).Unix(),
+			"nbf": now.Add(-time.Minute).Unix(),
+		}
+	}
+	cfg := JWTConfig{Issuer: "https://issuer.example.com", Audience: "api"}
+
+	t.Run("valid signatures", func(t *testing.T) {
+		cases := []struct {
+			alg     string
+			signKey any
+			key     any
+		}{
+			{AlgHS256, secret, secret},
+			{AlgRS256, rsaKey, &rsaKey.PublicKey},
+			{AlgES256, ecKey, &ecKey.PublicKey},
+		}
+		for _, tc := range cases {
+			t.Run(tc.alg, func(t *testing.T) {
+				cfg := cfg
+				cfg.Key = tc.key
+				token := signTestJWT(t, tc.alg, tc.signKey, nil, validClaims())
+
+				claims, err := NewJWTVerifier(cfg).Verify(context.Background(), token)
+
+				assert.NoError(t, err)
+				assert.Equal(t, "123", claims["sub"])
+			})
+		}
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		cfg := cfg
+		cfg.Key = &rsaKey.PublicKey
+		verifier := NewJWTVerifier(cfg)
+
+		expired := validClaims()
+		expired["exp"] = now.Add(-time.Minute).Unix()
+		notYetValid := validClaims()
+		notYetValid["nbf"] = now.Add(time.Hour).Unix()
+		wrongIssuer := validClaims()
+		wrongIssuer["iss"] = "https://evil.example.com"
+		wrongAudience := validClaims()
+		wrongAudience["aud"] = "other"
+		textExpiration := validClaims()
+		textExpiration["exp"] = "2020-01-01"
+		hugeExpiration := validClaims()
+		hugeExpiration["exp"] = 1e30
+		textNotBefore := validClaims()
+		textNotBefore["nbf"] = "soon"
+
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+
+		tokens := map[string]string{
+			"malformed":      "not-a-jwt",
+			"expired":        signTestJWT(t, AlgRS256, rsaKey, nil, expired),
+			"not yet valid":  signTestJWT(t, AlgRS256, rsaKey, nil, notYetValid),
+			"wrong issuer":   signTestJWT(t, AlgRS256, rsaKey, nil, wrongIssuer),
+			"wrong audience": signTestJWT(t, AlgRS256, rsaKey, nil, wrongAudience),
+			"text exp":       signTestJWT(t, AlgRS256, rsaKey, nil, textExpiration),
+			"huge exp":       signTestJWT(t, AlgRS256, rsaKey, nil, hugeExpiration),
+			"text nbf":       signTestJWT(t, AlgRS256, rsaKey, nil, textNotBefore),
+			"wrong key":      signTestJWT(t, AlgRS256, otherKey, nil, validClaims()),
+			"alg confusion":  signTestJWT(t, AlgHS256, []byte("public-key-bytes"), nil, validClaims()),
+			"alg none":       signTestJWT(t, "none", nil, nil, validClaims()),
+		}
+		for name, token := range tokens {
+			t.Run(name, func(t *testing.T) {
+				_, err := verifier.Verify(context.Background(), token)
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("leeway", func(t *testing.T) {
+		cfg := cfg
+		cfg.Key = secret
+		cfg.Leeway = time.Minute
+		claims := validClaims()
+		claims["exp"] = now.Add(-30 * time.Second).Unix()
+
+		_, err := NewJWTVerifier(cfg).Verify(context.Background(), signTestJWT(t, AlgHS256, secret, nil, claims))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("require expiration", func(t *testing.T) {
+		cfg := cfg
+		cfg.Key = secret
+		claims := validClaims()
+		delete(claims, "exp")
+		token := signTestJWT(t, AlgHS256, secret, nil, claims)
+
+		_, err := NewJWTVerifier(cfg).Verify(context.Background(), token)
+		assert.NoError(t, err)
+
+		cfg.RequireExpiration = true
+		_, err = NewJWTVerifier(cfg).Verify(context.Background(), token)
+		if assert.Error(t, err) {
+			assert.Equal(t, "token has no expiration", err.(ungerr.AppError).Details())
+		}
+	})
+
+	t.Run("key func", func(t *testing.T) {
+		verifier := NewJWTVerifier(JWTConfig{
+			KeyFunc: func(ctx context.Context, header JWTHeader) (any, error) {
+				if header.Kid == "ec-1" {
+					return &ecKey.PublicKey, nil
+				}
+				return nil, nil
+			},
+		})
+
+		_, err := verifier.Verify(context.Background(), signTestJWT(t, AlgES256, ecKey, map[string]any{"kid": "ec-1"}, validClaims()))
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), signTestJWT(t, AlgES256, ecKey, map[string]any{"kid": "ec-2"}, validClaims()))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewJWTAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+	secret := []byte("test-secret")
+
+	mw := mp.NewJWTAuthMiddleware(JWTConfig{Key: secret, Algorithms: []string{AlgHS256}})
+
+	t.Run("valid token", func(t *testing.T) {
+		token := signTestJWT(t, AlgHS256, secret, nil, map[string]any{
+			"sub":   "123",
+			"roles": []string{"admin"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		assert.Equal(t, "123", c.GetString("sub"))
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.Equal(t, "123", principal.Subject())
+		assert.Equal(t, []string{"admin"}, principal.Roles())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		token := signTestJWT(t, AlgHS256, []byte("other-secret"), nil, map[string]any{"sub": "123"})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/i18n"
+)
+
+// NewLocaleMiddleware creates a middleware that picks the request locale from the
+// Accept-Language header using bundle, and stores both in the Gin context.
+// Translate, the error middleware and validation messages then render text in that locale.
+// Register it right after the error middleware so every handler and error response sees the locale.
+func (mp *MiddlewareProvider) NewLocaleMiddleware(bundle *i18n.Bundle) gin.HandlerFunc {
+	if bundle == nil {
+		mp.logger.Fatal("bundle cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		bundleContextKey.Set(ctx, bundle)
+		localeContextKey.Set(ctx, bundle.MatchLocale(ctx.GetHeader("Accept-Language")))
+		ctx.Next()
+	}
+}
+
+// Locale returns the locale selected by NewLocaleMiddleware, or "" if it is not registered.
+func Locale(ctx *gin.Context) string {
+	return localeContextKey.Value(ctx)
+}
+
+// Translate renders the message for key in the request locale, formatted with args.
+// It returns key unchanged if NewLocaleMiddleware is not registered or the key is unknown,
+// so plain messages can be passed through safely.
+func Translate(ctx *gin.Context, key string, args ...any) string {
+	if msg, ok := translate(ctx, key, args...); ok {
+		return msg
+	}
+	return key
+}
+
+func translate(ctx *gin.Context, key string, args ...any) (string, bool) {
+	bundle, ok := bundleContextKey.Get(ctx)
+	if !ok {
+		return "", false
+	}
+	return bundle.Translate(Locale(ctx), key, args...)
+}
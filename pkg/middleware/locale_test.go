@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/i18n"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLocaleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	bundle := i18n.NewBundle("en")
+	bundle.AddMessages("en", map[string]string{"user.not_found": "user not found"})
+	bundle.AddMessages("id", map[string]string{"user.not_found": "pengguna tidak ditemukan"})
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(), mp.NewLocaleMiddleware(bundle))
+	r.GET("/greeting", func(c *gin.Context) {
+		c.String(http.StatusOK, Translate(c, "user.not_found"))
+	})
+	r.GET("/error", func(c *gin.Context) {
+		_ = c.Error(ungerr.NotFoundError("user.not_found"))
+	})
+	r.GET("/validation", func(c *gin.Context) {
+		type request struct {
+			Email string `validate:"required"`
+		}
+		_ = c.Error(ungerr.Wrap(validator.New().Struct(request{}), "invalid request"))
+	})
+
+	t.Run("translate in handler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/greeting", nil)
+		req.Header.Set("Accept-Language", "id-ID,id;q=0.9")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "pengguna tidak ditemukan", w.Body.String())
+	})
+
+	t.Run("translate error detail", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/error", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"detail":"user not found"`)
+	})
+
+	t.Run("translate validation error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/validation", nil))
+
+		var resp struct {
+			Errors []struct {
+				Detail any `json:"detail"`
+			} `json:"errors"`
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Errors, 1) {
+			assert.Equal(t, map[string]any{"Email": "Email is required"}, resp.Errors[0].Detail)
+		}
+	})
+
+	t.Run("without middleware", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		assert.Equal(t, "plain message", Translate(c, "plain message"))
+	})
+}
@@ -1,13 +1,29 @@
 package middleware
 
 import (
+	"bytes"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2"
 )
 
-func (mp *MiddlewareProvider) NewLoggingMiddleware() gin.HandlerFunc {
+// NewLoggingMiddleware creates an access log middleware that writes one line per request
+// and stores a request-scoped logger, tagged with the request ID, method and path, for GetLogger.
+// Requests are logged at Info level, failed (4xx and 5xx) ones at Error level unless changed with
+// WithStatusClassLogLevel, and requests slower than WithSlowRequestThreshold at least at Warn.
+func (mp *MiddlewareProvider) NewLoggingMiddleware(opts ...LoggingOption) gin.HandlerFunc {
+	cfg := loggingConfig{levels: defaultStatusClassLevels}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(ctx *gin.Context) {
 		if ctx.Request.Method == http.MethodOptions {
 			ctx.Next()
@@ -16,58 +32,422 @@ func (mp *MiddlewareProvider) NewLoggingMiddleware() gin.HandlerFunc {
 
 		start := time.Now()
 		path := ctx.Request.URL.Path
+		rawQuery := ctx.Request.URL.RawQuery
 		method := ctx.Request.Method
 
-		// Build full path with query string for logging
-		fullPath := path
-		if rawQuery := ctx.Request.URL.RawQuery; rawQuery != "" {
-			fullPath = path + "?" + rawQuery
+		// Give handlers a correlated logger unless NewRequestLoggerMiddleware already did
+		if _, exists := loggerContextKey.Get(ctx); !exists {
+			loggerContextKey.Set(ctx, mp.requestLogger(ctx, nil))
+		}
+		if cfg.skipPaths.match(ctx.Request) {
+			ctx.Next()
+			return
+		}
+
+		var reqBody []byte
+		var bodyWriter *bodyCaptureWriter
+		if cfg.body != nil {
+			// Capture one byte over the cap to tell whether the body was truncated
+			reqBody = captureRequestBody(ctx, int64(cfg.body.maxSize)+1)
+			bodyWriter = newBodyCaptureWriter(ctx.Writer, cfg.body.maxSize+1)
+			ctx.Writer = bodyWriter
 		}
 
 		// Process request
 		ctx.Next()
 
-		// Calculate duration
+		if cfg.skipFunc != nil && cfg.skipFunc(ctx) {
+			return
+		}
+
 		elapsed := time.Since(start)
 		statusCode := ctx.Writer.Status()
-		clientIP := ctx.ClientIP()
+		failed := statusCode >= 400
+		slow := !failed && cfg.slowThreshold > 0 && elapsed >= cfg.slowThreshold
+
+		if !failed && !slow && cfg.sampler != nil && !cfg.sampler.sample(ctx.FullPath()) {
+			return
+		}
+		if bodyWriter != nil {
+			cfg.body.log(mp.logger, ctx, reqBody, bodyWriter)
+		}
 
-		// Log based on status code (similar to gRPC error handling)
-		if statusCode >= 400 {
-			errorMsg := ""
-			if len(ctx.Errors) > 0 {
-				errorMsg = ctx.Errors.String()
+		if cfg.structured {
+			fields := map[string]any{
+				"http.method":      method,
+				"http.path":        path,
+				"http.status_code": statusCode,
+				"http.duration":    elapsed,
+				"http.client_ip":   ctx.ClientIP(),
 			}
+			if rawQuery != "" {
+				fields["http.query"] = rawQuery
+			}
+			if requestID := RequestID(ctx); requestID != "" {
+				fields["http.request_id"] = requestID
+			}
+			if failed && len(ctx.Errors) > 0 {
+				fields["error"] = strings.Join(ctx.Errors.Errors(), "; ")
+			}
+			if slow {
+				fields["http.slow_request"] = true
+				fields["http.slow_threshold"] = cfg.slowThreshold
+			}
+			logAccess(mp.logger.WithFields(fields), cfg.level(statusCode, slow), "http request")
+			return
+		}
+
+		// Build the line in a pooled buffer instead of formatting it with Errorf/Infof
+		buf := getBuffer()
+		defer putBuffer(buf)
+		writeAccessLog(buf, method, path, rawQuery, statusCode, elapsed, ctx.ClientIP(), RequestID(ctx))
 
-			if errorMsg != "" {
-				mp.logger.Errorf(
-					"[HTTP] method=%s path=%s status=%d duration=%s client_ip=%s error=%s",
-					method,
-					fullPath,
-					statusCode,
-					elapsed,
-					clientIP,
-					errorMsg,
-				)
-			} else {
-				mp.logger.Errorf(
-					"[HTTP] method=%s path=%s status=%d duration=%s client_ip=%s",
-					method,
-					fullPath,
-					statusCode,
-					elapsed,
-					clientIP,
-				)
+		if failed && len(ctx.Errors) > 0 {
+			buf.WriteString(" error=")
+			for i, err := range ctx.Errors {
+				if i > 0 {
+					buf.WriteString("; ")
+				}
+				buf.WriteString(err.Error())
 			}
-		} else {
-			mp.logger.Infof(
-				"[HTTP] method=%s path=%s status=%d duration=%s client_ip=%s",
-				method,
-				fullPath,
-				statusCode,
-				elapsed,
-				clientIP,
-			)
 		}
+		if slow {
+			buf.WriteString(" slow_request=true threshold=")
+			appendDuration(buf, cfg.slowThreshold)
+		}
+		logAccess(mp.logger, cfg.level(statusCode, slow), buf.String())
+	}
+}
+
+// LogLevel is a level the logging middleware writes access logs at.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// defaultStatusClassLevels is indexed by status class (status / 100).
+var defaultStatusClassLevels = [6]LogLevel{
+	LogLevelInfo, LogLevelInfo, LogLevelInfo, LogLevelInfo, LogLevelError, LogLevelError,
+}
+
+func logAccess(logger ezutil.Logger, level LogLevel, msg string) {
+	switch level {
+	case LogLevelDebug:
+		logger.Debug(msg)
+	case LogLevelInfo:
+		logger.Info(msg)
+	case LogLevelWarn:
+		logger.Warn(msg)
+	default:
+		logger.Error(msg)
+	}
+}
+
+// LoggingOption configures optional behaviour of NewLoggingMiddleware.
+type LoggingOption func(*loggingConfig)
+
+type loggingConfig struct {
+	slowThreshold time.Duration
+	skipPaths     pathPatterns
+	skipFunc      func(ctx *gin.Context) bool
+	structured    bool
+	body          *bodyLogging
+	sampler       *logSampler
+	levels        [6]LogLevel
+}
+
+// level picks the level of a request from its status class, raised to Warn if it was slow.
+func (cfg *loggingConfig) level(statusCode int, slow bool) LogLevel {
+	level := LogLevelError
+	if class := statusCode / 100; class >= 0 && class < len(cfg.levels) {
+		level = cfg.levels[class]
+	}
+	if slow {
+		level = max(level, LogLevelWarn)
+	}
+	return level
+}
+
+// WithStatusClassLogLevel sets the level of requests whose status is in the class of status,
+// e.g. WithStatusClassLogLevel(http.StatusNotFound, LogLevelWarn) logs every 4xx at Warn,
+// so client errors such as scanner 404s don't trigger error alerts.
+// Failed requests are still exempt from WithLogSampling and carry their errors.
+func WithStatusClassLogLevel(status int, level LogLevel) LoggingOption {
+	return func(cfg *loggingConfig) {
+		if class := status / 100; class >= 1 && class < len(cfg.levels) {
+			cfg.levels[class] = level
+		}
+	}
+}
+
+// WithSlowRequestThreshold logs successful requests taking at least threshold at Warn level,
+// marked with slow_request=true, so latency regressions stand out from regular traffic.
+func WithSlowRequestThreshold(threshold time.Duration) LoggingOption {
+	return func(cfg *loggingConfig) {
+		cfg.slowThreshold = threshold
+	}
+}
+
+func writeAccessLog(
+	buf *bytes.Buffer,
+	method, path, rawQuery string,
+	statusCode int,
+	elapsed time.Duration,
+	clientIP string,
+	requestID string,
+) {
+	buf.WriteString("[HTTP] method=")
+	buf.WriteString(method)
+	buf.WriteString(" path=")
+	buf.WriteString(path)
+	if rawQuery != "" {
+		buf.WriteByte('?')
+		buf.WriteString(rawQuery)
+	}
+	buf.WriteString(" status=")
+	buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(statusCode), 10))
+	buf.WriteString(" duration=")
+	appendDuration(buf, elapsed)
+	buf.WriteString(" client_ip=")
+	buf.WriteString(clientIP)
+	if requestID != "" {
+		buf.WriteString(" request_id=")
+		buf.WriteString(requestID)
+	}
+}
+
+// appendDuration writes d exactly as time.Duration.String does, without allocating a string.
+func appendDuration(buf *bytes.Buffer, d time.Duration) {
+	var scratch [32]byte
+	w := len(scratch)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		// Sub-second durations use a smaller unit, e.g. "1.2ms".
+		var prec int
+		w--
+		scratch[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			buf.WriteString("0s")
+			return
+		case u < uint64(time.Microsecond):
+			scratch[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			w-- // "µ" is two bytes
+			copy(scratch[w:], "µ")
+		default:
+			prec = 6
+			scratch[w] = 'm'
+		}
+		w, u = formatFrac(scratch[:w], u, prec)
+		w = formatInt(scratch[:w], u)
+	} else {
+		w--
+		scratch[w] = 's'
+		w, u = formatFrac(scratch[:w], u, 9)
+
+		w = formatInt(scratch[:w], u%60)
+		u /= 60
+		if u > 0 {
+			w--
+			scratch[w] = 'm'
+			w = formatInt(scratch[:w], u%60)
+			u /= 60
+			if u > 0 {
+				w--
+				scratch[w] = 'h'
+				w = formatInt(scratch[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		scratch[w] = '-'
+	}
+	buf.Write(scratch[w:])
+}
+
+// formatFrac writes the fraction of v/10^prec, omitting trailing zeros, into the end of buf.
+func formatFrac(buf []byte, v uint64, prec int) (int, uint64) {
+	w := len(buf)
+	print := false
+	for range prec {
+		digit := v % 10
+		print = print || digit != 0
+		if print {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if print {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// formatInt writes v into the end of buf and returns the index where the output begins.
+func formatInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+		return w
+	}
+	for v > 0 {
+		w--
+		buf[w] = byte(v%10) + '0'
+		v /= 10
+	}
+	return w
+}
+
+// WithStructuredLogs logs each request as a "http request" message with fields
+// (http.method, http.path, http.query, http.status_code, http.duration, http.client_ip,
+// http.request_id and error) instead of a formatted line, so log aggregators can index them.
+// Pair it with a structured logger such as logging.NewSlogLogger.
+func WithStructuredLogs() LoggingOption {
+	return func(cfg *loggingConfig) {
+		cfg.structured = true
+	}
+}
+
+// BodyLoggingConfig configures WithBodyLogging.
+type BodyLoggingConfig struct {
+	// MaxBodySize caps the bytes logged of each body. Defaults to 4 KB.
+	MaxBodySize int
+	// RedactFields are JSON and form fields whose values are masked, matched case-insensitively,
+	// in addition to common ones such as password, secret, token and api_key.
+	RedactFields []string
+	// RedactHeaders are headers whose values are masked, in addition to
+	// Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-API-Key.
+	RedactHeaders []string
+}
+
+// WithBodyLogging additionally logs the headers and bodies of every request and response
+// at Debug level, with sensitive values redacted. Bodies are capped at cfg.MaxBodySize and
+// binary content is summarized, but this is still expensive: enable it only while debugging.
+func WithBodyLogging(cfg BodyLoggingConfig) LoggingOption {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 4 << 10
+	}
+	body := &bodyLogging{
+		maxSize: cfg.MaxBodySize,
+		redactor: newRedactor(
+			append(slices.Clone(defaultRedactedFields), cfg.RedactFields...),
+			append(slices.Clone(defaultRedactedHeaders), cfg.RedactHeaders...),
+		),
+	}
+	return func(cfg *loggingConfig) {
+		cfg.body = body
+	}
+}
+
+type bodyLogging struct {
+	maxSize  int
+	redactor *redactor
+}
+
+func (bl *bodyLogging) log(logger ezutil.Logger, ctx *gin.Context, reqBody []byte, writer *bodyCaptureWriter) {
+	respBody := writer.body.Bytes()
+	fields := map[string]any{
+		"http.method":           ctx.Request.Method,
+		"http.path":             ctx.Request.URL.Path,
+		"http.status_code":      writer.Status(),
+		"http.request.headers":  bl.redactor.redactHeaders(ctx.Request.Header),
+		"http.request.body":     bl.redactBody(ctx.GetHeader("Content-Type"), reqBody),
+		"http.response.headers": bl.redactor.redactHeaders(writer.Header()),
+		"http.response.body":    bl.redactBody(writer.Header().Get("Content-Type"), respBody),
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		fields["http.request_id"] = requestID
+	}
+	logger.WithFields(fields).Debug("http exchange")
+}
+
+func (bl *bodyLogging) redactBody(contentType string, body []byte) string {
+	truncated := len(body) > bl.maxSize
+	if truncated {
+		body = body[:bl.maxSize]
+	}
+	return bl.redactor.redactBody(contentType, body, truncated)
+}
+
+// WithLogSampling logs only one in every n successful requests, to keep hot endpoints from
+// flooding the logs. Failed and slow requests are always logged.
+// Without routes, it applies to every route; otherwise only to the given route patterns
+// (as returned by gin.Context.FullPath, e.g. "/items/:id"), so it can be called several
+// times to sample routes at different rates. Each route is counted separately.
+func WithLogSampling(every int, routes ...string) LoggingOption {
+	return func(cfg *loggingConfig) {
+		if cfg.sampler == nil {
+			cfg.sampler = &logSampler{every: map[string]int{}}
+		}
+		if len(routes) == 0 {
+			cfg.sampler.defaultEvery = every
+		}
+		for _, route := range routes {
+			cfg.sampler.every[route] = every
+		}
+	}
+}
+
+type logSampler struct {
+	defaultEvery int
+	every        map[string]int
+	counters     sync.Map // route -> *atomic.Uint64
+}
+
+// sample reports whether the next successful request of route should be logged.
+// The first request of every window is, so new routes show up immediately.
+func (ls *logSampler) sample(route string) bool {
+	every, ok := ls.every[route]
+	if !ok {
+		every = ls.defaultEvery
+	}
+	if every <= 1 {
+		return true
+	}
+
+	counter, ok := ls.counters.Load(route)
+	if !ok {
+		counter, _ = ls.counters.LoadOrStore(route, new(atomic.Uint64))
+	}
+	return (counter.(*atomic.Uint64).Add(1)-1)%uint64(every) == 0
+}
+
+// WithLogSkipPaths stops requests matching any of the patterns from being logged,
+// e.g. WithLogSkipPaths("/healthz", "GET /metrics"). See WithSkipPaths for the pattern syntax.
+// Handlers still get a request-scoped logger.
+func WithLogSkipPaths(patterns ...string) LoggingOption {
+	return func(cfg *loggingConfig) {
+		cfg.skipPaths = append(cfg.skipPaths, compilePathPatterns(patterns)...)
+	}
+}
+
+// WithLogSkipFunc stops requests for which skip returns true from being logged.
+// It runs after the request was handled, so it can look at the response, e.g. to skip
+// only successful health checks:
+//
+//	WithLogSkipFunc(func(ctx *gin.Context) bool {
+//		return ctx.FullPath() == "/healthz" && ctx.Writer.Status() < 400
+//	})
+func WithLogSkipFunc(skip func(ctx *gin.Context) bool) LoggingOption {
+	return func(cfg *loggingConfig) {
+		cfg.skipFunc = skip
 	}
 }
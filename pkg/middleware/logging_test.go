@@ -2,13 +2,25 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2"
 	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,3 +57,374 @@ func TestNewLoggingMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
+
+func TestNewLoggingMiddlewareInjectsLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	var injected ezutil.Logger
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware())
+	r.GET("/", func(ctx *gin.Context) {
+		injected = GetLogger(ctx)
+		ctx.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	assert.NotNil(t, injected)
+	assert.NotSame(t, discardLogger, injected)
+}
+
+// recordingLogger keeps the messages logged through it, with their level.
+type recordingLogger struct {
+	ezutil.Logger
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+type logEntry struct {
+	level   string
+	message string
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{Logger: simple.NewLogger("test", true, 0)}
+}
+
+func (rl *recordingLogger) record(level string, args []any) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.entries = append(rl.entries, logEntry{level, fmt.Sprint(args...)})
+}
+
+func (rl *recordingLogger) Debug(args ...any) { rl.record("debug", args) }
+func (rl *recordingLogger) Info(args ...any)  { rl.record("info", args) }
+func (rl *recordingLogger) Warn(args ...any)  { rl.record("warn", args) }
+func (rl *recordingLogger) Error(args ...any) { rl.record("error", args) }
+
+func (rl *recordingLogger) WithContext(context.Context) ezutil.Logger { return rl }
+func (rl *recordingLogger) WithError(error) ezutil.Logger             { return rl }
+func (rl *recordingLogger) WithField(string, any) ezutil.Logger       { return rl }
+func (rl *recordingLogger) WithFields(map[string]any) ezutil.Logger   { return rl }
+
+func (rl *recordingLogger) logged() []logEntry {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return slices.Clone(rl.entries)
+}
+
+func TestNewLoggingMiddlewareSlowRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware(WithSlowRequestThreshold(20 * time.Millisecond)))
+	r.GET("/fast", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.GET("/slow", func(ctx *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/slow-failure", func(ctx *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		ctx.Status(http.StatusBadGateway)
+	})
+
+	for _, path := range []string{"/fast", "/slow", "/slow-failure"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	entries := logger.logged()
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, "info", entries[0].level)
+		assert.NotContains(t, entries[0].message, "slow_request")
+		assert.Equal(t, "warn", entries[1].level)
+		assert.Contains(t, entries[1].message, "path=/slow ")
+		assert.Contains(t, entries[1].message, "slow_request=true threshold=20ms")
+		assert.Equal(t, "error", entries[2].level)
+	}
+}
+
+func TestNewLoggingMiddlewareSkips(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	var injected ezutil.Logger
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware(
+		WithLogSkipPaths("/healthz", "GET /metrics"),
+		WithLogSkipFunc(func(ctx *gin.Context) bool {
+			return ctx.FullPath() == "/ready" && ctx.Writer.Status() < 400
+		}),
+	))
+	r.GET("/healthz", func(ctx *gin.Context) {
+		injected = GetLogger(ctx)
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/metrics", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.POST("/metrics", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.GET("/ready", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+		if ctx.Query("fail") != "" {
+			ctx.Status(http.StatusServiceUnavailable)
+		}
+	})
+
+	for _, req := range [][2]string{
+		{"GET", "/healthz"},
+		{"GET", "/metrics"},
+		{"POST", "/metrics"},
+		{"GET", "/ready"},
+		{"GET", "/ready?fail=1"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req[0], req[1], nil))
+	}
+
+	assert.NotSame(t, discardLogger, injected)
+	entries := logger.logged()
+	if assert.Len(t, entries, 2) {
+		assert.Contains(t, entries[0].message, "method=POST path=/metrics ")
+		assert.Contains(t, entries[1].message, "path=/ready?fail=1 status=503")
+	}
+}
+
+func TestNewLoggingMiddlewareStructured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := logging.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewRequestIDMiddleware())
+	r.Use(mp.NewLoggingMiddleware(WithStructuredLogs()))
+	r.GET("/items", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("no items"))
+		ctx.Status(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest("GET", "/items?page=2", nil)
+	req.Header.Set(headerRequestID, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "http request", entry["msg"])
+	assert.Equal(t, "GET", entry["http.method"])
+	assert.Equal(t, "/items", entry["http.path"])
+	assert.Equal(t, "page=2", entry["http.query"])
+	assert.Equal(t, float64(http.StatusNotFound), entry["http.status_code"])
+	assert.Equal(t, "192.0.2.1", entry["http.client_ip"])
+	assert.Equal(t, "req-1", entry["http.request_id"])
+	assert.Equal(t, "no items", entry["error"])
+	assert.Contains(t, entry, "http.duration")
+}
+
+func TestNewLoggingMiddlewareBodyLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := logging.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware(WithStructuredLogs(), WithBodyLogging(BodyLoggingConfig{
+		MaxBodySize:  64,
+		RedactFields: []string{"pin"},
+	})))
+	r.POST("/login", func(ctx *gin.Context) {
+		var req map[string]any
+		assert.NoError(t, ctx.ShouldBindJSON(&req), "handler can still read the body")
+		ctx.JSON(http.StatusOK, gin.H{"access_token": "xyz", "user": req["user"]})
+	})
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"user":"ann","password":"hunter2","pin":"1234"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Basic YW5uOmh1bnRlcjI=")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"access_token":"xyz","user":"ann"}`, w.Body.String())
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	var exchange map[string]any
+	assert.NoError(t, json.Unmarshal(lines[0], &exchange))
+	assert.Equal(t, "DEBUG", exchange["level"])
+	assert.Equal(t, "http exchange", exchange["msg"])
+	assert.JSONEq(t, `{"password":"[REDACTED]","pin":"[REDACTED]","user":"ann"}`, exchange["http.request.body"].(string))
+	assert.JSONEq(t, `{"access_token":"[REDACTED]","user":"ann"}`, exchange["http.response.body"].(string))
+	assert.Equal(t, []any{redactedValue}, exchange["http.request.headers"].(map[string]any)["Authorization"])
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestNewLoggingMiddlewareSampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware(
+		WithLogSampling(3),
+		WithLogSampling(1, "/rare"),
+	))
+	r.GET("/items/:id", func(ctx *gin.Context) {
+		if ctx.Param("id") == "missing" {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/rare", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	serve := func(path string, times int) {
+		for range times {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+	}
+	count := func(substr string) int {
+		n := 0
+		for _, entry := range logger.logged() {
+			if strings.Contains(entry.message, substr) {
+				n++
+			}
+		}
+		return n
+	}
+
+	serve("/items/1", 4)
+	serve("/items/2", 3)
+	serve("/items/missing", 2)
+	serve("/rare", 2)
+
+	// 7 successful requests on /items/:id, counted together: the 1st, 4th and 7th are logged
+	assert.Equal(t, 3, count("status=200")-count("path=/rare"))
+	assert.Equal(t, 2, count("status=404"))
+	assert.Equal(t, 2, count("path=/rare"))
+}
+
+func TestNewLoggingMiddlewareStatusClassLevels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := newRecordingLogger()
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware(
+		WithStatusClassLogLevel(http.StatusOK, LogLevelDebug),
+		WithStatusClassLogLevel(http.StatusNotFound, LogLevelWarn),
+	))
+	r.GET("/status/:code", func(ctx *gin.Context) {
+		code, _ := strconv.Atoi(ctx.Param("code"))
+		ctx.Status(code)
+	})
+
+	for _, code := range []string{"200", "302", "404", "503"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/"+code, nil))
+	}
+
+	var levels []string
+	for _, entry := range logger.logged() {
+		levels = append(levels, entry.level)
+	}
+	assert.Equal(t, []string{"debug", "info", "warn", "error"}, levels)
+}
+
+func TestWriteAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	writeAccessLog(&buf, "GET", "/api/test", "q=1", http.StatusNotFound, 1500*time.Microsecond, "10.0.0.1", "")
+
+	assert.Equal(t, "[HTTP] method=GET path=/api/test?q=1 status=404 duration=1.5ms client_ip=10.0.0.1", buf.String())
+
+	buf.Reset()
+	writeAccessLog(&buf, "GET", "/", "", http.StatusOK, time.Millisecond, "10.0.0.1", "req-1")
+
+	assert.Equal(t, "[HTTP] method=GET path=/ status=200 duration=1ms client_ip=10.0.0.1 request_id=req-1", buf.String())
+}
+
+func TestAppendDuration(t *testing.T) {
+	durations := []time.Duration{
+		0,
+		1,
+		999,
+		time.Microsecond,
+		1500 * time.Nanosecond,
+		time.Millisecond + 250*time.Microsecond,
+		time.Second,
+		90 * time.Second,
+		time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond,
+		-2500 * time.Microsecond,
+		time.Duration(1<<63 - 1),
+		time.Duration(-1 << 63),
+	}
+
+	for _, d := range durations {
+		var buf bytes.Buffer
+		appendDuration(&buf, d)
+		assert.Equal(t, d.String(), buf.String())
+	}
+}
+
+// BenchmarkAccessLogLine compares the buffered access-log writer with the
+// fmt-based formatting the logging middleware used previously.
+func BenchmarkAccessLogLine(b *testing.B) {
+	elapsed := 1234567 * time.Nanosecond
+
+	b.Run("sprintf", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = fmt.Sprintf("[HTTP] method=%s path=%s status=%d duration=%s client_ip=%s",
+				"GET", "/api/test?page=1", http.StatusOK, elapsed, "10.0.0.1")
+		}
+	})
+
+	b.Run("buffer", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := getBuffer()
+			writeAccessLog(buf, "GET", "/api/test", "page=1", http.StatusOK, elapsed, "10.0.0.1", "")
+			putBuffer(buf)
+		}
+	})
+}
+
+// discardStdout silences simple.Logger, which prints to stdout, for the duration of a benchmark.
+func discardStdout(b *testing.B) {
+	b.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	b.Cleanup(func() {
+		os.Stdout = stdout
+		_ = devNull.Close()
+	})
+}
+
+func BenchmarkLoggingMiddleware(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	discardStdout(b)
+	mp := NewMiddlewareProvider(simple.NewLogger("bench", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewLoggingMiddleware())
+	r.GET("/ok", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.GET("/fail", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("boom"))
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"/ok", "/fail"} {
+		b.Run(path[1:], func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodGet, path+"?page=1", nil)
+			b.ReportAllocs()
+			for b.Loop() {
+				r.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMaintenanceMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	mode := NewMaintenanceMode()
+	r := gin.New()
+	r.Use(mp.NewMaintenanceMiddleware(mode, 90*time.Second, "/healthz"))
+	r.GET("/healthz", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.GET("/orders", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/orders").Code)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		mode.Enable()
+		defer mode.Disable()
+
+		w := serve("/orders")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "90", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "service is under maintenance")
+
+		assert.Equal(t, http.StatusOK, serve("/healthz").Code)
+	})
+
+	t.Run("toggled off", func(t *testing.T) {
+		assert.False(t, mode.Enabled())
+		assert.Equal(t, http.StatusOK, serve("/orders").Code)
+	})
+}
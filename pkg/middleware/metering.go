@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2"
+)
+
+// MeterUsage is the usage aggregated for one metering key over a flush window.
+type MeterUsage struct {
+	Key           string
+	Requests      int64
+	RequestBytes  int64
+	ResponseBytes int64
+	WindowStart   time.Time
+	WindowEnd     time.Time
+}
+
+// MeterSink receives aggregated usage, e.g. to forward it to a billing system.
+// Flush is called from a single background goroutine.
+type MeterSink interface {
+	Flush(ctx context.Context, usages []MeterUsage) error
+}
+
+// MeterConfig configures a Meter.
+type MeterConfig struct {
+	Sink MeterSink
+	// KeyFunc returns the key usage is billed to, e.g. a tenant or API key.
+	// Requests for which it returns "" are not metered.
+	// Defaults to the subject of the current Principal.
+	KeyFunc func(ctx *gin.Context) string
+	// FlushInterval is how often aggregated usage is sent to Sink. Defaults to 10 seconds.
+	FlushInterval time.Duration
+	// BufferSize bounds the number of pending records; further records are dropped
+	// with a warning rather than blocking requests. Defaults to 10000.
+	BufferSize int
+}
+
+type meterRecord struct {
+	key           string
+	requestBytes  int64
+	responseBytes int64
+}
+
+// Meter aggregates per-key request counts and payload sizes and flushes them
+// asynchronously to a MeterSink, decoupling handlers from the billing system.
+type Meter struct {
+	cfg     MeterConfig
+	logger  ezutil.Logger
+	records chan meterRecord
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewMeter creates a Meter and starts its background flusher.
+// Call Close on shutdown to flush the remaining usage.
+func (mp *MiddlewareProvider) NewMeter(cfg MeterConfig) *Meter {
+	if cfg.Sink == nil {
+		mp.logger.Fatal("meter sink cannot be nil")
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = principalSubject
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+
+	m := &Meter{
+		cfg:     cfg,
+		logger:  mp.logger,
+		records: make(chan meterRecord, cfg.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// NewMeteringMiddleware creates a middleware that records every request to m.
+func (mp *MiddlewareProvider) NewMeteringMiddleware(m *Meter) gin.HandlerFunc {
+	if m == nil {
+		mp.logger.Fatal("meter cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		key := m.cfg.KeyFunc(ctx)
+		if key == "" {
+			return
+		}
+
+		// records is never closed, so requests finishing during shutdown can't panic;
+		// they are just not metered once the meter is closed.
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+
+		record := meterRecord{
+			key:           key,
+			requestBytes:  max(ctx.Request.ContentLength, 0),
+			responseBytes: int64(max(ctx.Writer.Size(), 0)),
+		}
+		select {
+		case m.records <- record:
+		default:
+			m.logger.Warnf("metering buffer full, dropping record for key: %s", key)
+		}
+	}
+}
+
+// Close stops the background flusher after sending the remaining usage to the sink.
+// Call it once the server has stopped serving requests; requests still finishing
+// afterwards are not metered.
+func (m *Meter) Close(ctx context.Context) error {
+	m.once.Do(func() { close(m.stop) })
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Meter) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	usages := make(map[string]*MeterUsage)
+	windowStart := time.Now()
+
+	flush := func() {
+		if len(usages) == 0 {
+			windowStart = time.Now()
+			return
+		}
+		windowEnd := time.Now()
+		batch := make([]MeterUsage, 0, len(usages))
+		for _, usage := range usages {
+			usage.WindowStart, usage.WindowEnd = windowStart, windowEnd
+			batch = append(batch, *usage)
+		}
+		if err := m.cfg.Sink.Flush(context.Background(), batch); err != nil {
+			m.logger.WithError(err).Errorf("error flushing %d metering usages", len(batch))
+		}
+		usages = make(map[string]*MeterUsage)
+		windowStart = windowEnd
+	}
+
+	add := func(record meterRecord) {
+		usage, exists := usages[record.key]
+		if !exists {
+			usage = &MeterUsage{Key: record.key}
+			usages[record.key] = usage
+		}
+		usage.Requests++
+		usage.RequestBytes += record.requestBytes
+		usage.ResponseBytes += record.responseBytes
+	}
+
+	for {
+		select {
+		case record := <-m.records:
+			add(record)
+		case <-ticker.C:
+			flush()
+		case <-m.stop:
+			for {
+				select {
+				case record := <-m.records:
+					add(record)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func principalSubject(ctx *gin.Context) string {
+	if principal, ok := CurrentPrincipal(ctx); ok {
+		return principal.Subject()
+	}
+	return ""
+}
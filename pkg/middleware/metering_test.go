@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySink struct {
+	usages []MeterUsage
+	mu     sync.Mutex
+}
+
+func (ms *memorySink) Flush(_ context.Context, usages []MeterUsage) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.usages = append(ms.usages, usages...)
+	return nil
+}
+
+func TestNewMeteringMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	sink := &memorySink{}
+	meter := mp.NewMeter(MeterConfig{
+		Sink:          sink,
+		KeyFunc:       func(ctx *gin.Context) string { return ctx.GetHeader("X-API-Key") },
+		FlushInterval: time.Hour,
+	})
+
+	r := gin.New()
+	r.Use(mp.NewMeteringMiddleware(meter))
+	r.POST("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+
+	for _, key := range []string{"tenant-a", "tenant-a", "tenant-b", ""} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("body"))
+		req.Header.Set("X-API-Key", key)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.NoError(t, meter.Close(context.Background()))
+
+	usages := make(map[string]MeterUsage)
+	for _, usage := range sink.usages {
+		usages[usage.Key] = usage
+	}
+	assert.Len(t, usages, 2)
+	assert.Equal(t, int64(2), usages["tenant-a"].Requests)
+	assert.Equal(t, int64(8), usages["tenant-a"].RequestBytes)
+	assert.Equal(t, int64(10), usages["tenant-a"].ResponseBytes)
+	assert.Equal(t, int64(1), usages["tenant-b"].Requests)
+}
+
+func TestMeterRequestsAfterClose(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	meter := mp.NewMeter(MeterConfig{
+		Sink:          &memorySink{},
+		KeyFunc:       func(*gin.Context) string { return "tenant-a" },
+		FlushInterval: time.Hour,
+	})
+	r := gin.New()
+	r.Use(mp.NewMeteringMiddleware(meter))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+	}
+	assert.NoError(t, meter.Close(context.Background()))
+	wg.Wait()
+
+	assert.NotPanics(t, func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	})
+}
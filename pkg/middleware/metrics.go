@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	metricErrorOutcomes     = "ginkgo_error_middleware_outcomes"
+	metricPanicsRecovered   = "ginkgo_panics_recovered"
+	metricAuthFailures      = "ginkgo_auth_failures"
+	metricRateLimitRejected = "ginkgo_rate_limit_rejections"
+	metricLoadShed          = "ginkgo_load_shed_requests"
+)
+
+// MetricsRecorder receives the internal counters of ginkgo's middlewares
+// (error outcomes, recovered panics, auth failures, rate-limit rejections, shed requests),
+// so dashboards can track the health of the middleware itself.
+type MetricsRecorder interface {
+	IncCounter(name string, labels map[string]string)
+}
+
+type nopMetricsRecorder struct{}
+
+func (nopMetricsRecorder) IncCounter(string, map[string]string) {}
+
+// OpenMetricsRecorder is an in-memory MetricsRecorder that exposes its counters
+// in the OpenMetrics text format.
+type OpenMetricsRecorder struct {
+	counters map[string]map[string]float64
+	mu       sync.Mutex
+}
+
+// NewOpenMetricsRecorder creates an empty OpenMetricsRecorder.
+func NewOpenMetricsRecorder() *OpenMetricsRecorder {
+	return &OpenMetricsRecorder{counters: make(map[string]map[string]float64)}
+}
+
+func (omr *OpenMetricsRecorder) IncCounter(name string, labels map[string]string) {
+	key := formatLabels(labels)
+
+	omr.mu.Lock()
+	defer omr.mu.Unlock()
+
+	if omr.counters[name] == nil {
+		omr.counters[name] = make(map[string]float64)
+	}
+	omr.counters[name][key]++
+}
+
+// Expose renders all counters in the OpenMetrics text format.
+func (omr *OpenMetricsRecorder) Expose() string {
+	omr.mu.Lock()
+	defer omr.mu.Unlock()
+
+	var sb strings.Builder
+	for _, name := range sortedKeys(omr.counters) {
+		fmt.Fprintf(&sb, "# TYPE %s counter\n", name)
+		samples := omr.counters[name]
+		for _, labels := range sortedKeys(samples) {
+			fmt.Fprintf(&sb, "%s_total%s %g\n", name, labels, samples[labels])
+		}
+	}
+	sb.WriteString("# EOF\n")
+	return sb.String()
+}
+
+// Handler returns a Gin handler serving the counters, e.g. mounted at /metrics.
+func (omr *OpenMetricsRecorder) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/openmetrics-text; version=1.0.0; charset=utf-8", []byte(omr.Expose()))
+	}
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, name := range sortedKeys(labels) {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[name])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
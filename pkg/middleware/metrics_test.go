@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMetricsRecorder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	recorder := NewOpenMetricsRecorder()
+	mp := NewMiddlewareProvider(logger, WithMetricsRecorder(recorder))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.GET("/metrics", recorder.Handler())
+	r.GET("/not-found", func(c *gin.Context) {
+		_ = c.Error(ungerr.NotFoundError("missing"))
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("oops")
+	})
+	r.GET("/private", mp.NewAuthMiddleware("Bearer", func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+		return true, nil, nil
+	}))
+
+	for _, path := range []string{"/not-found", "/not-found", "/panic", "/private"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/openmetrics-text")
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE ginkgo_error_middleware_outcomes counter\n")
+	assert.Contains(t, body, `ginkgo_error_middleware_outcomes_total{outcome="app_error",status="404"} 2`)
+	assert.Contains(t, body, `ginkgo_error_middleware_outcomes_total{outcome="app_error",status="401"} 1`)
+	assert.Contains(t, body, "ginkgo_panics_recovered_total 1\n")
+	assert.Contains(t, body, `ginkgo_auth_failures_total{reason="missing_token"} 1`)
+	assert.Contains(t, body, "# EOF\n")
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", formatLabels(nil))
+	assert.Equal(t, `{a="1",b="x\"y"}`, formatLabels(map[string]string{"b": `x"y`, "a": "1"}))
+}
@@ -8,16 +8,35 @@ import (
 )
 
 type MiddlewareProvider struct {
-	logger ezutil.Logger
+	logger   ezutil.Logger
+	metrics  MetricsRecorder
+	reporter ErrorReporter
+	mappers  *errorMappers
 }
 
-func NewMiddlewareProvider(logger ezutil.Logger) *MiddlewareProvider {
+// ProviderOption configures optional dependencies of a MiddlewareProvider.
+type ProviderOption func(*MiddlewareProvider)
+
+// WithMetricsRecorder makes the provider's middlewares report their internal counters to mr.
+func WithMetricsRecorder(mr MetricsRecorder) ProviderOption {
+	return func(mp *MiddlewareProvider) {
+		if mr != nil {
+			mp.metrics = mr
+		}
+	}
+}
+
+func NewMiddlewareProvider(logger ezutil.Logger, opts ...ProviderOption) *MiddlewareProvider {
 	if logger == nil {
 		log.Fatal("logger cannot be nil")
 	}
-	return &MiddlewareProvider{logger}
+	mp := &MiddlewareProvider{logger: logger, metrics: nopMetricsRecorder{}, mappers: &errorMappers{}}
+	for _, opt := range opts {
+		opt(mp)
+	}
+	return mp
 }
 
-func (mp *MiddlewareProvider) NewErrorMiddleware() gin.HandlerFunc {
-	return newErrorMiddleware(mp.logger)
+func (mp *MiddlewareProvider) NewErrorMiddleware(opts ...ErrorOption) gin.HandlerFunc {
+	return newErrorMiddleware(mp.logger, mp.metrics, mp.reporter, mp.mappers, opts)
 }
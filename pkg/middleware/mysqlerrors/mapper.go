@@ -0,0 +1,52 @@
+// Package mysqlerrors maps MySQL integrity constraint violations to AppErrors for the error
+// middleware. It is kept out of package middleware so only services using MySQL depend on
+// its driver.
+package mysqlerrors
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ungerr"
+)
+
+// MySQL error numbers of integrity constraint violations.
+const (
+	duplicateEntry      = 1062
+	rowIsReferenced     = 1451
+	noReferencedRow     = 1452
+	badNull             = 1048
+	checkConstraintFail = 3819
+)
+
+var _ middleware.ErrorMapper = Mapper
+
+// Mapper is a middleware.ErrorMapper for *mysql.MySQLError, mapping duplicate entries and
+// deleting a referenced row to 409 Conflict, and references to missing rows, NULLs in
+// NOT NULL columns and failed CHECK constraints to 422 Unprocessable Entity, with the
+// same error codes as the PostgreSQL errors recognised by the error middleware.
+// The details never include the constraint or table name. Register it with
+// MiddlewareProvider.RegisterMapper.
+func Mapper(err error) (ungerr.AppError, bool) {
+	e, ok := err.(*mysql.MySQLError)
+	if !ok {
+		return nil, false
+	}
+
+	switch e.Number {
+	case duplicateEntry:
+		return middleware.WithErrorCode(ungerr.ConflictError("resource already exists"), middleware.ErrorCodeDuplicateResource), true
+	case rowIsReferenced:
+		return middleware.WithErrorCode(ungerr.ConflictError("resource is still referenced"), middleware.ErrorCodeResourceReferenced), true
+	case noReferencedRow:
+		return middleware.WithErrorCode(
+			ungerr.UnprocessableEntityError("referenced resource does not exist"),
+			middleware.ErrorCodeInvalidReference,
+		), true
+	case badNull, checkConstraintFail:
+		return middleware.WithErrorCode(
+			ungerr.UnprocessableEntityError("resource violates a constraint"),
+			middleware.ErrorCodeConstraintViolated,
+		), true
+	}
+	return nil, false
+}
@@ -0,0 +1,49 @@
+package mysqlerrors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapper(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+	mp.RegisterMapper(Mapper)
+
+	cases := map[string]struct {
+		err    error
+		status int
+		code   middleware.ErrorCode
+	}{
+		"duplicate":     {&mysql.MySQLError{Number: 1062}, http.StatusConflict, middleware.ErrorCodeDuplicateResource},
+		"referenced":    {&mysql.MySQLError{Number: 1451}, http.StatusConflict, middleware.ErrorCodeResourceReferenced},
+		"no referenced": {&mysql.MySQLError{Number: 1452}, http.StatusUnprocessableEntity, middleware.ErrorCodeInvalidReference},
+		"check":         {&mysql.MySQLError{Number: 3819}, http.StatusUnprocessableEntity, middleware.ErrorCodeConstraintViolated},
+		"lock wait":     {&mysql.MySQLError{Number: 1205}, http.StatusInternalServerError, middleware.ErrorCodeInternal},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(mp.NewErrorMiddleware())
+			r.GET("/", func(ctx *gin.Context) {
+				_ = ctx.Error(ungerr.Wrap(fmt.Errorf("inserting user: %w", tc.err), "error creating user"))
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Contains(t, w.Body.String(), fmt.Sprintf(`"errorCode":"%s"`, tc.code))
+		})
+	}
+}
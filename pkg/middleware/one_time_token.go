@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/itsLeonB/ungerr"
+)
+
+const oneTimeTokenParam = "token"
+
+// OneTimeTokens issues and redeems single-use tokens, typically for download links,
+// so a leaked link cannot be replayed. Tokens are kept in a store.Store.
+type OneTimeTokens struct {
+	store store.Store
+}
+
+// NewOneTimeTokens creates a OneTimeTokens using s to keep issued and used tokens.
+func NewOneTimeTokens(s store.Store) *OneTimeTokens {
+	return &OneTimeTokens{s}
+}
+
+// Issue creates a token granting one-time access to resource, valid for ttl.
+func (ott *OneTimeTokens) Issue(ctx context.Context, resource string, ttl time.Duration) (string, error) {
+	token, err := newRandomID()
+	if err != nil {
+		return "", err
+	}
+	if err = ott.store.Set(ctx, oneTimeTokenKey(token), []byte(resource), ttl); err != nil {
+		return "", ungerr.Wrap(err, "error storing one-time token")
+	}
+	return token, nil
+}
+
+// IssueSignedURL issues a token for resource and returns rawURL signed by signer with
+// the token attached, so the link is both tamper-proof and single-use.
+func (ott *OneTimeTokens) IssueSignedURL(
+	ctx context.Context,
+	signer *URLSigner,
+	rawURL string,
+	resource string,
+	ttl time.Duration,
+) (string, error) {
+	token, err := ott.Issue(ctx, resource, ttl)
+	if err != nil {
+		return "", err
+	}
+	return signer.Sign(rawURL, ttl, SignOptions{Claims: map[string]string{oneTimeTokenParam: token}})
+}
+
+// Redeem consumes token and returns the resource it was issued for.
+// It returns a ForbiddenError if the token is unknown, expired or already used.
+func (ott *OneTimeTokens) Redeem(ctx context.Context, token string) (string, error) {
+	resource, exists, err := ott.store.Get(ctx, oneTimeTokenKey(token))
+	if err != nil {
+		return "", ungerr.Wrap(err, "error reading one-time token")
+	}
+	if !exists {
+		return "", ungerr.ForbiddenError("token expired or invalid")
+	}
+
+	firstUse, err := ott.store.SetNX(ctx, oneTimeTokenUsedKey(token), []byte{1}, 24*time.Hour)
+	if err != nil {
+		return "", ungerr.Wrap(err, "error marking one-time token as used")
+	}
+	if !firstUse {
+		return "", ungerr.ForbiddenError("token already used")
+	}
+
+	if err = ott.store.Delete(ctx, oneTimeTokenKey(token)); err != nil {
+		return "", ungerr.Wrap(err, "error deleting one-time token")
+	}
+
+	return string(resource), nil
+}
+
+// NewOneTimeTokenMiddleware creates a middleware that redeems the "token" query parameter
+// and stores the resource it grants in the Gin context (see OneTimeTokenResource).
+// Combine it with NewSignedURLMiddleware for links created by IssueSignedURL.
+func (mp *MiddlewareProvider) NewOneTimeTokenMiddleware(ott *OneTimeTokens) gin.HandlerFunc {
+	if ott == nil {
+		mp.logger.Fatal("one-time tokens cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		token := ctx.Query(oneTimeTokenParam)
+		if token == "" {
+			_ = ctx.Error(ungerr.ForbiddenError("missing token"))
+			ctx.Abort()
+			return
+		}
+
+		resource, err := ott.Redeem(ctx, token)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		oneTimeTokenContextKey.Set(ctx, resource)
+		ctx.Next()
+	}
+}
+
+// OneTimeTokenResource returns the resource granted by the redeemed one-time token.
+func OneTimeTokenResource(ctx *gin.Context) string {
+	return oneTimeTokenContextKey.Value(ctx)
+}
+
+func oneTimeTokenKey(token string) string {
+	return "one-time-token:" + token
+}
+
+func oneTimeTokenUsedKey(token string) string {
+	return "one-time-token-used:" + token
+}
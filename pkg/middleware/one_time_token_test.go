@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneTimeTokens(t *testing.T) {
+	ctx := context.Background()
+	ott := NewOneTimeTokens(store.NewMemoryStore())
+
+	token, err := ott.Issue(ctx, "report-1", time.Minute)
+	assert.NoError(t, err)
+
+	resource, err := ott.Redeem(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, "report-1", resource)
+
+	_, err = ott.Redeem(ctx, token)
+	assert.Error(t, err)
+
+	_, err = ott.Redeem(ctx, "unknown")
+	assert.Error(t, err)
+}
+
+func TestNewOneTimeTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	signer := NewURLSigner([]byte("secret"))
+	ott := NewOneTimeTokens(store.NewMemoryStore())
+
+	r := gin.New()
+	r.GET("/download", mp.NewSignedURLMiddleware(signer), mp.NewOneTimeTokenMiddleware(ott), func(c *gin.Context) {
+		c.String(http.StatusOK, OneTimeTokenResource(c))
+	})
+
+	signed, err := ott.IssueSignedURL(context.Background(), signer, "/download", "report-1", time.Minute)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", signed, nil))
+	assert.Equal(t, "report-1", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", signed, nil))
+	assert.Empty(t, w.Body.String())
+}
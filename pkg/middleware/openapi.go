@@ -0,0 +1,579 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOption configures NewOpenAPIValidationMiddleware.
+type OpenAPIOption func(*openAPIConfig)
+
+type openAPIConfig struct {
+	basePath     string
+	basePathSet  bool
+	maxBodyBytes int64
+}
+
+const defaultOpenAPIMaxBodyBytes = 1 << 20
+
+// WithOpenAPIBasePath sets the prefix of request paths that the document's paths are
+// relative to, e.g. "/api/v1". Defaults to the path of the document's first server URL.
+func WithOpenAPIBasePath(basePath string) OpenAPIOption {
+	return func(cfg *openAPIConfig) {
+		cfg.basePath = strings.TrimRight(basePath, "/")
+		cfg.basePathSet = true
+	}
+}
+
+// WithOpenAPIMaxBodyBytes sets how large a JSON request body may be to be validated.
+// Larger bodies are rejected with 413 Payload Too Large. Defaults to 1 MiB.
+func WithOpenAPIMaxBodyBytes(n int64) OpenAPIOption {
+	return func(cfg *openAPIConfig) {
+		if n > 0 {
+			cfg.maxBodyBytes = n
+		}
+	}
+}
+
+// NewOpenAPIValidationMiddleware validates requests against spec, an OpenAPI 3 document
+// in JSON or YAML, before they reach their handlers, so the documented schema and the
+// implementation can't drift apart. For the operation matching the request's method and
+// path it checks the path, query, header and cookie parameters, and for JSON request
+// bodies the body schema, including $ref'd components.
+//
+// A malformed JSON body, a missing required body or an undocumented content type is
+// rejected with 400 Bad Request, and a JSON body over the size limit (see
+// WithOpenAPIMaxBodyBytes) with 413 Payload Too Large. Parameters and body fields that don't match their schema
+// are rejected with a single 422 VALIDATION_FAILED error whose details are keyed by
+// parameter name or by the field's path in the body, e.g. "items[0].sku".
+// Requests for operations the document doesn't describe pass through unchecked.
+// It exits the process if spec is not a valid OpenAPI 3 document.
+func (mp *MiddlewareProvider) NewOpenAPIValidationMiddleware(spec []byte, opts ...OpenAPIOption) gin.HandlerFunc {
+	var cfg openAPIConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	v, err := newOpenAPIValidator(spec, cfg)
+	if err != nil {
+		mp.logger.Fatalf("invalid OpenAPI document: %v", err)
+	}
+
+	return func(ctx *gin.Context) {
+		op, pathParams, ok := v.match(ctx.Request.Method, ctx.Request.URL.Path)
+		if !ok {
+			ctx.Next()
+			return
+		}
+		if err := v.validateRequest(ctx, op, pathParams); err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+type openAPIValidator struct {
+	doc          map[string]any
+	basePath     string
+	maxBodyBytes int64
+	routes       []openAPIRoute
+	patterns     sync.Map // pattern string -> *regexp.Regexp
+}
+
+type openAPIRoute struct {
+	template   string
+	segments   []string
+	literals   int
+	operations map[string]openAPIOperation
+}
+
+type openAPIOperation struct {
+	params []openAPIParameter
+	body   *openAPIRequestBody
+}
+
+type openAPIParameter struct {
+	name     string
+	in       string
+	required bool
+	schema   any
+}
+
+type openAPIRequestBody struct {
+	required bool
+	content  map[string]any // media type -> schema, nil if the media type has none
+}
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func newOpenAPIValidator(spec []byte, cfg openAPIConfig) (*openAPIValidator, error) {
+	var raw any
+	if err := yaml.Unmarshal(spec, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing document: %w", err)
+	}
+	doc, ok := normalizeYAML(raw).(map[string]any)
+	if !ok {
+		return nil, errors.New("document must be an object")
+	}
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, errors.New("only OpenAPI 3 documents are supported")
+	}
+
+	v := &openAPIValidator{doc: doc, basePath: cfg.basePath, maxBodyBytes: cfg.maxBodyBytes}
+	if v.maxBodyBytes <= 0 {
+		v.maxBodyBytes = defaultOpenAPIMaxBodyBytes
+	}
+	if !cfg.basePathSet {
+		v.basePath = serverBasePath(doc)
+	}
+	if err := v.checkRefs(doc); err != nil {
+		return nil, err
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	for template, item := range paths {
+		pathItem, err := v.resolve(item)
+		if err != nil {
+			return nil, err
+		}
+
+		route := openAPIRoute{
+			template:   template,
+			segments:   splitOpenAPIPath(template),
+			operations: map[string]openAPIOperation{},
+		}
+		for _, segment := range route.segments {
+			if !isPathTemplate(segment) {
+				route.literals++
+			}
+		}
+		for _, method := range openAPIMethods {
+			if operation, ok := pathItem[method]; ok {
+				op, err := v.parseOperation(pathItem["parameters"], operation)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), template, err)
+				}
+				route.operations[strings.ToUpper(method)] = op
+			}
+		}
+		v.routes = append(v.routes, route)
+	}
+
+	// Concrete paths take precedence over templated ones, e.g. /users/me over /users/{id}.
+	slices.SortFunc(v.routes, func(a, b openAPIRoute) int {
+		if a.literals != b.literals {
+			return b.literals - a.literals
+		}
+		return strings.Compare(a.template, b.template)
+	})
+	return v, nil
+}
+
+func (v *openAPIValidator) parseOperation(shared, operation any) (openAPIOperation, error) {
+	op, err := v.resolve(operation)
+	if err != nil {
+		return openAPIOperation{}, err
+	}
+
+	params := map[string]openAPIParameter{}
+	for _, list := range []any{shared, op["parameters"]} {
+		items, _ := list.([]any)
+		for _, item := range items {
+			param, err := v.resolve(item)
+			if err != nil {
+				return openAPIOperation{}, err
+			}
+			p := openAPIParameter{schema: param["schema"]}
+			p.name, _ = param["name"].(string)
+			p.in, _ = param["in"].(string)
+			p.required, _ = param["required"].(bool)
+			if p.in == "path" {
+				p.required = true
+			}
+			// Operation parameters override path item parameters with the same name and location.
+			params[p.in+":"+p.name] = p
+		}
+	}
+
+	var parsed openAPIOperation
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		parsed.params = append(parsed.params, params[key])
+	}
+
+	if requestBody, ok := op["requestBody"]; ok {
+		body, err := v.resolve(requestBody)
+		if err != nil {
+			return openAPIOperation{}, err
+		}
+		parsed.body = &openAPIRequestBody{content: map[string]any{}}
+		parsed.body.required, _ = body["required"].(bool)
+		content, _ := body["content"].(map[string]any)
+		for mediaType, media := range content {
+			mediaMap, _ := media.(map[string]any)
+			parsed.body.content[strings.ToLower(mediaType)] = mediaMap["schema"]
+		}
+	}
+	return parsed, nil
+}
+
+// match finds the operation for method and path, and the values of its path parameters.
+func (v *openAPIValidator) match(method, path string) (openAPIOperation, map[string]string, bool) {
+	if v.basePath != "" {
+		rest, ok := strings.CutPrefix(path, v.basePath)
+		if !ok || (rest != "" && rest[0] != '/') {
+			return openAPIOperation{}, nil, false
+		}
+		path = rest
+	}
+	segments := splitOpenAPIPath(path)
+
+	for _, route := range v.routes {
+		if len(route.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for i, segment := range route.segments {
+			if isPathTemplate(segment) {
+				params[segment[1:len(segment)-1]] = segments[i]
+			} else if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		// A more specific route without the method must not hide a templated one with it.
+		if op, ok := route.operations[method]; ok {
+			return op, params, true
+		}
+	}
+	return openAPIOperation{}, nil, false
+}
+
+func (v *openAPIValidator) validateRequest(ctx *gin.Context, op openAPIOperation, pathParams map[string]string) error {
+	details := map[string]string{}
+	query := ctx.Request.URL.Query()
+
+	for _, param := range op.params {
+		var values []string
+		switch param.in {
+		case "path":
+			if value, ok := pathParams[param.name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[param.name]
+		case "header":
+			values = ctx.Request.Header.Values(param.name)
+		case "cookie":
+			if cookie, err := ctx.Request.Cookie(param.name); err == nil {
+				values = []string{cookie.Value}
+			}
+		}
+
+		if len(values) == 0 {
+			if param.required {
+				details[param.name] = "is required"
+			}
+			continue
+		}
+		if param.schema == nil {
+			continue
+		}
+
+		value, msg, err := v.paramValue(param.schema, values)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			details[param.name] = msg
+			continue
+		}
+		if err := v.validateSchema(param.schema, value, param.name, details); err != nil {
+			return err
+		}
+	}
+
+	if op.body != nil {
+		if err := v.validateBody(ctx, op.body, details); err != nil {
+			return err
+		}
+	}
+
+	if len(details) > 0 {
+		return WithErrorCode(ungerr.ValidationError(details), ErrorCodeValidationFailed)
+	}
+	return nil
+}
+
+func (v *openAPIValidator) validateBody(ctx *gin.Context, body *openAPIRequestBody, details map[string]string) error {
+	req := ctx.Request
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		if body.required {
+			return WithErrorCode(ungerr.BadRequestError("missing request body"), ErrorCodeMissingBody)
+		}
+		return nil
+	}
+
+	mediaType := strings.ToLower(ctx.ContentType())
+	schema, ok := body.schemaFor(mediaType)
+	if !ok {
+		return ungerr.BadRequestError(fmt.Sprintf("unsupported content type %q", mediaType))
+	}
+	if schema == nil || !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	tooLarge := payloadTooLargeError(fmt.Sprintf("request body must be at most %d bytes", v.maxBodyBytes))
+	if req.ContentLength > v.maxBodyBytes {
+		return tooLarge
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, req.Body, v.maxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return tooLarge
+		}
+		return ungerr.Wrap(err, "error reading request body")
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if body.required {
+			return WithErrorCode(ungerr.BadRequestError("missing request body"), ErrorCodeMissingBody)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return WithErrorCode(ungerr.BadRequestError("invalid json"), ErrorCodeInvalidJSON)
+	}
+	return v.validateSchema(schema, value, "", details)
+}
+
+// schemaFor returns the schema for mediaType, falling back to ranges such as
+// "application/*" and "*/*" like OpenAPI does.
+func (b *openAPIRequestBody) schemaFor(mediaType string) (any, bool) {
+	if schema, ok := b.content[mediaType]; ok {
+		return schema, true
+	}
+	if typ, _, ok := strings.Cut(mediaType, "/"); ok {
+		if schema, ok := b.content[typ+"/*"]; ok {
+			return schema, true
+		}
+	}
+	schema, ok := b.content["*/*"]
+	return schema, ok
+}
+
+// paramValue converts the raw values of a parameter to the type of its schema. It returns
+// a message instead if they can't be converted.
+func (v *openAPIValidator) paramValue(schemaNode any, values []string) (any, string, error) {
+	schema, err := v.resolve(schemaNode)
+	if err != nil || schema == nil {
+		return values[0], "", err
+	}
+
+	if !slices.Contains(schemaTypes(schema), "array") {
+		if len(values) > 1 {
+			return nil, "must be given once", nil
+		}
+		value, msg := paramScalar(schemaTypes(schema), values[0])
+		return value, msg, nil
+	}
+
+	items, err := v.resolve(schema["items"])
+	if err != nil {
+		return nil, "", err
+	}
+	if len(values) == 1 {
+		values = strings.Split(values[0], ",")
+	}
+	converted := make([]any, 0, len(values))
+	for _, value := range values {
+		item, msg := paramScalar(schemaTypes(items), value)
+		if msg != "" {
+			return nil, msg, nil
+		}
+		converted = append(converted, item)
+	}
+	return converted, "", nil
+}
+
+func paramScalar(types []string, value string) (any, string) {
+	switch {
+	case slices.Contains(types, "integer"):
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, "must be an integer"
+		}
+		return n, ""
+	case slices.Contains(types, "number"):
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, "must be a number"
+		}
+		return f, ""
+	case slices.Contains(types, "boolean"):
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, "must be a boolean"
+		}
+		return b, ""
+	}
+	return value, ""
+}
+
+// resolve follows the $ref of node, if any, to the object it points to in the document.
+func (v *openAPIValidator) resolve(node any) (map[string]any, error) {
+	for range 32 {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj, nil
+		}
+		target, err := v.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		node = target
+	}
+	return nil, errors.New("too many nested $ref")
+}
+
+// lookup returns the node ref points to. Only references within the document are supported.
+func (v *openAPIValidator) lookup(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q, only references within the document are supported", ref)
+	}
+	pointer, err := url.PathUnescape(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q", ref)
+	}
+
+	var node any = v.doc
+	for token := range strings.SplitSeq(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" && pointer == "" {
+			break
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch n := node.(type) {
+		case map[string]any:
+			if node, ok = n[token]; !ok {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+	}
+	return node, nil
+}
+
+// checkRefs reports a $ref anywhere in node that can't be resolved, so broken documents
+// fail at startup rather than on the first request using them.
+func (v *openAPIValidator) checkRefs(node any) error {
+	switch n := node.(type) {
+	case map[string]any:
+		if ref, ok := n["$ref"].(string); ok {
+			if _, err := v.lookup(ref); err != nil {
+				return err
+			}
+		}
+		for _, child := range n {
+			if err := v.checkRefs(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range n {
+			if err := v.checkRefs(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// serverBasePath returns the path of the document's first server URL, e.g. "/v1" for
+// "https://api.example.com/v1". Server variables in the path are not supported.
+func serverBasePath(doc map[string]any) string {
+	servers, _ := doc["servers"].([]any)
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]any)
+	rawURL, _ := server["url"].(string)
+	u, err := url.Parse(rawURL)
+	if err != nil || strings.Contains(u.Path, "{") {
+		return ""
+	}
+	return strings.TrimRight(u.Path, "/")
+}
+
+func splitOpenAPIPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isPathTemplate(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// normalizeYAML converts the map[any]any that YAML produces for mappings with non-string
+// keys, such as response codes, to map[string]any.
+func normalizeYAML(node any) any {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, value := range n {
+			n[key] = normalizeYAML(value)
+		}
+		return n
+	case map[any]any:
+		m := make(map[string]any, len(n))
+		for key, value := range n {
+			m[fmt.Sprint(key)] = normalizeYAML(value)
+		}
+		return m
+	case []any:
+		for i, value := range n {
+			n[i] = normalizeYAML(value)
+		}
+		return n
+	}
+	return node
+}
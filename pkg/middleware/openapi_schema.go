@@ -0,0 +1,370 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// validateSchema checks value against the OpenAPI schema node, recording a message per
+// invalid field in details under the field's path. Only the first problem of a field is
+// recorded. It returns an error if the schema itself is invalid.
+func (v *openAPIValidator) validateSchema(node, value any, path string, details map[string]string) error {
+	if allowed, ok := node.(bool); ok {
+		// OpenAPI 3.1 allows true and false as schemas.
+		if !allowed {
+			setSchemaDetail(details, path, "is not allowed")
+		}
+		return nil
+	}
+	schema, err := v.resolve(node)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	types := schemaTypes(schema)
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); !nullable && len(types) > 0 && !slices.Contains(types, "null") {
+			setSchemaDetail(details, path, "must not be null")
+		}
+		return nil
+	}
+
+	if err := v.validateComposition(schema, value, path, details); err != nil {
+		return err
+	}
+
+	if len(types) > 0 && !slices.ContainsFunc(types, func(typ string) bool { return hasSchemaType(value, typ) }) {
+		setSchemaDetail(details, path, typeMessage(types))
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return schemaValuesEqual(e, value) }) {
+		allowed := make([]string, len(enum))
+		for i, e := range enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		setSchemaDetail(details, path, "must be one of "+strings.Join(allowed, ", "))
+		return nil
+	}
+
+	switch val := value.(type) {
+	case string:
+		return v.validateString(schema, val, path, details)
+	case []any:
+		return v.validateArray(schema, val, path, details)
+	case map[string]any:
+		return v.validateObject(schema, val, path, details)
+	case bool:
+		return nil
+	}
+	if n, ok := schemaNumber(value); ok {
+		validateNumber(schema, n, path, details)
+	}
+	return nil
+}
+
+func (v *openAPIValidator) validateComposition(schema map[string]any, value any, path string, details map[string]string) error {
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			if err := v.validateSchema(sub, value, path, details); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		subs, ok := schema[keyword].([]any)
+		if !ok {
+			continue
+		}
+		matches := 0
+		for _, sub := range subs {
+			subDetails := map[string]string{}
+			if err := v.validateSchema(sub, value, path, subDetails); err != nil {
+				return err
+			}
+			if len(subDetails) == 0 {
+				matches++
+			}
+		}
+		if keyword == "anyOf" && matches == 0 {
+			setSchemaDetail(details, path, "must match at least one of the allowed schemas")
+		}
+		if keyword == "oneOf" && matches != 1 {
+			setSchemaDetail(details, path, "must match exactly one of the allowed schemas")
+		}
+	}
+	return nil
+}
+
+func (v *openAPIValidator) validateString(schema map[string]any, value, path string, details map[string]string) error {
+	length := utf8.RuneCountInString(value)
+	if minLength, ok := schemaInt(schema["minLength"]); ok && length < minLength {
+		setSchemaDetail(details, path, fmt.Sprintf("must be at least %d characters", minLength))
+		return nil
+	}
+	if maxLength, ok := schemaInt(schema["maxLength"]); ok && length > maxLength {
+		setSchemaDetail(details, path, fmt.Sprintf("must be at most %d characters", maxLength))
+		return nil
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := v.pattern(pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(value) {
+			setSchemaDetail(details, path, "must match the pattern "+pattern)
+			return nil
+		}
+	}
+
+	format, _ := schema["format"].(string)
+	if msg := checkStringFormat(format, value); msg != "" {
+		setSchemaDetail(details, path, msg)
+	}
+	return nil
+}
+
+// checkStringFormat checks the formats with an unambiguous definition. Others are accepted.
+func checkStringFormat(format, value string) string {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse(time.DateOnly, value)
+	case "uuid":
+		_, err = uuid.Parse(value)
+	case "email":
+		var addr *mail.Address
+		if addr, err = mail.ParseAddress(value); err == nil && addr.Address != value {
+			err = fmt.Errorf("not a bare address")
+		}
+	default:
+		return ""
+	}
+	if err != nil {
+		return "must be a valid " + format
+	}
+	return ""
+}
+
+func validateNumber(schema map[string]any, value float64, path string, details map[string]string) {
+	// exclusiveMinimum and exclusiveMaximum are flags in OpenAPI 3.0 and bounds in 3.1.
+	if minimum, ok := schemaNumber(schema["minimum"]); ok {
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && value <= minimum {
+			setSchemaDetail(details, path, "must be greater than "+formatSchemaNumber(minimum))
+			return
+		} else if value < minimum {
+			setSchemaDetail(details, path, "must be at least "+formatSchemaNumber(minimum))
+			return
+		}
+	}
+	if bound, ok := schemaNumber(schema["exclusiveMinimum"]); ok && value <= bound {
+		setSchemaDetail(details, path, "must be greater than "+formatSchemaNumber(bound))
+		return
+	}
+	if maximum, ok := schemaNumber(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && value >= maximum {
+			setSchemaDetail(details, path, "must be less than "+formatSchemaNumber(maximum))
+			return
+		} else if value > maximum {
+			setSchemaDetail(details, path, "must be at most "+formatSchemaNumber(maximum))
+			return
+		}
+	}
+	if bound, ok := schemaNumber(schema["exclusiveMaximum"]); ok && value >= bound {
+		setSchemaDetail(details, path, "must be less than "+formatSchemaNumber(bound))
+	}
+}
+
+func (v *openAPIValidator) validateArray(schema map[string]any, value []any, path string, details map[string]string) error {
+	if minItems, ok := schemaInt(schema["minItems"]); ok && len(value) < minItems {
+		setSchemaDetail(details, path, fmt.Sprintf("must have at least %d items", minItems))
+		return nil
+	}
+	if maxItems, ok := schemaInt(schema["maxItems"]); ok && len(value) > maxItems {
+		setSchemaDetail(details, path, fmt.Sprintf("must have at most %d items", maxItems))
+		return nil
+	}
+
+	items, ok := schema["items"]
+	if !ok {
+		return nil
+	}
+	for i, item := range value {
+		if err := v.validateSchema(items, item, path+"["+strconv.Itoa(i)+"]", details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *openAPIValidator) validateObject(schema map[string]any, value map[string]any, path string, details map[string]string) error {
+	properties, _ := schema["properties"].(map[string]any)
+
+	required, _ := schema["required"].([]any)
+	for _, r := range required {
+		name, _ := r.(string)
+		if _, ok := value[name]; ok {
+			continue
+		}
+		// Read-only properties are only required in responses.
+		property, err := v.resolve(properties[name])
+		if err != nil {
+			return err
+		}
+		if readOnly, _ := property["readOnly"].(bool); !readOnly {
+			setSchemaDetail(details, schemaChildPath(path, name), "is required")
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(value)) {
+		if property, ok := properties[name]; ok {
+			if err := v.validateSchema(property, value[name], schemaChildPath(path, name), details); err != nil {
+				return err
+			}
+			continue
+		}
+		if additional, ok := schema["additionalProperties"]; ok {
+			if err := v.validateSchema(additional, value[name], schemaChildPath(path, name), details); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *openAPIValidator) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema pattern %q: %w", pattern, err)
+	}
+	v.patterns.Store(pattern, re)
+	return re, nil
+}
+
+// schemaTypes returns the types a schema allows; OpenAPI 3.1 allows a list of types.
+func schemaTypes(schema map[string]any) []string {
+	switch typ := schema["type"].(type) {
+	case string:
+		return []string{typ}
+	case []any:
+		types := make([]string, 0, len(typ))
+		for _, t := range typ {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func hasSchemaType(value any, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "number":
+		_, ok := schemaNumber(value)
+		return ok
+	case "integer":
+		n, ok := schemaNumber(value)
+		return ok && n == math.Trunc(n)
+	}
+	return false
+}
+
+func typeMessage(types []string) string {
+	articles := make([]string, 0, len(types))
+	for _, typ := range types {
+		switch typ {
+		case "null":
+			continue
+		case "integer", "array", "object":
+			articles = append(articles, "an "+typ)
+		default:
+			articles = append(articles, "a "+typ)
+		}
+	}
+	return "must be " + strings.Join(articles, " or ")
+}
+
+// schemaNumber converts the numbers found in decoded JSON, converted parameters and
+// YAML documents to float64.
+func schemaNumber(value any) (float64, bool) {
+	switch n := value.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func schemaInt(value any) (int, bool) {
+	n, ok := schemaNumber(value)
+	return int(n), ok
+}
+
+func schemaValuesEqual(a, b any) bool {
+	if x, ok := schemaNumber(a); ok {
+		y, ok := schemaNumber(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func formatSchemaNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func schemaChildPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// setSchemaDetail records msg for the field at path, keyed "body" for the body itself.
+func setSchemaDetail(details map[string]string, path, msg string) {
+	if path == "" {
+		path = "body"
+	}
+	if _, exists := details[path]; !exists {
+		details[path] = msg
+	}
+}
@@ -0,0 +1,333 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPISpec = `
+openapi: 3.0.3
+info:
+  title: Orders
+  version: "1"
+servers:
+  - url: https://api.example.com/api
+paths:
+  /orders:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+            minimum: 1
+            maximum: 100
+        - name: status
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+              enum: [open, closed]
+    post:
+      parameters:
+        - name: X-Tenant
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Order"
+  /orders/{id}:
+    parameters:
+      - name: id
+        in: path
+        schema:
+          type: integer
+    get: {}
+    delete: {}
+  /orders/latest:
+    get: {}
+components:
+  schemas:
+    Order:
+      type: object
+      additionalProperties: false
+      required: [email, items]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        email:
+          type: string
+          format: email
+        note:
+          type: string
+          nullable: true
+          maxLength: 5
+        items:
+          type: array
+          minItems: 1
+          items:
+            $ref: "#/components/schemas/Item"
+    Item:
+      type: object
+      required: [sku, quantity]
+      properties:
+        sku:
+          type: string
+          pattern: "^[A-Z]{3}-[0-9]+$"
+        quantity:
+          type: integer
+          minimum: 1
+`
+
+type openAPIErrorResponse struct {
+	Errors []struct {
+		ErrorCode ErrorCode `json:"errorCode"`
+		Detail    any       `json:"detail"`
+	} `json:"errors"`
+}
+
+func TestOpenAPIValidationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	var handledBody string
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(), mp.NewOpenAPIValidationMiddleware([]byte(testOpenAPISpec)))
+	r.GET("/api/orders", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.POST("/api/orders", func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		handledBody = string(body)
+		ctx.Status(http.StatusCreated)
+	})
+	r.GET("/api/orders/:id", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.GET("/api/health", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	serve := func(method, target, contentType, body string) (*httptest.ResponseRecorder, openAPIErrorResponse) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("X-Tenant", "acme")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp openAPIErrorResponse
+		if w.Code >= http.StatusBadRequest {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Errors, 1)
+		}
+		return w, resp
+	}
+
+	t.Run("valid requests pass through", func(t *testing.T) {
+		w, _ := serve("GET", "/api/orders?limit=10&status=open,closed", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w, _ = serve("GET", "/api/orders/latest", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		body := `{"email":"a@example.com","note":null,"items":[{"sku":"ABC-1","quantity":2}]}`
+		w, _ = serve("POST", "/api/orders", "application/json", body)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, body, handledBody, "handler can still read the body")
+	})
+
+	t.Run("undocumented routes are not checked", func(t *testing.T) {
+		w, _ := serve("GET", "/api/health", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		w, resp := serve("GET", "/api/orders?limit=500&status=open&status=pending", "", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, ErrorCodeValidationFailed, resp.Errors[0].ErrorCode)
+		assert.Equal(t, map[string]any{
+			"limit":     "must be at most 100",
+			"status[1]": "must be one of open, closed",
+		}, resp.Errors[0].Detail)
+
+		w, resp = serve("GET", "/api/orders", "", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, map[string]any{"limit": "is required"}, resp.Errors[0].Detail)
+
+		w, resp = serve("GET", "/api/orders/abc", "", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, map[string]any{"id": "must be an integer"}, resp.Errors[0].Detail)
+	})
+
+	t.Run("invalid body fields", func(t *testing.T) {
+		body := `{"email":"nope","note":"too long","coupon":"X","items":[{"sku":"abc","quantity":0},{"quantity":"2"}]}`
+		w, resp := serve("POST", "/api/orders", "application/json", body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, ErrorCodeValidationFailed, resp.Errors[0].ErrorCode)
+		assert.Equal(t, map[string]any{
+			"email":             "must be a valid email",
+			"note":              "must be at most 5 characters",
+			"coupon":            "is not allowed",
+			"items[0].sku":      "must match the pattern ^[A-Z]{3}-[0-9]+$",
+			"items[0].quantity": "must be at least 1",
+			"items[1].sku":      "is required",
+			"items[1].quantity": "must be an integer",
+		}, resp.Errors[0].Detail)
+	})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		w, resp := serve("POST", "/api/orders", "application/json", `{"email":`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorCodeInvalidJSON, resp.Errors[0].ErrorCode)
+
+		w, resp = serve("POST", "/api/orders", "application/json", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrorCodeMissingBody, resp.Errors[0].ErrorCode)
+
+		w, _ = serve("POST", "/api/orders", "text/plain", "hello")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, resp = serve("POST", "/api/orders", "application/json", `[]`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, map[string]any{"body": "must be an object"}, resp.Errors[0].Detail)
+	})
+}
+
+func TestOpenAPIMaxBodyBytes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(), mp.NewOpenAPIValidationMiddleware([]byte(testOpenAPISpec), WithOpenAPIMaxBodyBytes(64)))
+	r.POST("/api/orders", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+
+	body := `{"email":"a@example.com","items":[{"sku":"ABC-1","quantity":2}],"note":"` + strings.Repeat("x", 64) + `"}`
+	serve := func(req *http.Request) (*httptest.ResponseRecorder, openAPIErrorResponse) {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant", "acme")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp openAPIErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Errors, 1)
+		return w, resp
+	}
+
+	t.Run("declared length", func(t *testing.T) {
+		w, resp := serve(httptest.NewRequest("POST", "/api/orders", strings.NewReader(body)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, ErrorCodePayloadTooLarge, resp.Errors[0].ErrorCode)
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/orders", io.MultiReader(strings.NewReader(body)))
+		req.ContentLength = -1
+		w, resp := serve(req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, ErrorCodePayloadTooLarge, resp.Errors[0].ErrorCode)
+	})
+}
+
+func TestOpenAPIBasePath(t *testing.T) {
+	v, err := newOpenAPIValidator([]byte(testOpenAPISpec), openAPIConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "/api", v.basePath)
+
+	_, _, ok := v.match("GET", "/apiorders")
+	assert.False(t, ok)
+	_, params, ok := v.match("GET", "/api/orders/7")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "7"}, params)
+
+	_, params, ok = v.match("GET", "/api/orders/latest")
+	assert.True(t, ok)
+	assert.Empty(t, params)
+	_, params, ok = v.match("DELETE", "/api/orders/latest")
+	assert.True(t, ok, "/orders/latest has no DELETE, so /orders/{id} matches")
+	assert.Equal(t, map[string]string{"id": "latest"}, params)
+	_, _, ok = v.match("PUT", "/api/orders/latest")
+	assert.False(t, ok)
+
+	cfg := openAPIConfig{}
+	WithOpenAPIBasePath("/v2/")(&cfg)
+	v, err = newOpenAPIValidator([]byte(testOpenAPISpec), cfg)
+	require.NoError(t, err)
+	_, _, ok = v.match("GET", "/v2/orders/latest")
+	assert.True(t, ok)
+}
+
+func TestOpenAPISchemaKeywords(t *testing.T) {
+	v, err := newOpenAPIValidator([]byte(`{"openapi": "3.1.0", "paths": {}}`), openAPIConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		detail string
+	}{
+		{"type list with null", `{"type": ["string", "null"]}`, `null`, ""},
+		{"type list", `{"type": ["string", "integer"]}`, `true`, "must be a string or an integer"},
+		{"not nullable", `{"type": "string"}`, `null`, "must not be null"},
+		{"exclusive bound", `{"type": "number", "exclusiveMinimum": 0}`, `0`, "must be greater than 0"},
+		{"integral number", `{"type": "integer"}`, `2.0`, ""},
+		{"uuid", `{"type": "string", "format": "uuid"}`, `"nope"`, "must be a valid uuid"},
+		{"date-time", `{"type": "string", "format": "date-time"}`, `"2024-01-02T03:04:05Z"`, ""},
+		{"min items", `{"type": "array", "minItems": 2}`, `[1]`, "must have at least 2 items"},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `1.5`, "must match at least one of the allowed schemas"},
+		{"oneOf", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, "must match exactly one of the allowed schemas"},
+		{"allOf", `{"allOf": [{"type": "string"}, {"minLength": 3}]}`, `"ab"`, "must be at least 3 characters"},
+		{"false schema", `false`, `1`, "is not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema, value any
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+			decoder := json.NewDecoder(strings.NewReader(tt.value))
+			decoder.UseNumber()
+			require.NoError(t, decoder.Decode(&value))
+
+			details := map[string]string{}
+			require.NoError(t, v.validateSchema(schema, value, "", details))
+			if tt.detail == "" {
+				assert.Empty(t, details)
+			} else {
+				assert.Equal(t, map[string]string{"body": tt.detail}, details)
+			}
+		})
+	}
+}
+
+func TestNewOpenAPIValidatorErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"not an object", `[]`, "document must be an object"},
+		{"swagger 2", `{"swagger": "2.0"}`, "only OpenAPI 3 documents are supported"},
+		{"missing ref", `{"openapi": "3.0.0", "paths": {"/a": {"$ref": "#/missing"}}}`, `$ref "#/missing" not found`},
+		{"external ref", `{"openapi": "3.0.0", "paths": {"/a": {"$ref": "other.yaml#/a"}}}`, "unsupported $ref"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newOpenAPIValidator([]byte(tt.spec), openAPIConfig{})
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
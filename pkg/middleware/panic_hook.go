@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PanicInfo describes a panic recovered by the error middleware.
+type PanicInfo struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack string
+	// Method, Path and Route identify the request. Route is empty for unmatched routes.
+	Method, Path, Route string
+	// Handler is the name of the last handler in the chain.
+	Handler string
+	// RequestID is the request ID set by NewRequestIDMiddleware, if registered.
+	RequestID string
+}
+
+// PanicHook is called by the error middleware for every recovered panic, e.g. to page
+// the on-call engineer or count panics per route. Like an ErrorReporter it runs on the
+// request goroutine before the error response is written, so it should not block.
+type PanicHook func(ctx *gin.Context, info PanicInfo)
+
+// WithPanicHook adds hook to the hooks called for recovered panics, in registration order.
+// A hook that panics itself is recovered and logged without affecting the other hooks.
+func WithPanicHook(hook PanicHook) ErrorOption {
+	return func(em *errorMiddleware) {
+		if hook != nil {
+			em.panicHooks = append(em.panicHooks, hook)
+		}
+	}
+}
+
+func (em *errorMiddleware) runPanicHooks(ctx *gin.Context, r any, stack stackTrace) {
+	if len(em.panicHooks) == 0 {
+		return
+	}
+
+	info := PanicInfo{
+		Value:     r,
+		Stack:     stack.String(),
+		Method:    ctx.Request.Method,
+		Path:      ctx.Request.URL.Path,
+		Route:     ctx.FullPath(),
+		Handler:   ctx.HandlerName(),
+		RequestID: RequestID(ctx),
+	}
+	for _, hook := range em.panicHooks {
+		em.runPanicHook(ctx, hook, info)
+	}
+}
+
+func (em *errorMiddleware) runPanicHook(ctx *gin.Context, hook PanicHook, info PanicInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			em.requestLogger(ctx).
+				WithField("panic.value", fmt.Sprintf("%v", r)).
+				Error("panic hook panicked")
+		}
+	}()
+	hook(ctx, info)
+}
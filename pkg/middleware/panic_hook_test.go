@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPanicHook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	var infos []PanicInfo
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(
+		WithPanicHook(func(ctx *gin.Context, info PanicInfo) {
+			panic("hook failed")
+		}),
+		WithPanicHook(func(ctx *gin.Context, info PanicInfo) {
+			infos = append(infos, info)
+		}),
+	))
+	r.Use(mp.NewRequestIDMiddleware())
+	r.GET("/orders/:id", func(ctx *gin.Context) {
+		panic("oops")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/orders/7", nil)
+	req.Header.Set(headerRequestID, "req-1")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	if assert.Len(t, infos, 1) {
+		info := infos[0]
+		assert.Equal(t, "oops", info.Value)
+		assert.Contains(t, info.Stack, "runtime/debug.Stack")
+		assert.Equal(t, "GET", info.Method)
+		assert.Equal(t, "/orders/7", info.Path)
+		assert.Equal(t, "/orders/:id", info.Route)
+		assert.Equal(t, "req-1", info.RequestID)
+		assert.NotEmpty(t, info.Handler)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// pathPatterns are request matchers such as "/healthz", "GET /metrics" or "/public/*":
+// a path.Match pattern against the request path, optionally prefixed with an HTTP method.
+type pathPatterns []pathPattern
+
+type pathPattern struct {
+	method  string
+	pattern string
+}
+
+func compilePathPatterns(patterns []string) pathPatterns {
+	compiled := make(pathPatterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		method, p, found := strings.Cut(strings.TrimSpace(pattern), " ")
+		if !found {
+			method, p = "", method
+		}
+		p = strings.TrimSpace(p)
+		if _, err := path.Match(p, ""); err != nil {
+			log.Fatalf("invalid path pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, pathPattern{
+			method:  strings.ToUpper(method),
+			pattern: p,
+		})
+	}
+	return compiled
+}
+
+func (pp pathPatterns) match(req *http.Request) bool {
+	for _, p := range pp {
+		if p.method != "" && p.method != req.Method {
+			continue
+		}
+		if ok, _ := path.Match(p.pattern, req.URL.Path); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/itsLeonB/ungerr"
@@ -11,28 +15,70 @@ import (
 // It retrieves the user role from context using the provided roleContextKey,
 // checks if the role exists in permissionMap and includes the requiredPermission,
 // and aborts the request with a ForbiddenError if permission is missing.
+// If roleContextKey is empty, the roles of the current Principal are used instead,
+// and the request is allowed if any of them grants the permission.
+// Granted permissions may contain wildcards, see WithRoleHierarchy for role inheritance
+// and WithPermissionProvider to load permissions at runtime instead of from permissionMap.
 // Returns a Gin HandlerFunc for permission enforcement.
 func (mp *MiddlewareProvider) NewPermissionMiddleware(
 	roleContextKey string,
 	requiredPermission string,
 	permissionMap map[string][]string,
+	opts ...PermissionOption,
 ) gin.HandlerFunc {
+	return mp.NewPermissionRequirementMiddleware(roleContextKey, RequireAll(requiredPermission), permissionMap, opts...)
+}
+
+// NewPermissionRequirementMiddleware is like NewPermissionMiddleware but lets a route require
+// several permissions, e.g. RequireAny("report:read", "admin") or RequireAll("report:read", "report:export").
+// With several roles, their combined permissions must satisfy the requirement.
+func (mp *MiddlewareProvider) NewPermissionRequirementMiddleware(
+	roleContextKey string,
+	requirement PermissionRequirement,
+	permissionMap map[string][]string,
+	opts ...PermissionOption,
+) gin.HandlerFunc {
+	if len(requirement.permissions) == 0 {
+		mp.logger.Fatal("at least one permission is required")
+	}
+
+	var cfg permissionConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	lookup := staticPermissionLookup(compilePermissions(permissionMap, cfg.hierarchy))
+	if cfg.provider != nil {
+		lookup = providerPermissionLookup(cfg.provider)
+	}
+
 	return func(ctx *gin.Context) {
-		role := ctx.GetString(roleContextKey)
-		if role == "" {
+		roleNames := rolesFromContext(ctx, roleContextKey)
+		if len(roleNames) == 0 {
 			_ = ctx.Error(ungerr.Unknownf("role not found in context or invalid type"))
 			ctx.Abort()
 			return
 		}
 
-		permissions, ok := permissionMap[role]
-		if !ok {
-			_ = ctx.Error(ungerr.Unknownf("unknown role: %s", role))
+		granted := make([]*permissionSet, 0, len(roleNames))
+		for _, role := range roleNames {
+			permissions, ok, err := lookup(ctx, role)
+			if err != nil {
+				_ = ctx.Error(ungerr.Wrap(err, "error getting role permissions"))
+				ctx.Abort()
+				return
+			}
+			if ok {
+				granted = append(granted, permissions)
+			}
+		}
+
+		if len(granted) == 0 {
+			_ = ctx.Error(ungerr.Unknownf("unknown role: %s", strings.Join(roleNames, ", ")))
 			ctx.Abort()
 			return
 		}
 
-		if !slices.Contains(permissions, requiredPermission) {
+		if !requirement.satisfiedBy(granted) {
 			_ = ctx.Error(ungerr.ForbiddenError("no permission"))
 			ctx.Abort()
 			return
@@ -41,3 +87,249 @@ func (mp *MiddlewareProvider) NewPermissionMiddleware(
 		ctx.Next()
 	}
 }
+
+// PermissionRequirement describes the permissions a route needs, see RequireAll and RequireAny.
+type PermissionRequirement struct {
+	permissions []string
+	anyOf       bool
+}
+
+// RequireAll requires every one of permissions.
+func RequireAll(permissions ...string) PermissionRequirement {
+	return PermissionRequirement{permissions: permissions}
+}
+
+// RequireAny requires at least one of permissions.
+func RequireAny(permissions ...string) PermissionRequirement {
+	return PermissionRequirement{permissions: permissions, anyOf: true}
+}
+
+func (pr PermissionRequirement) satisfiedBy(granted []*permissionSet) bool {
+	isGranted := func(permission string) bool {
+		return slices.ContainsFunc(granted, func(ps *permissionSet) bool {
+			return ps.grants(permission)
+		})
+	}
+	if pr.anyOf {
+		return slices.ContainsFunc(pr.permissions, isGranted)
+	}
+	return !slices.ContainsFunc(pr.permissions, func(permission string) bool {
+		return !isGranted(permission)
+	})
+}
+
+// PermissionOption configures optional behaviour of NewPermissionMiddleware.
+type PermissionOption func(*permissionConfig)
+
+type permissionConfig struct {
+	hierarchy map[string][]string
+	provider  PermissionProvider
+}
+
+// PermissionProvider loads the permissions granted to a role, e.g. from a database
+// or a remote service, so they can change without redeploying.
+// Returning an empty list for a role denies it every permission.
+type PermissionProvider interface {
+	GetPermissions(ctx context.Context, role string) ([]string, error)
+}
+
+// WithPermissionProvider makes the middleware ask provider for role permissions on every
+// request; permissionMap and WithRoleHierarchy are then ignored. Wrap slow providers with
+// NewCachedPermissionProvider.
+func WithPermissionProvider(provider PermissionProvider) PermissionOption {
+	return func(cfg *permissionConfig) {
+		cfg.provider = provider
+	}
+}
+
+// WithRoleHierarchy makes roles inherit the permissions of other roles,
+// e.g. {"admin": {"editor"}, "editor": {"viewer"}} gives admin everything editor and viewer have.
+// Inheritance is resolved once when the middleware is created.
+func WithRoleHierarchy(hierarchy map[string][]string) PermissionOption {
+	return func(cfg *permissionConfig) {
+		cfg.hierarchy = hierarchy
+	}
+}
+
+type permissionLookup func(ctx context.Context, role string) (*permissionSet, bool, error)
+
+func staticPermissionLookup(roles map[string]*permissionSet) permissionLookup {
+	return func(_ context.Context, role string) (*permissionSet, bool, error) {
+		permissions, ok := roles[role]
+		return permissions, ok, nil
+	}
+}
+
+func providerPermissionLookup(provider PermissionProvider) permissionLookup {
+	return func(ctx context.Context, role string) (*permissionSet, bool, error) {
+		granted, err := provider.GetPermissions(ctx, role)
+		if err != nil {
+			return nil, false, err
+		}
+		return newPermissionSet(granted), true, nil
+	}
+}
+
+// permissionSet holds the compiled permissions of a role. Permissions are
+// colon-separated segments; a "*" segment matches any single segment, and a
+// trailing "*" matches all remaining segments, so "users:*" grants "users:read"
+// and "users:profile:write", and "*" grants everything.
+type permissionSet struct {
+	exact    map[string]struct{}
+	patterns [][]string
+}
+
+func newPermissionSet(permissions []string) *permissionSet {
+	ps := &permissionSet{exact: make(map[string]struct{}, len(permissions))}
+	for _, permission := range permissions {
+		ps.add(permission)
+	}
+	return ps
+}
+
+func (ps *permissionSet) add(permission string) {
+	if !strings.Contains(permission, "*") {
+		ps.exact[permission] = struct{}{}
+		return
+	}
+	ps.patterns = append(ps.patterns, strings.Split(permission, ":"))
+}
+
+func (ps *permissionSet) grants(permission string) bool {
+	if _, ok := ps.exact[permission]; ok {
+		return true
+	}
+	if len(ps.patterns) == 0 {
+		return false
+	}
+
+	segments := strings.Split(permission, ":")
+	for _, pattern := range ps.patterns {
+		if matchPermission(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPermission(pattern, segments []string) bool {
+	for i, part := range pattern {
+		if i >= len(segments) {
+			return false
+		}
+		if part == "*" && i == len(pattern)-1 {
+			return true
+		}
+		if part != "*" && part != segments[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+// compilePermissions flattens role inheritance and splits wildcard patterns
+// from exact permissions so checks don't re-parse the map on every request.
+func compilePermissions(permissionMap, hierarchy map[string][]string) map[string]*permissionSet {
+	roles := make(map[string]*permissionSet, len(permissionMap)+len(hierarchy))
+
+	var collect func(ps *permissionSet, role string, seen map[string]bool)
+	collect = func(ps *permissionSet, role string, seen map[string]bool) {
+		if seen[role] {
+			return // tolerate inheritance cycles
+		}
+		seen[role] = true
+		for _, permission := range permissionMap[role] {
+			ps.add(permission)
+		}
+		for _, parent := range hierarchy[role] {
+			collect(ps, parent, seen)
+		}
+	}
+
+	for _, source := range []map[string][]string{permissionMap, hierarchy} {
+		for role := range source {
+			if _, ok := roles[role]; ok {
+				continue
+			}
+			ps := newPermissionSet(nil)
+			collect(ps, role, map[string]bool{})
+			roles[role] = ps
+		}
+	}
+
+	return roles
+}
+
+func rolesFromContext(ctx *gin.Context, roleContextKey string) []string {
+	if roleContextKey != "" {
+		if role := ctx.GetString(roleContextKey); role != "" {
+			return []string{role}
+		}
+		return nil
+	}
+
+	if principal, ok := CurrentPrincipal(ctx); ok {
+		return principal.Roles()
+	}
+	return nil
+}
+
+// CachedPermissionProvider caches the permissions returned by another PermissionProvider
+// for a fixed TTL, so changes are picked up without querying the source on every request.
+type CachedPermissionProvider struct {
+	provider PermissionProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]cachedPermissions
+}
+
+type cachedPermissions struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// NewCachedPermissionProvider wraps provider with a cache whose entries expire after ttl.
+func NewCachedPermissionProvider(provider PermissionProvider, ttl time.Duration) *CachedPermissionProvider {
+	return &CachedPermissionProvider{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  map[string]cachedPermissions{},
+	}
+}
+
+func (cpp *CachedPermissionProvider) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	cpp.mu.RLock()
+	entry, ok := cpp.entries[role]
+	cpp.mu.RUnlock()
+	if ok && cpp.now().Before(entry.expiresAt) {
+		return entry.permissions, nil
+	}
+
+	permissions, err := cpp.provider.GetPermissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	cpp.mu.Lock()
+	cpp.entries[role] = cachedPermissions{permissions, cpp.now().Add(cpp.ttl)}
+	cpp.mu.Unlock()
+
+	return permissions, nil
+}
+
+// Invalidate drops the cached permissions of roles, or of every role if none are given.
+func (cpp *CachedPermissionProvider) Invalidate(roles ...string) {
+	cpp.mu.Lock()
+	defer cpp.mu.Unlock()
+
+	if len(roles) == 0 {
+		clear(cpp.entries)
+		return
+	}
+	for _, role := range roles {
+		delete(cpp.entries, role)
+	}
+}
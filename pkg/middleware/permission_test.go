@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/itsLeonB/ezutil/v2/simple"
@@ -65,4 +69,190 @@ func TestNewPermissionMiddleware(t *testing.T) {
 
 		assert.True(t, c.IsAborted())
 	})
+
+	t.Run("principal roles", func(t *testing.T) {
+		mw := mp.NewPermissionMiddleware("", "write", permissionMap)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", nil)
+		SetPrincipal(c, BasicPrincipal{ID: "1", RoleNames: []string{"user", "admin"}})
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("wildcards and hierarchy", func(t *testing.T) {
+		permissionMap := map[string][]string{
+			"viewer": {"reports:read"},
+			"editor": {"users:*"},
+			"root":   {"*"},
+		}
+		hierarchy := map[string][]string{
+			"admin":  {"editor"},
+			"editor": {"viewer"},
+		}
+
+		cases := []struct {
+			role       string
+			permission string
+			allowed    bool
+		}{
+			{"editor", "users:read", true},
+			{"editor", "users:profile:write", true},
+			{"editor", "users", false},
+			{"editor", "reports:read", true},
+			{"viewer", "users:read", false},
+			{"admin", "users:delete", true},
+			{"admin", "reports:read", true},
+			{"admin", "billing:read", false},
+			{"root", "billing:read", true},
+		}
+		for _, tc := range cases {
+			mw := mp.NewPermissionMiddleware("role", tc.permission, permissionMap, WithRoleHierarchy(hierarchy))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/", nil)
+			c.Set("role", tc.role)
+
+			mw(c)
+
+			assert.Equal(t, !tc.allowed, c.IsAborted(), tc.role+" "+tc.permission)
+		}
+	})
+}
+
+func TestNewPermissionRequirementMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	permissionMap := map[string][]string{
+		"analyst":  {"report:read"},
+		"exporter": {"report:export"},
+	}
+
+	cases := []struct {
+		name        string
+		requirement PermissionRequirement
+		roles       []string
+		allowed     bool
+	}{
+		{"any granted", RequireAny("report:read", "admin"), []string{"analyst"}, true},
+		{"any missing", RequireAny("report:delete", "admin"), []string{"analyst"}, false},
+		{"all granted", RequireAll("report:read", "report:export"), []string{"analyst", "exporter"}, true},
+		{"all partially granted", RequireAll("report:read", "report:export"), []string{"analyst"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mw := mp.NewPermissionRequirementMiddleware("", tc.requirement, permissionMap)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			SetPrincipal(c, BasicPrincipal{ID: "1", RoleNames: tc.roles})
+
+			mw(c)
+
+			assert.Equal(t, !tc.allowed, c.IsAborted())
+		})
+	}
+}
+
+type permissionProviderFunc func(ctx context.Context, role string) ([]string, error)
+
+func (f permissionProviderFunc) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	return f(ctx, role)
+}
+
+func TestPermissionProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	calls := 0
+	grants := map[string][]string{"editor": {"posts:*"}}
+	provider := permissionProviderFunc(func(ctx context.Context, role string) ([]string, error) {
+		calls++
+		if role == "broken" {
+			return nil, errors.New("db down")
+		}
+		return grants[role], nil
+	})
+	cached := NewCachedPermissionProvider(provider, time.Minute)
+	mw := mp.NewPermissionMiddleware("role", "posts:publish", nil, WithPermissionProvider(cached))
+
+	run := func(role string) *gin.Context {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", nil)
+		c.Set("role", role)
+		mw(c)
+		return c
+	}
+
+	t.Run("grants from provider", func(t *testing.T) {
+		assert.False(t, run("editor").IsAborted())
+		assert.False(t, run("editor").IsAborted())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("role without permissions", func(t *testing.T) {
+		c := run("viewer")
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		c := run("broken")
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		grants["editor"] = []string{"posts:read"}
+		assert.False(t, run("editor").IsAborted())
+
+		cached.Invalidate("editor")
+		assert.True(t, run("editor").IsAborted())
+	})
+}
+
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		pattern    string
+		permission string
+		match      bool
+	}{
+		{"*", "anything:at:all", true},
+		{"users:*", "users:read", true},
+		{"users:*", "orders:read", false},
+		{"*:read", "users:read", true},
+		{"*:read", "users:write", false},
+		{"*:read", "users:profile:read", false},
+		{"users:*:read", "users:profile:read", true},
+	}
+	for _, tc := range cases {
+		got := matchPermission(strings.Split(tc.pattern, ":"), strings.Split(tc.permission, ":"))
+		assert.Equal(t, tc.match, got, tc.pattern+" "+tc.permission)
+	}
+}
+
+func BenchmarkPermissionMiddleware(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("bench", true, 0))
+	mw := mp.NewPermissionMiddleware("role", "users:profile:write", map[string][]string{
+		"admin": {"reports:read", "billing:*", "users:*"},
+	})
+
+	r := gin.New()
+	r.POST("/", func(ctx *gin.Context) { ctx.Set("role", "admin") }, mw, func(ctx *gin.Context) {})
+	req := httptest.NewRequest("POST", "/", nil)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// NewPolicyMiddleware creates an authorization middleware for attribute-based decisions
+// that can't be expressed as role→permission lookups, such as ownership or tenant checks.
+// The request is aborted with a ForbiddenError if policy returns false, or with the
+// returned error if policy fails (return an AppError to control the response).
+func (mp *MiddlewareProvider) NewPolicyMiddleware(policy func(ctx *gin.Context) (bool, error)) gin.HandlerFunc {
+	if policy == nil {
+		mp.logger.Fatal("policy cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		allowed, err := policy(ctx)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+		if !allowed {
+			_ = ctx.Error(ungerr.ForbiddenError("access denied"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPolicyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	// Only the owner of a document may access it.
+	mw := mp.NewPolicyMiddleware(func(ctx *gin.Context) (bool, error) {
+		principal, ok := CurrentPrincipal(ctx)
+		if !ok {
+			return false, ungerr.UnauthorizedError("not authenticated")
+		}
+		return ctx.Query("owner") == principal.Subject(), nil
+	})
+
+	run := func(target string, principal Principal) *gin.Context {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", target, nil)
+		if principal != nil {
+			SetPrincipal(c, principal)
+		}
+		mw(c)
+		return c
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		c := run("/docs/1?owner=123", BasicPrincipal{ID: "123"})
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		c := run("/docs/1?owner=456", BasicPrincipal{ID: "123"})
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusForbidden, c.Errors.Last().Err.(ungerr.AppError).HttpStatus())
+	})
+
+	t.Run("policy error", func(t *testing.T) {
+		c := run("/docs/1?owner=123", nil)
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusUnauthorized, c.Errors.Last().Err.(ungerr.AppError).HttpStatus())
+	})
+}
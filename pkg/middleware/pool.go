@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBufferSize keeps unusually large buffers from being retained by the pool.
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// stackTrace defers converting a captured stack to a string until a logger formats it,
+// so loggers that drop fields don't pay for the copy.
+type stackTrace []byte
+
+func (st stackTrace) String() string {
+	return string(st)
+}
+
+func (st stackTrace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(st))
+}
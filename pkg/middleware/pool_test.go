@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferPool(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("dirty")
+	putBuffer(buf)
+
+	assert.Equal(t, 0, getBuffer().Len())
+}
+
+func TestStackTrace(t *testing.T) {
+	st := stackTrace("goroutine 1 [running]:\n\tmain.go:1")
+
+	assert.Equal(t, "goroutine 1 [running]:\n\tmain.go:1", st.String())
+
+	data, err := json.Marshal(map[string]any{"stack_trace": st})
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), `"goroutine 1 [running]:\n\tmain.go:1"`))
+}
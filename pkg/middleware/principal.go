@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Principal describes the authenticated identity of the current request.
+// Auth strategies populate it with SetPrincipal (NewAuthMiddleware does so automatically),
+// and the permission and scope middlewares read it with CurrentPrincipal, so they work
+// the same way whether the identity came from a JWT, a session or an API key.
+type Principal interface {
+	Subject() string
+	Roles() []string
+	HasScope(scope string) bool
+}
+
+// BasicPrincipal is the default Principal implementation, built from token claims.
+type BasicPrincipal struct {
+	ID        string
+	RoleNames []string
+	Scopes    []string
+	Claims    map[string]any
+	Token     string
+}
+
+func (bp BasicPrincipal) Subject() string {
+	return bp.ID
+}
+
+func (bp BasicPrincipal) Roles() []string {
+	return bp.RoleNames
+}
+
+func (bp BasicPrincipal) HasScope(scope string) bool {
+	return slices.Contains(bp.Scopes, scope)
+}
+
+// newPrincipal builds a BasicPrincipal from the data returned by a token check function.
+// The ID is read from the "sub", "id" or "userID" claim, roles from "roles" or "role",
+// and scopes from "scopes" or "scope" (a slice or a space-delimited string).
+func newPrincipal(token string, claims map[string]any) BasicPrincipal {
+	return BasicPrincipal{
+		ID:        stringClaim(claims, "sub", "id", "userID"),
+		RoleNames: stringSliceClaim(claims, "roles", "role"),
+		Scopes:    stringSliceClaim(claims, "scopes", "scope"),
+		Claims:    claims,
+		Token:     token,
+	}
+}
+
+// SetPrincipal stores the Principal of the current request in the Gin context and the
+// request context. Custom auth strategies call it so downstream middlewares can use
+// CurrentPrincipal.
+func SetPrincipal(ctx *gin.Context, principal Principal) {
+	principalContextKey.Set(ctx, principal)
+	if ctx.Request != nil {
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), principalRequestKey{}, principal))
+	}
+}
+
+// CurrentPrincipal returns the Principal stored by the auth middleware or SetPrincipal.
+// It accepts a *gin.Context or any context derived from the request context, so code
+// such as repositories and gRPC clients can read the identity without depending on Gin.
+// The boolean is false if the request was not authenticated.
+func CurrentPrincipal(ctx context.Context) (Principal, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		return principalContextKey.Get(ginCtx)
+	}
+	principal, ok := ctx.Value(principalRequestKey{}).(Principal)
+	return principal, ok
+}
+
+func stringClaim(claims map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if val, ok := claims[key]; ok && val != nil {
+			return fmt.Sprint(val)
+		}
+	}
+	return ""
+}
+
+func stringSliceClaim(claims map[string]any, keys ...string) []string {
+	for _, key := range keys {
+		val, ok := claims[key]
+		if !ok {
+			continue
+		}
+		switch v := val.(type) {
+		case []string:
+			return v
+		case []any:
+			values := make([]string, 0, len(v))
+			for _, item := range v {
+				values = append(values, fmt.Sprint(item))
+			}
+			return values
+		case string:
+			return strings.Fields(v)
+		}
+	}
+	return nil
+}
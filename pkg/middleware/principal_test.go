@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrincipal(t *testing.T) {
+	t.Run("reads conventional claims", func(t *testing.T) {
+		claims := map[string]any{
+			"sub":   "user-1",
+			"roles": []any{"admin", "editor"},
+			"scope": "users:read users:write",
+		}
+
+		p := newPrincipal("token", claims)
+
+		assert.Equal(t, "user-1", p.Subject())
+		assert.Equal(t, []string{"admin", "editor"}, p.Roles())
+		assert.True(t, p.HasScope("users:read"))
+		assert.False(t, p.HasScope("users:delete"))
+		assert.Equal(t, claims, p.Claims)
+		assert.Equal(t, "token", p.Token)
+	})
+
+	t.Run("single role string", func(t *testing.T) {
+		p := newPrincipal("token", map[string]any{"userID": 7, "role": "admin"})
+
+		assert.Equal(t, "7", p.Subject())
+		assert.Equal(t, []string{"admin"}, p.Roles())
+		assert.Nil(t, p.Scopes)
+	})
+}
+
+func TestCurrentPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	_, ok := CurrentPrincipal(c)
+	assert.False(t, ok)
+
+	SetPrincipal(c, BasicPrincipal{ID: "user-1"})
+	p, ok := CurrentPrincipal(c)
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", p.Subject())
+
+	t.Run("request context", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		_, ok := CurrentPrincipal(c.Request.Context())
+		assert.False(t, ok)
+
+		SetPrincipal(c, BasicPrincipal{ID: "user-1"})
+		p, ok := CurrentPrincipal(c.Request.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "user-1", p.Subject())
+	})
+}
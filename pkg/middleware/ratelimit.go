@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"context"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -10,71 +13,135 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// RateLimitStore keeps the token buckets of the rate limit middleware.
+// The built-in MemoryRateLimitStore suits single instances; RedisRateLimitStore
+// shares limits across a distributed deployment.
+type RateLimitStore interface {
+	// Take consumes one token from the bucket of key, which refills at limit tokens
+	// per second up to burst tokens, and reports the resulting bucket state.
+	Take(ctx context.Context, key string, limit rate.Limit, burst int) (RateLimitResult, error)
+}
+
+// RateLimitResult is the state of a token bucket after a Take.
+type RateLimitResult struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available if the request was not allowed.
+	RetryAfter time.Duration
+}
+
+func newRateLimitResult(allowed bool, tokens float64, limit rate.Limit, burst int) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: max(int(math.Floor(tokens)), 0),
+	}
+	if limit > 0 && limit != rate.Inf {
+		resetSeconds := math.Ceil((float64(burst) - tokens) / float64(limit))
+		result.Reset = time.Duration(resetSeconds) * time.Second
+		if !allowed {
+			retrySeconds := math.Ceil((1 - tokens) / float64(limit))
+			result.RetryAfter = time.Duration(retrySeconds) * time.Second
+		}
+	}
+	return result
+}
+
 type visitor struct {
 	limiter  *rate.Limiter
 	lastSeen time.Time
 }
 
-type rateLimiter struct {
+// MemoryRateLimitStore is an in-process RateLimitStore. Buckets idle for
+// more than 3 minutes are dropped by a background sweep.
+type MemoryRateLimitStore struct {
 	visitors map[string]*visitor
 	mu       sync.Mutex
-	rate     rate.Limit
-	burst    int
 }
 
-func newRateLimiter(r rate.Limit, b int) *rateLimiter {
-	rl := &rateLimiter{
-		visitors: make(map[string]*visitor),
-		rate:     r,
-		burst:    b,
-	}
-	go rl.cleanupVisitors()
-	return rl
+// NewMemoryRateLimitStore creates an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	rls := &MemoryRateLimitStore{visitors: make(map[string]*visitor)}
+	go rls.cleanupVisitors()
+	return rls
+}
+
+func (rls *MemoryRateLimitStore) Take(_ context.Context, key string, limit rate.Limit, burst int) (RateLimitResult, error) {
+	limiter := rls.getVisitor(key, limit, burst)
+
+	now := time.Now()
+	allowed := limiter.AllowN(now, 1)
+	return newRateLimitResult(allowed, limiter.TokensAt(now), limit, burst), nil
 }
 
-func (rl *rateLimiter) getVisitor(ip string) *rate.Limiter {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+func (rls *MemoryRateLimitStore) getVisitor(key string, limit rate.Limit, burst int) *rate.Limiter {
+	rls.mu.Lock()
+	defer rls.mu.Unlock()
 
-	v, exists := rl.visitors[ip]
+	v, exists := rls.visitors[key]
 	if !exists {
-		limiter := rate.NewLimiter(rl.rate, rl.burst)
-		rl.visitors[ip] = &visitor{limiter, time.Now()}
+		limiter := rate.NewLimiter(limit, burst)
+		rls.visitors[key] = &visitor{limiter, time.Now()}
 		return limiter
 	}
 	v.lastSeen = time.Now()
 	return v.limiter
 }
 
-func (rl *rateLimiter) cleanupVisitors() {
+func (rls *MemoryRateLimitStore) cleanupVisitors() {
 	for {
 		time.Sleep(time.Minute)
-		rl.mu.Lock()
-		for ip, v := range rl.visitors {
+		rls.mu.Lock()
+		for key, v := range rls.visitors {
 			if time.Since(v.lastSeen) > 3*time.Minute {
-				delete(rl.visitors, ip)
+				delete(rls.visitors, key)
 			}
 		}
-		rl.mu.Unlock()
+		rls.mu.Unlock()
 	}
 }
 
 // NewRateLimitMiddleware creates a rate limiter middleware for Gin.
-// It limits requests based on the client's IP address using a token bucket algorithm.
+// It limits requests based on the client's IP address using a token bucket algorithm;
+// use WithRateLimitKey to limit by something else, such as the authenticated user,
+// and WithRateLimitStore to share limits between instances.
 // limit: The number of requests per second derived from time.Duration (e.g., 1 request per second).
 // burst: The maximum number of requests allowed to exceed the limit.
-func (mp *MiddlewareProvider) NewRateLimitMiddleware(limit rate.Limit, burst int) gin.HandlerFunc {
-	rl := newRateLimiter(limit, burst)
+// Every response carries X-RateLimit-Limit/Remaining/Reset and the equivalent
+// IETF RateLimit-* headers so clients can throttle themselves, and rejected
+// requests also get Retry-After.
+// If the store fails, the request is let through and the error is logged.
+func (mp *MiddlewareProvider) NewRateLimitMiddleware(limit rate.Limit, burst int, opts ...RateLimitOption) gin.HandlerFunc {
+	cfg := rateLimitConfig{keyFunc: (*gin.Context).ClientIP}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.store == nil {
+		cfg.store = NewMemoryRateLimitStore()
+	}
 
 	return func(ctx *gin.Context) {
-		ip := ctx.ClientIP()
-		limiter := rl.getVisitor(ip)
-
-		if !limiter.Allow() {
-			mp.logger.Warnf("rate limit exceeded for IP: %s", ip)
-			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, response.NewErrorResponse(errorObject{
-				Code:   http.StatusText(http.StatusTooManyRequests),
-				Detail: "rate limit exceeded",
+		key := cfg.keyFunc(ctx)
+		result, err := cfg.store.Take(ctx, key, limit, burst)
+		if err != nil {
+			mp.logger.WithContext(ctx).WithError(err).Error("rate limit store unavailable, allowing request")
+			ctx.Next()
+			return
+		}
+		setRateLimitHeaders(ctx, burst, result)
+
+		if !result.Allowed {
+			if result.RetryAfter > 0 {
+				ctx.Header("Retry-After", strconv.Itoa(int(result.RetryAfter/time.Second)))
+			}
+			mp.logger.Warnf("rate limit exceeded for key: %s", key)
+			mp.metrics.IncCounter(metricRateLimitRejected, nil)
+			response.AbortWithJSON(ctx, http.StatusTooManyRequests, response.NewErrorResponse(errorObject{
+				Code:      http.StatusText(http.StatusTooManyRequests),
+				ErrorCode: ErrorCodeRateLimited,
+				Detail:    "rate limit exceeded",
 			}))
 			return
 		}
@@ -82,3 +149,53 @@ func (mp *MiddlewareProvider) NewRateLimitMiddleware(limit rate.Limit, burst int
 		ctx.Next()
 	}
 }
+
+// RateLimitOption configures optional behaviour of NewRateLimitMiddleware.
+type RateLimitOption func(*rateLimitConfig)
+
+type rateLimitConfig struct {
+	keyFunc func(ctx *gin.Context) string
+	store   RateLimitStore
+}
+
+// WithRateLimitKey sets the function that picks the bucket a request counts against.
+// Defaults to the client IP. For per-user limits behind auth, key by the Principal:
+//
+//	WithRateLimitKey(func(ctx *gin.Context) string {
+//		if p, ok := middleware.CurrentPrincipal(ctx); ok {
+//			return "user:" + p.Subject()
+//		}
+//		return "ip:" + ctx.ClientIP()
+//	})
+func WithRateLimitKey(keyFunc func(ctx *gin.Context) string) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		if keyFunc != nil {
+			cfg.keyFunc = keyFunc
+		}
+	}
+}
+
+// WithRateLimitStore sets where token buckets are kept. Defaults to a new MemoryRateLimitStore.
+// Middlewares sharing a store with different limits must use distinct keys.
+func WithRateLimitStore(store RateLimitStore) RateLimitOption {
+	return func(cfg *rateLimitConfig) {
+		cfg.store = store
+	}
+}
+
+// setRateLimitHeaders writes the bucket state to the response headers.
+func setRateLimitHeaders(ctx *gin.Context, burst int, result RateLimitResult) {
+	resetSeconds := int(result.Reset / time.Second)
+
+	limitStr := strconv.Itoa(burst)
+	remainingStr := strconv.Itoa(result.Remaining)
+	resetStr := strconv.Itoa(resetSeconds)
+
+	header := ctx.Writer.Header()
+	header.Set("X-RateLimit-Limit", limitStr)
+	header.Set("X-RateLimit-Remaining", remainingStr)
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(result.Reset).Unix(), 10))
+	header.Set("RateLimit-Limit", limitStr)
+	header.Set("RateLimit-Remaining", remainingStr)
+	header.Set("RateLimit-Reset", resetStr)
+}
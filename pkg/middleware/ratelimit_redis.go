@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/itsLeonB/ungerr"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+// tokenBucketScript refills and takes from a token bucket stored in a hash, atomically.
+// It uses the Redis server clock so all instances agree on elapsed time.
+// KEYS[1] = bucket key; ARGV[1] = tokens per second; ARGV[2] = burst.
+// Returns {allowed (0/1), remaining tokens as a string}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+if rate > 0 then
+	redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+else
+	redis.call('PEXPIRE', KEYS[1], 86400000)
+end
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimitStore is a RateLimitStore that keeps token buckets in Redis,
+// so every instance of a service enforces the same limits.
+type RedisRateLimitStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRateLimitStore creates a RedisRateLimitStore using client.
+// The prefix, e.g. "myapp:ratelimit:", is prepended to every bucket key.
+func NewRedisRateLimitStore(client redis.UniversalClient, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client, prefix}
+}
+
+func (rrs *RedisRateLimitStore) Take(ctx context.Context, key string, limit rate.Limit, burst int) (RateLimitResult, error) {
+	if limit == rate.Inf {
+		return RateLimitResult{Allowed: true, Remaining: burst}, nil
+	}
+
+	vals, err := tokenBucketScript.Run(ctx, rrs.client, []string{rrs.prefix + key},
+		strconv.FormatFloat(float64(limit), 'f', -1, 64), burst).Slice()
+	if err != nil {
+		return RateLimitResult{}, ungerr.Wrap(err, "error running rate limit script")
+	}
+	if len(vals) != 2 {
+		return RateLimitResult{}, ungerr.Unknownf("unexpected rate limit script result: %v", vals)
+	}
+
+	allowed, _ := vals[0].(int64)
+	tokensStr, _ := vals[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return RateLimitResult{}, ungerr.Wrap(err, "error parsing remaining rate limit tokens")
+	}
+
+	return newRateLimitResult(allowed == 1, tokens, limit, burst), nil
+}
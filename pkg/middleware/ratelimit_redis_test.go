@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+// TestRedisRateLimitStore runs against the Redis server at REDIS_ADDR and is skipped if it is not set.
+func TestRedisRateLimitStore(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer func() { _ = client.Close() }()
+	rls := NewRedisRateLimitStore(client, "ginkgo-test:ratelimit:")
+	key := "bucket-" + time.Now().Format(time.RFC3339Nano)
+
+	for i := range 2 {
+		result, err := rls.Take(ctx, key, rate.Every(time.Minute), 2)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, err := rls.Take(ctx, key, rate.Every(time.Minute), 2)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Positive(t, result.Reset)
+}
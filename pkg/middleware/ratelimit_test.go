@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -31,6 +33,11 @@ func TestNewRateLimitMiddleware(t *testing.T) {
 
 		assert.False(t, c.IsAborted())
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+		assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
 	})
 
 	t.Run("rate limit exceeded", func(t *testing.T) {
@@ -53,6 +60,8 @@ func TestNewRateLimitMiddleware(t *testing.T) {
 
 		assert.True(t, c2.IsAborted())
 		assert.Equal(t, http.StatusTooManyRequests, w2.Code)
+		assert.Equal(t, "0", w2.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", w2.Header().Get("Retry-After"))
 
 		var response map[string]interface{}
 		_ = json.Unmarshal(w2.Body.Bytes(), &response)
@@ -66,4 +75,43 @@ func TestNewRateLimitMiddleware(t *testing.T) {
 			assert.Fail(t, "expected errors in response")
 		}
 	})
+
+	t.Run("custom key", func(t *testing.T) {
+		mw := mp.NewRateLimitMiddleware(rate.Every(time.Second), 1, WithRateLimitKey(func(ctx *gin.Context) string {
+			return ctx.GetHeader("X-Tenant")
+		}))
+
+		run := func(tenant, remoteAddr string) *gin.Context {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			c.Request.RemoteAddr = remoteAddr
+			c.Request.Header.Set("X-Tenant", tenant)
+			mw(c)
+			return c
+		}
+
+		assert.False(t, run("acme", "10.0.0.1:1234").IsAborted())
+		assert.True(t, run("acme", "10.0.0.2:1234").IsAborted(), "same tenant from another IP shares the bucket")
+		assert.False(t, run("globex", "10.0.0.1:1234").IsAborted())
+	})
+
+	t.Run("store error allows request", func(t *testing.T) {
+		mw := mp.NewRateLimitMiddleware(rate.Every(time.Second), 1, WithRateLimitStore(failingRateLimitStore{}))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	})
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, rate.Limit, int) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store down")
 }
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+var (
+	defaultRedactedFields = []string{
+		"password", "new_password", "old_password", "secret", "client_secret",
+		"token", "access_token", "refresh_token", "id_token", "api_key", "apikey",
+	}
+	defaultRedactedHeaders = []string{
+		"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", defaultAPIKeyHeader,
+	}
+)
+
+// redactor masks sensitive headers and body fields before they are logged.
+type redactor struct {
+	fields  map[string]struct{} // lower-cased
+	headers map[string]struct{} // canonical
+	// fieldPattern finds string values of sensitive fields in JSON that can't be decoded,
+	// such as a body truncated by the size cap.
+	fieldPattern *regexp.Regexp
+}
+
+func newRedactor(fields, headers []string) *redactor {
+	r := &redactor{
+		fields:  make(map[string]struct{}, len(fields)),
+		headers: make(map[string]struct{}, len(headers)),
+	}
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		r.fields[strings.ToLower(field)] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	for _, header := range headers {
+		r.headers[textproto.CanonicalMIMEHeaderKey(header)] = struct{}{}
+	}
+	if len(quoted) > 0 {
+		r.fieldPattern = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+	}
+	return r
+}
+
+// redactHeaders returns a copy of header with sensitive values replaced.
+func (r *redactor) redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for key := range redacted {
+		if _, ok := r.headers[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+			redacted[key] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+// redactBody renders body for logging with sensitive fields masked. JSON and form bodies
+// are redacted, other text is logged as is, and binary content is replaced by a placeholder.
+// truncated marks a body cut at the size cap.
+func (r *redactor) redactBody(contentType string, body []byte, truncated bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	var out string
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		out = r.redactJSON(body)
+	case mediaType == "application/x-www-form-urlencoded":
+		out = r.redactForm(body)
+	case mediaType == "" || strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		out = string(body)
+	default:
+		return "[" + strconv.Itoa(len(body)) + " bytes of " + mediaType + "]"
+	}
+
+	if truncated {
+		out += "...(truncated)"
+	}
+	return out
+}
+
+func (r *redactor) redactJSON(body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		if r.fieldPattern == nil {
+			return string(body)
+		}
+		return r.fieldPattern.ReplaceAllString(string(body), `${1}"`+redactedValue+`"`)
+	}
+
+	encoded, err := json.Marshal(r.redactValue(decoded))
+	if err != nil {
+		return string(body)
+	}
+	return string(encoded)
+}
+
+func (r *redactor) redactValue(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, ok := r.fields[strings.ToLower(key)]; ok {
+				v[key] = redactedValue
+			} else {
+				v[key] = r.redactValue(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	}
+	return val
+}
+
+func (r *redactor) redactForm(body []byte) string {
+	pairs := strings.Split(string(body), "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			if _, ok := r.fields[strings.ToLower(name)]; ok {
+				pairs[i] = key + "=" + redactedValue
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
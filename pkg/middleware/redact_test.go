@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor([]string{"password", "api_key"}, []string{"authorization", "Cookie"})
+
+	t.Run("headers", func(t *testing.T) {
+		header := http.Header{
+			"Authorization": {"Bearer abc"},
+			"Cookie":        {"session=1"},
+			"Accept":        {"application/json"},
+		}
+		redacted := r.redactHeaders(header)
+
+		assert.Equal(t, redactedValue, redacted.Get("Authorization"))
+		assert.Equal(t, redactedValue, redacted.Get("Cookie"))
+		assert.Equal(t, "application/json", redacted.Get("Accept"))
+		assert.Equal(t, "Bearer abc", header.Get("Authorization"), "original header is untouched")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		body := `{"user":"ann","Password":"hunter2","keys":[{"api_key":"k1","name":"ci"}],"age":30}`
+		assert.JSONEq(t,
+			`{"user":"ann","Password":"[REDACTED]","keys":[{"api_key":"[REDACTED]","name":"ci"}],"age":30}`,
+			r.redactBody("application/json; charset=utf-8", []byte(body), false))
+	})
+
+	t.Run("truncated json", func(t *testing.T) {
+		body := `{"user":"ann","password": "hun`
+		assert.Equal(t, `{"user":"ann","password": "[REDACTED]"...(truncated)`,
+			r.redactBody("application/json", []byte(body), true))
+	})
+
+	t.Run("form", func(t *testing.T) {
+		assert.Equal(t, "user=ann&password=[REDACTED]&remember=1",
+			r.redactBody("application/x-www-form-urlencoded", []byte("user=ann&password=hunter2&remember=1"), false))
+	})
+
+	t.Run("text and binary", func(t *testing.T) {
+		assert.Equal(t, "hello", r.redactBody("text/plain", []byte("hello"), false))
+		assert.Equal(t, "[3 bytes of image/png]", r.redactBody("image/png", []byte{1, 2, 3}, false))
+		assert.Equal(t, "", r.redactBody("application/json", nil, false))
+	})
+}
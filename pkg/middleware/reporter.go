@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorReporter sends server errors to an error tracker. The error middleware calls it
+// for every 5xx response and recovered panic; see WithErrorReporter.
+// Package sentryreporter provides an implementation for Sentry.
+type ErrorReporter interface {
+	// Report is called with the *gin.Context of the request, the error, and metadata such as
+	// http.method, http.route, http.status_code, http.request_id, enduser.id, handler
+	// and, for panics, stack_trace. It runs on the request goroutine, so it should not block.
+	Report(ctx context.Context, err error, meta map[string]any)
+}
+
+// WithErrorReporter makes the error middleware report server errors and panics to er.
+func WithErrorReporter(er ErrorReporter) ProviderOption {
+	return func(mp *MiddlewareProvider) {
+		mp.reporter = er
+	}
+}
+
+// errorReportMeta collects the request metadata passed to an ErrorReporter.
+func errorReportMeta(ctx *gin.Context, status int) map[string]any {
+	meta := map[string]any{
+		"http.method":      ctx.Request.Method,
+		"http.path":        ctx.Request.URL.Path,
+		"http.status_code": status,
+		"handler":          ctx.HandlerName(),
+	}
+	if route := ctx.FullPath(); route != "" {
+		meta["http.route"] = route
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		meta["http.request_id"] = requestID
+	}
+	if principal, ok := CurrentPrincipal(ctx); ok && principal.Subject() != "" {
+		meta["enduser.id"] = principal.Subject()
+	}
+	return meta
+}
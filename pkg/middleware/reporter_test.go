@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+type report struct {
+	err  error
+	meta map[string]any
+}
+
+type recordingReporter struct {
+	reports []report
+}
+
+func (rr *recordingReporter) Report(_ context.Context, err error, meta map[string]any) {
+	rr.reports = append(rr.reports, report{err, meta})
+}
+
+func TestErrorMiddlewareReporter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	reporter := &recordingReporter{}
+	mp := NewMiddlewareProvider(logger, WithErrorReporter(reporter))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Use(mp.NewRequestIDMiddleware())
+	r.GET("/not-found", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.NotFoundError("missing"))
+	})
+	r.GET("/db/:id", func(ctx *gin.Context) {
+		SetPrincipal(ctx, BasicPrincipal{ID: "user-1"})
+		_ = ctx.Error(ungerr.Wrap(errors.New("connection refused"), "error querying db"))
+	})
+	r.GET("/panic", func(ctx *gin.Context) {
+		panic("oops")
+	})
+
+	for _, path := range []string{"/not-found", "/db/42", "/panic"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(headerRequestID, "req-1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if !assert.Len(t, reporter.reports, 2, "client errors are not reported") {
+		return
+	}
+
+	dbReport := reporter.reports[0]
+	assert.ErrorContains(t, dbReport.err, "error querying db")
+	assert.Equal(t, "/db/:id", dbReport.meta["http.route"])
+	assert.Equal(t, "/db/42", dbReport.meta["http.path"])
+	assert.Equal(t, http.StatusInternalServerError, dbReport.meta["http.status_code"])
+	assert.Equal(t, "req-1", dbReport.meta["http.request_id"])
+	assert.Equal(t, "user-1", dbReport.meta["enduser.id"])
+
+	panicReport := reporter.reports[1]
+	assert.EqualError(t, panicReport.err, "panic: oops")
+	assert.Contains(t, panicReport.meta["stack_trace"], "runtime/debug.Stack")
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type requestIDContextKey struct{}
+
+// maxRequestIDLength bounds incoming request IDs, which end up in every log line.
+const maxRequestIDLength = 128
+
+// NewRequestIDMiddleware creates a middleware that assigns every request an ID:
+// the incoming X-Request-ID header if it is a safe token, or a new UUID otherwise.
+// The ID is echoed in the X-Request-ID response header, is available through RequestID,
+// and is included automatically by the logging, request logger and error middlewares.
+// Register it right after the error middleware so all later middlewares see the ID.
+func (mp *MiddlewareProvider) NewRequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(headerRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), requestIDContextKey{}, requestID))
+		ctx.Header(headerRequestID, requestID)
+
+		ctx.Next()
+	}
+}
+
+// RequestID returns the ID assigned by NewRequestIDMiddleware. It accepts a *gin.Context
+// or any context derived from the request context, and returns "" if the middleware
+// is not registered.
+func RequestID(ctx context.Context) string {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if ginCtx.Request == nil {
+			return ""
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// validRequestID accepts IDs made of letters, digits and "-_.:" so client-supplied
+// values cannot inject content into logs or headers.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	var seen string
+	r := gin.New()
+	r.Use(mp.NewRequestIDMiddleware())
+	r.GET("/", func(ctx *gin.Context) {
+		seen = RequestID(ctx.Request.Context())
+		ctx.Status(http.StatusOK)
+	})
+
+	t.Run("honors incoming id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "abc-123", seen)
+		assert.Equal(t, "abc-123", w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("generates id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		_, err := uuid.Parse(seen)
+		assert.NoError(t, err)
+		assert.Equal(t, seen, w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("replaces unsafe id", func(t *testing.T) {
+		for _, id := range []string{"bad id\nforged=1", strings.Repeat("a", 129)} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-Request-ID", id)
+
+			r.ServeHTTP(w, req)
+
+			assert.NotEqual(t, id, seen)
+			_, err := uuid.Parse(seen)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("without middleware", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		assert.Empty(t, RequestID(c))
+	})
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2"
+	"github.com/itsLeonB/ezutil/v2/simple"
+)
+
+// NewRequestLoggerMiddleware creates a middleware that derives a request-scoped logger
+// from the provider's logger, pre-populated with the request ID, method, path, route and
+// the values stored under identityKeys (e.g. "userID") by preceding middlewares.
+// Register it after the auth middleware so identity fields are available.
+// Handlers retrieve the logger with GetLogger.
+func (mp *MiddlewareProvider) NewRequestLoggerMiddleware(identityKeys ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		loggerContextKey.Set(ctx, mp.requestLogger(ctx, identityKeys))
+
+		ctx.Next()
+	}
+}
+
+func (mp *MiddlewareProvider) requestLogger(ctx *gin.Context, identityKeys []string) ezutil.Logger {
+	fields := map[string]any{
+		"http.method": ctx.Request.Method,
+		"http.path":   ctx.Request.URL.Path,
+		"http.route":  ctx.FullPath(),
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		fields["http.request_id"] = requestID
+	}
+	for _, key := range identityKeys {
+		if val, exists := ctx.Get(key); exists {
+			fields["enduser."+key] = val
+		}
+	}
+
+	return mp.logger.WithContext(ctx.Request.Context()).WithFields(fields)
+}
+
+// GetLogger returns the request-scoped logger stored by NewRequestLoggerMiddleware,
+// or by NewLoggingMiddleware if the former is not registered.
+// If the middleware is not registered, a logger that discards all output is returned,
+// so handlers can always log without nil checks.
+func GetLogger(ctx *gin.Context) ezutil.Logger {
+	if logger, ok := loggerContextKey.Get(ctx); ok && logger != nil {
+		return logger
+	}
+	return discardLogger
+}
+
+var discardLogger ezutil.Logger = simple.NewLogger(packageName, false, 5)
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequestLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	t.Run("stores logger in context", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("X-Request-ID", "req-1")
+		c.Set("userID", "123")
+
+		mp.NewRequestLoggerMiddleware("userID")(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, exists := loggerContextKey.Get(c)
+		assert.True(t, exists)
+		assert.NotNil(t, GetLogger(c))
+	})
+
+	t.Run("fallback without middleware", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		assert.Equal(t, discardLogger, GetLogger(c))
+	})
+}
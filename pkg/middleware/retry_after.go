@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// retryAfterError is an AppError with a Retry-After duration attached.
+type retryAfterError struct {
+	ungerr.AppError
+	after time.Duration
+}
+
+func (re retryAfterError) RetryAfter() time.Duration {
+	return re.after
+}
+
+func (re retryAfterError) Unwrap() error {
+	return re.AppError
+}
+
+// WithRetryAfter attaches a Retry-After duration to appError, e.g.
+//
+//	middleware.WithRetryAfter(middleware.ServiceUnavailableError("payments are down"), time.Minute)
+//
+// The error middleware sends it as a Retry-After header, in whole seconds, when appError
+// is answered with 429 Too Many Requests or 503 Service Unavailable.
+func WithRetryAfter(appError ungerr.AppError, after time.Duration) ungerr.AppError {
+	return retryAfterError{appError, after}
+}
+
+// RetryAfterOf returns the Retry-After duration attached to the first error in err's chain
+// that has one.
+func RetryAfterOf(err error) (time.Duration, bool) {
+	for cause := range errorChain(err) {
+		if ra, ok := cause.(interface{ RetryAfter() time.Duration }); ok {
+			return ra.RetryAfter(), true
+		}
+	}
+	return 0, false
+}
+
+// WithDefaultRetryAfter makes the error middleware send a Retry-After of after with 429 and
+// 503 responses whose AppError has none attached.
+func WithDefaultRetryAfter(after time.Duration) ErrorOption {
+	return func(em *errorMiddleware) {
+		em.retryAfter = after
+	}
+}
+
+// setRetryAfterHeader sets the Retry-After header of 429 and 503 responses to appError.
+func (em *errorMiddleware) setRetryAfterHeader(ctx *gin.Context, appError ungerr.AppError) {
+	status := appError.HttpStatus()
+	if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+		return
+	}
+	after, ok := RetryAfterOf(appError)
+	if !ok {
+		after = em.retryAfter
+	}
+	setRetryAfter(ctx, after)
+}
+
+// setRetryAfter sets a Retry-After header of after in whole seconds, rounded up, if positive.
+func setRetryAfter(ctx *gin.Context, after time.Duration) {
+	if after > 0 {
+		seconds := int((after + time.Second - 1) / time.Second)
+		ctx.Header("Retry-After", strconv.Itoa(seconds))
+	}
+}
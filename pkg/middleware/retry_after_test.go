@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(WithDefaultRetryAfter(30 * time.Second)))
+	r.GET("/throttled", func(ctx *gin.Context) {
+		_ = ctx.Error(WithRetryAfter(TooManyRequestsError("slow down"), 1500*time.Millisecond))
+	})
+	r.GET("/down", func(ctx *gin.Context) {
+		appError := WithRetryAfter(ServiceUnavailableError("payments are down"), time.Minute)
+		_ = ctx.Error(ungerr.Wrap(appError, "error charging card"))
+	})
+	r.GET("/default", func(ctx *gin.Context) {
+		_ = ctx.Error(ServiceUnavailableError("try again later"))
+	})
+	r.GET("/not-found", func(ctx *gin.Context) {
+		_ = ctx.Error(WithRetryAfter(ungerr.NotFoundError("missing"), time.Minute))
+	})
+
+	cases := []struct {
+		path       string
+		status     int
+		retryAfter string
+		errorCode  ErrorCode
+	}{
+		{"/throttled", http.StatusTooManyRequests, "2", ErrorCodeRateLimited},
+		{"/down", http.StatusServiceUnavailable, "60", ErrorCodeServiceUnavailable},
+		{"/default", http.StatusServiceUnavailable, "30", ErrorCodeServiceUnavailable},
+		{"/not-found", http.StatusNotFound, "", ErrorCodeNotFound},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+		assert.Equal(t, tc.status, w.Code, tc.path)
+		assert.Equal(t, tc.retryAfter, w.Header().Get("Retry-After"), tc.path)
+		assert.Contains(t, w.Body.String(), string(tc.errorCode), tc.path)
+	}
+}
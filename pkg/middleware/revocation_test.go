@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreRevocationChecker(t *testing.T) {
+	ctx := context.Background()
+	rc := NewStoreRevocationChecker(store.NewMemoryStore())
+
+	claims := map[string]any{"jti": "token-1"}
+
+	revoked, err := rc.IsRevoked(ctx, "raw", claims)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, rc.Revoke(ctx, "token-1", time.Minute))
+
+	revoked, err = rc.IsRevoked(ctx, "raw", claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestTokenID(t *testing.T) {
+	assert.Equal(t, "abc", TokenID("raw", map[string]any{"jti": "abc"}))
+	assert.Len(t, TokenID("raw", nil), 64)
+}
+
+func TestAuthMiddlewareRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	rc := NewStoreRevocationChecker(store.NewMemoryStore())
+	assert.NoError(t, rc.Revoke(context.Background(), TokenID("revoked-token", nil), time.Minute))
+
+	tokenCheckFunc := func(ctx *gin.Context, token string) (bool, map[string]any, error) {
+		return true, map[string]any{"userID": "123"}, nil
+	}
+	mw := mp.NewAuthMiddleware("Bearer", tokenCheckFunc, WithRevocationChecker(rc))
+
+	t.Run("valid token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer valid-token")
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer revoked-token")
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+}
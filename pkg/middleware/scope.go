@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// NewScopeMiddleware creates a middleware that requires the current Principal to have
+// all of requiredScopes. Scopes come from the "scopes" or "scope" claim, either a slice
+// or a space-delimited string as issued by OAuth2 servers.
+// Requests missing a scope are aborted with a ForbiddenError and an RFC 6750
+// insufficient_scope WWW-Authenticate header.
+func (mp *MiddlewareProvider) NewScopeMiddleware(requiredScopes ...string) gin.HandlerFunc {
+	return mp.newScopeMiddleware(requiredScopes, func(principal Principal) bool {
+		return !slices.ContainsFunc(requiredScopes, func(scope string) bool {
+			return !principal.HasScope(scope)
+		})
+	})
+}
+
+// NewAnyScopeMiddleware is like NewScopeMiddleware but requires only one of scopes.
+func (mp *MiddlewareProvider) NewAnyScopeMiddleware(scopes ...string) gin.HandlerFunc {
+	return mp.newScopeMiddleware(scopes, func(principal Principal) bool {
+		return slices.ContainsFunc(scopes, principal.HasScope)
+	})
+}
+
+func (mp *MiddlewareProvider) newScopeMiddleware(scopes []string, allowed func(Principal) bool) gin.HandlerFunc {
+	if len(scopes) == 0 {
+		mp.logger.Fatal("at least one scope is required")
+	}
+	challenge := `Bearer error="insufficient_scope", scope="` + strings.Join(scopes, " ") + `"`
+
+	return func(ctx *gin.Context) {
+		principal, ok := CurrentPrincipal(ctx)
+		if !ok {
+			_ = ctx.Error(ungerr.Unknownf("principal not found in context"))
+			ctx.Abort()
+			return
+		}
+
+		if !allowed(principal) {
+			ctx.Header("WWW-Authenticate", challenge)
+			_ = ctx.Error(ungerr.ForbiddenError("insufficient scope"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScopeMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	run := func(mw gin.HandlerFunc, principal Principal) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		if principal != nil {
+			SetPrincipal(c, principal)
+		}
+		mw(c)
+		return c, w
+	}
+	// Scopes as an OAuth2 server would issue them: a space-delimited "scope" claim.
+	principal := newPrincipal("token", map[string]any{"sub": "1", "scope": "orders:read orders:write"})
+
+	t.Run("all of", func(t *testing.T) {
+		c, _ := run(mp.NewScopeMiddleware("orders:read", "orders:write"), principal)
+		assert.False(t, c.IsAborted())
+
+		c, w := run(mp.NewScopeMiddleware("orders:read", "orders:delete"), principal)
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="orders:read orders:delete"`, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("any of", func(t *testing.T) {
+		c, _ := run(mp.NewAnyScopeMiddleware("orders:delete", "orders:write"), principal)
+		assert.False(t, c.IsAborted())
+
+		c, _ = run(mp.NewAnyScopeMiddleware("orders:delete", "admin"), principal)
+		assert.True(t, c.IsAborted())
+	})
+
+	t.Run("scopes slice", func(t *testing.T) {
+		principal := newPrincipal("token", map[string]any{"scopes": []any{"orders:read"}})
+
+		c, _ := run(mp.NewScopeMiddleware("orders:read"), principal)
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("missing principal", func(t *testing.T) {
+		c, _ := run(mp.NewScopeMiddleware("orders:read"), nil)
+		assert.True(t, c.IsAborted())
+	})
+}
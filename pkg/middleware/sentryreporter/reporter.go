@@ -0,0 +1,57 @@
+// Package sentryreporter reports the server errors of the error middleware to Sentry.
+// It is kept out of package middleware so only services using Sentry depend on its SDK.
+package sentryreporter
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+)
+
+var _ middleware.ErrorReporter = (*Reporter)(nil)
+
+// Reporter is a middleware.ErrorReporter sending errors to Sentry, with the HTTP request,
+// the user and the request metadata attached to the event.
+type Reporter struct {
+	hub *sentry.Hub
+}
+
+// New creates a Reporter using hub, or sentry.CurrentHub() if hub is nil,
+// which is configured by sentry.Init. A hub stored on the request context, e.g. by the
+// sentrygin middleware, takes precedence so breadcrumbs recorded during the request are kept.
+func New(hub *sentry.Hub) *Reporter {
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	return &Reporter{hub}
+}
+
+func (sr *Reporter) Report(ctx context.Context, err error, meta map[string]any) {
+	ginCtx, _ := ctx.(*gin.Context)
+	if ginCtx != nil && ginCtx.Request != nil {
+		ctx = ginCtx.Request.Context()
+	}
+
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sr.hub.Clone()
+	}
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		if ginCtx != nil && ginCtx.Request != nil {
+			scope.SetRequest(ginCtx.Request)
+		}
+		if userID, ok := meta["enduser.id"].(string); ok {
+			scope.SetUser(sentry.User{ID: userID})
+		}
+		for _, key := range []string{"http.route", "http.request_id"} {
+			if val, ok := meta[key].(string); ok {
+				scope.SetTag(key, val)
+			}
+		}
+		scope.SetContext("request_metadata", meta)
+		hub.CaptureException(err)
+	})
+}
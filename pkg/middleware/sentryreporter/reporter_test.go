@@ -0,0 +1,57 @@
+package sentryreporter
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type sentryTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (st *sentryTransport) Flush(time.Duration) bool              { return true }
+func (st *sentryTransport) FlushWithContext(context.Context) bool { return true }
+func (st *sentryTransport) Configure(sentry.ClientOptions)        {}
+func (st *sentryTransport) Close()                                {}
+
+func (st *sentryTransport) SendEvent(event *sentry.Event) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.events = append(st.events, event)
+}
+
+func TestReporter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	transport := &sentryTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: transport})
+	assert.NoError(t, err)
+	reporter := New(sentry.NewHub(client, sentry.NewScope()))
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/orders/7", nil)
+	reporter.Report(ctx, errors.New("boom"), map[string]any{
+		"http.route":      "/orders/:id",
+		"http.request_id": "req-1",
+		"enduser.id":      "user-1",
+	})
+
+	if !assert.Len(t, transport.events, 1) {
+		return
+	}
+	event := transport.events[0]
+	assert.Equal(t, "boom", event.Exception[0].Value)
+	assert.Equal(t, "user-1", event.User.ID)
+	assert.Equal(t, "/orders/:id", event.Tags["http.route"])
+	assert.Equal(t, "req-1", event.Tags["http.request_id"])
+	assert.Contains(t, event.Request.URL, "/orders/7")
+	assert.Equal(t, "user-1", event.Contexts["request_metadata"]["enduser.id"])
+}
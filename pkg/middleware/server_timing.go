@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type timingContextKey struct{}
+
+type timing struct {
+	name string
+	dur  time.Duration
+	desc string
+}
+
+type timingCollector struct {
+	start   time.Time
+	timings []timing
+	mu      sync.Mutex
+}
+
+// NewServerTimingMiddleware creates a middleware that collects timings added with AddTiming
+// during the request and emits them, plus a "total" entry, as a Server-Timing response header
+// so browser devtools and APM agents can show the per-request breakdown.
+func (mp *MiddlewareProvider) NewServerTimingMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		collector := &timingCollector{start: time.Now()}
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), timingContextKey{}, collector))
+		ctx.Writer = &serverTimingWriter{ResponseWriter: ctx.Writer, collector: collector}
+
+		ctx.Next()
+	}
+}
+
+// AddTiming records a named duration, with an optional description, for the Server-Timing
+// header of the current request. It accepts a *gin.Context or any context derived from the
+// request context, and is a no-op if NewServerTimingMiddleware is not registered.
+// Timings added after the response headers are written are dropped.
+func AddTiming(ctx context.Context, name string, dur time.Duration, desc string) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		ctx = ginCtx.Request.Context()
+	}
+	collector, ok := ctx.Value(timingContextKey{}).(*timingCollector)
+	if !ok {
+		return
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.timings = append(collector.timings, timing{name, dur, desc})
+}
+
+func (tc *timingCollector) header() string {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	var sb strings.Builder
+	for _, t := range tc.timings {
+		writeTiming(&sb, t)
+		sb.WriteString(", ")
+	}
+	writeTiming(&sb, timing{name: "total", dur: time.Since(tc.start)})
+	return sb.String()
+}
+
+func writeTiming(sb *strings.Builder, t timing) {
+	sb.WriteString(t.name)
+	sb.WriteString(";dur=")
+	sb.WriteString(strconv.FormatFloat(float64(t.dur.Microseconds())/1000, 'f', -1, 64))
+	if t.desc != "" {
+		sb.WriteString(`;desc="`)
+		sb.WriteString(strings.ReplaceAll(t.desc, `"`, `'`))
+		sb.WriteByte('"')
+	}
+}
+
+// serverTimingWriter sets the Server-Timing header right before the response headers are sent.
+type serverTimingWriter struct {
+	gin.ResponseWriter
+	collector *timingCollector
+	done      bool
+}
+
+func (w *serverTimingWriter) setHeader() {
+	if w.done || w.ResponseWriter.Written() {
+		return
+	}
+	w.done = true
+	w.Header().Set("Server-Timing", w.collector.header())
+}
+
+func (w *serverTimingWriter) WriteHeader(code int) {
+	w.setHeader()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serverTimingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serverTimingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerTimingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewServerTimingMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		AddTiming(c, "db", 53200*time.Microsecond, "Database")
+		AddTiming(c.Request.Context(), "cache", 2*time.Millisecond, "")
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	header := w.Header().Get("Server-Timing")
+	assert.Contains(t, header, `db;dur=53.2;desc="Database"`)
+	assert.Contains(t, header, "cache;dur=2")
+	assert.Contains(t, header, "total;dur=")
+}
+
+func TestAddTimingWithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	assert.NotPanics(t, func() { AddTiming(c, "db", time.Millisecond, "") })
+}
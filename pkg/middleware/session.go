@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/itsLeonB/ungerr"
+)
+
+// SessionConfig configures cookie sessions with sliding expiry.
+// Every authenticated request pushes the expiry IdleTimeout into the future,
+// but never beyond MaxLifetime after the session was created.
+type SessionConfig struct {
+	Store       store.Store
+	CookieName  string        // defaults to "session_id"
+	IdleTimeout time.Duration // defaults to 30 minutes
+	MaxLifetime time.Duration // defaults to 24 hours
+	Path        string        // defaults to "/"
+	Domain      string
+	Insecure    bool          // sends the cookie over plain HTTP too, e.g. in local development; Secure by default
+	SameSite    http.SameSite // defaults to http.SameSiteLaxMode
+}
+
+// Session is the server-side state of a cookie session.
+type Session struct {
+	ID        string         `json:"id"`
+	Data      map[string]any `json:"data"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// SessionManager creates, refreshes and destroys sessions for a SessionConfig.
+type SessionManager struct {
+	cfg SessionConfig
+}
+
+// NewSessionManager creates a SessionManager, applying defaults to unset fields of cfg.
+// It logs a fatal error if cfg.Store is nil.
+func (mp *MiddlewareProvider) NewSessionManager(cfg SessionConfig) *SessionManager {
+	if cfg.Store == nil {
+		mp.logger.Fatal("session store cannot be nil")
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "session_id"
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Minute
+	}
+	if cfg.MaxLifetime <= 0 {
+		cfg.MaxLifetime = 24 * time.Hour
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &SessionManager{cfg}
+}
+
+// NewSessionMiddleware creates a middleware that authenticates requests by session cookie.
+// It loads the session, rejects it with an UnauthorizedError once it is idle or past its
+// absolute lifetime, refreshes the expiry of the store entry and the cookie, and stores the
+// session (see CurrentSession) and a Principal built from its data in the Gin context.
+func (mp *MiddlewareProvider) NewSessionMiddleware(sm *SessionManager) gin.HandlerFunc {
+	if sm == nil {
+		mp.logger.Fatal("session manager cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		sessionID, err := ctx.Cookie(sm.cfg.CookieName)
+		if err != nil || sessionID == "" {
+			_ = ctx.Error(ungerr.UnauthorizedError("missing session"))
+			ctx.Abort()
+			return
+		}
+
+		session, found, err := sm.load(ctx, sessionID)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+		if !found {
+			sm.clearCookie(ctx)
+			_ = ctx.Error(ungerr.UnauthorizedError("session expired"))
+			ctx.Abort()
+			return
+		}
+
+		saved, err := sm.save(ctx, session)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+		if !saved {
+			_ = ctx.Error(ungerr.UnauthorizedError("session expired"))
+			ctx.Abort()
+			return
+		}
+
+		sessionContextKey.Set(ctx, session)
+		SetPrincipal(ctx, newPrincipal("", session.Data))
+
+		ctx.Next()
+	}
+}
+
+// Create starts a new session holding data and sets the session cookie.
+func (sm *SessionManager) Create(ctx *gin.Context, data map[string]any) (Session, error) {
+	id, err := newRandomID()
+	if err != nil {
+		return Session{}, err
+	}
+
+	session := Session{ID: id, Data: data, CreatedAt: time.Now()}
+	saved, err := sm.save(ctx, session)
+	if err != nil {
+		return Session{}, err
+	}
+	if !saved {
+		return Session{}, ungerr.Unknown("session expired before it was stored")
+	}
+
+	return session, nil
+}
+
+// Destroy deletes the current session, if any, and clears the session cookie.
+func (sm *SessionManager) Destroy(ctx *gin.Context) error {
+	defer sm.clearCookie(ctx)
+
+	sessionID, err := ctx.Cookie(sm.cfg.CookieName)
+	if err != nil || sessionID == "" {
+		return nil
+	}
+	if err = sm.cfg.Store.Delete(ctx, sessionKey(sessionID)); err != nil {
+		return ungerr.Wrap(err, "error deleting session")
+	}
+	return nil
+}
+
+// CurrentSession returns the session loaded by the session middleware.
+func CurrentSession(ctx *gin.Context) (Session, bool) {
+	return sessionContextKey.Get(ctx)
+}
+
+func (sm *SessionManager) load(ctx *gin.Context, sessionID string) (Session, bool, error) {
+	raw, found, err := sm.cfg.Store.Get(ctx, sessionKey(sessionID))
+	if err != nil {
+		return Session{}, false, ungerr.Wrap(err, "error loading session")
+	}
+	if !found {
+		return Session{}, false, nil
+	}
+
+	var session Session
+	if err = json.Unmarshal(raw, &session); err != nil {
+		return Session{}, false, ungerr.Wrap(err, "error decoding session")
+	}
+
+	if time.Since(session.CreatedAt) >= sm.cfg.MaxLifetime {
+		if err = sm.cfg.Store.Delete(ctx, sessionKey(sessionID)); err != nil {
+			return Session{}, false, ungerr.Wrap(err, "error deleting expired session")
+		}
+		return Session{}, false, nil
+	}
+
+	return session, true, nil
+}
+
+// save stores the session with a TTL of IdleTimeout, capped by the remaining absolute
+// lifetime, and refreshes the cookie to match. If no lifetime remains, which stores would
+// take as no expiry, it deletes the session and clears the cookie instead, and returns false.
+func (sm *SessionManager) save(ctx *gin.Context, session Session) (bool, error) {
+	ttl := min(sm.cfg.IdleTimeout, sm.cfg.MaxLifetime-time.Since(session.CreatedAt))
+	if ttl <= 0 {
+		sm.clearCookie(ctx)
+		if err := sm.cfg.Store.Delete(ctx, sessionKey(session.ID)); err != nil {
+			return false, ungerr.Wrap(err, "error deleting expired session")
+		}
+		return false, nil
+	}
+
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return false, ungerr.Wrap(err, "error encoding session")
+	}
+	if err = sm.cfg.Store.Set(ctx, sessionKey(session.ID), raw, ttl); err != nil {
+		return false, ungerr.Wrap(err, "error storing session")
+	}
+
+	// Round up, since a Max-Age of 0 would make it a browser-session cookie.
+	sm.setCookie(ctx, session.ID, int((ttl+time.Second-1)/time.Second))
+	return true, nil
+}
+
+func (sm *SessionManager) setCookie(ctx *gin.Context, value string, maxAge int) {
+	ctx.SetSameSite(sm.cfg.SameSite)
+	ctx.SetCookie(sm.cfg.CookieName, value, maxAge, sm.cfg.Path, sm.cfg.Domain, !sm.cfg.Insecure, true)
+}
+
+func (sm *SessionManager) clearCookie(ctx *gin.Context) {
+	sm.setCookie(ctx, "", -1)
+}
+
+func newRandomID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", ungerr.Wrap(err, "error generating random id")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func sessionKey(sessionID string) string {
+	return "session:" + sessionID
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSessionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	newRouter := func(sm *SessionManager) *gin.Engine {
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware())
+		r.POST("/login", func(c *gin.Context) {
+			_, _ = sm.Create(c, map[string]any{"userID": "123"})
+		})
+		r.GET("/me", mp.NewSessionMiddleware(sm), func(c *gin.Context) {
+			principal, _ := CurrentPrincipal(c)
+			c.String(http.StatusOK, principal.Subject())
+		})
+		return r
+	}
+
+	login := func(r *gin.Engine) *http.Cookie {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/login", nil))
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.True(t, cookies[0].Secure, "session cookies are Secure by default")
+		assert.True(t, cookies[0].HttpOnly)
+		return cookies[0]
+	}
+
+	t.Run("valid session", func(t *testing.T) {
+		r := newRouter(mp.NewSessionManager(SessionConfig{Store: store.NewMemoryStore()}))
+		cookie := login(r)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(cookie)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "123", w.Body.String())
+		assert.NotEmpty(t, w.Result().Cookies())
+	})
+
+	t.Run("insecure opt-out", func(t *testing.T) {
+		r := newRouter(mp.NewSessionManager(SessionConfig{Store: store.NewMemoryStore(), Insecure: true}))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/login", nil))
+		if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+			assert.False(t, cookies[0].Secure)
+		}
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		r := newRouter(mp.NewSessionManager(SessionConfig{Store: store.NewMemoryStore()}))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotContains(t, w.Body.String(), "123")
+	})
+
+	t.Run("absolute lifetime exceeded", func(t *testing.T) {
+		r := newRouter(mp.NewSessionManager(SessionConfig{
+			Store:       store.NewMemoryStore(),
+			MaxLifetime: 20 * time.Millisecond,
+		}))
+		cookie := login(r)
+		time.Sleep(30 * time.Millisecond)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(cookie)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotContains(t, w.Body.String(), "123")
+	})
+}
+
+func TestSessionManagerSaveAtEndOfLifetime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+	sessions := store.NewMemoryStore()
+	sm := mp.NewSessionManager(SessionConfig{Store: sessions, MaxLifetime: time.Hour})
+
+	save := func(session Session) (bool, *http.Cookie) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/", nil)
+
+		saved, err := sm.save(ctx, session)
+		assert.NoError(t, err)
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		return saved, cookies[0]
+	}
+
+	t.Run("sub-second lifetime left", func(t *testing.T) {
+		saved, cookie := save(Session{ID: "almost", CreatedAt: time.Now().Add(-time.Hour + 500*time.Millisecond)})
+
+		assert.True(t, saved)
+		assert.Equal(t, 1, cookie.MaxAge, "not a browser-session cookie")
+	})
+
+	t.Run("no lifetime left", func(t *testing.T) {
+		raw, _ := json.Marshal(Session{ID: "over"})
+		assert.NoError(t, sessions.Set(context.Background(), sessionKey("over"), raw, time.Hour))
+
+		saved, cookie := save(Session{ID: "over", CreatedAt: time.Now().Add(-time.Hour)})
+
+		assert.False(t, saved)
+		assert.Negative(t, cookie.MaxAge, "cookie is cleared")
+		_, found, err := sessions.Get(context.Background(), sessionKey("over"))
+		assert.NoError(t, err)
+		assert.False(t, found, "session is deleted")
+	})
+}
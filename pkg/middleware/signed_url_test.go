@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+
+	t.Run("valid url", func(t *testing.T) {
+		signed, err := signer.Sign("/reports/1?format=pdf", time.Minute, SignOptions{
+			Method: "get",
+			Claims: map[string]string{"user": "42"},
+		})
+		assert.NoError(t, err)
+
+		u, _ := url.Parse(signed)
+		assert.Equal(t, "42", u.Query().Get("user"))
+		assert.NoError(t, signer.Verify(http.MethodGet, u))
+		assert.Error(t, signer.Verify(http.MethodPost, u))
+	})
+
+	t.Run("tampered url", func(t *testing.T) {
+		signed, _ := signer.Sign("/reports/1", time.Minute, SignOptions{Claims: map[string]string{"user": "42"}})
+		u, _ := url.Parse(signed)
+
+		query := u.Query()
+		query.Set("user", "43")
+		u.RawQuery = query.Encode()
+
+		assert.Error(t, signer.Verify(http.MethodGet, u))
+	})
+
+	t.Run("expired url", func(t *testing.T) {
+		signed, _ := signer.Sign("/reports/1", -time.Minute, SignOptions{})
+		u, _ := url.Parse(signed)
+
+		assert.Error(t, signer.Verify(http.MethodGet, u))
+	})
+
+	t.Run("different secret", func(t *testing.T) {
+		signed, _ := NewURLSigner([]byte("other")).Sign("/reports/1", time.Minute, SignOptions{})
+		u, _ := url.Parse(signed)
+
+		assert.Error(t, signer.Verify(http.MethodGet, u))
+	})
+}
+
+func TestNewSignedURLMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+	signer := NewURLSigner([]byte("secret"))
+	mw := mp.NewSignedURLMiddleware(signer)
+
+	t.Run("signed", func(t *testing.T) {
+		signed, _ := signer.Sign("/download", time.Minute, SignOptions{})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", signed, nil)
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/download", nil)
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		assert.NotEmpty(t, c.Errors)
+	})
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/itsLeonB/ungerr"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// StatusClientClosedRequest is the non-standard status, popularised by nginx, of requests
+// whose client went away before the response was written.
+const StatusClientClosedRequest = 499
+
+// statusError is an AppError for statuses ungerr has no constructor for.
+type statusError struct {
+	status     int
+	grpcStatus uint32
+	title      string
+	errType    string
+	details    any
+}
+
+func (se statusError) GrpcStatus() uint32 {
+	return se.grpcStatus
+}
+
+func (se statusError) HttpStatus() int {
+	return se.status
+}
+
+func (se statusError) Error() string {
+	return se.title
+}
+
+func (se statusError) Details() any {
+	return se.details
+}
+
+func (se statusError) ToLogAttrs() []ungerr.LogAttr {
+	return []ungerr.LogAttr{
+		{Key: string(semconv.ErrorTypeKey), Value: se.errType},
+		{Key: string(semconv.ErrorMessageKey), Value: fmt.Sprintf("%v", se.details)},
+	}
+}
+
+// TooManyRequestsError creates an AppError answered with 429 Too Many Requests.
+// Attach a Retry-After with WithRetryAfter.
+func TooManyRequestsError(details any) ungerr.AppError {
+	return WithErrorCode(statusError{
+		status:     http.StatusTooManyRequests,
+		grpcStatus: 8, // RESOURCE_EXHAUSTED
+		title:      http.StatusText(http.StatusTooManyRequests),
+		errType:    "TooManyRequestsError",
+		details:    details,
+	}, ErrorCodeRateLimited)
+}
+
+// ServiceUnavailableError creates an AppError answered with 503 Service Unavailable.
+// Attach a Retry-After with WithRetryAfter.
+func ServiceUnavailableError(details any) ungerr.AppError {
+	return WithErrorCode(statusError{
+		status:     http.StatusServiceUnavailable,
+		grpcStatus: 14, // UNAVAILABLE
+		title:      http.StatusText(http.StatusServiceUnavailable),
+		errType:    "ServiceUnavailableError",
+		details:    details,
+	}, ErrorCodeServiceUnavailable)
+}
+
+// payloadTooLargeError is the AppError of request bodies over a size limit.
+func payloadTooLargeError(details any) ungerr.AppError {
+	return statusError{
+		status:     http.StatusRequestEntityTooLarge,
+		grpcStatus: 8, // RESOURCE_EXHAUSTED
+		title:      http.StatusText(http.StatusRequestEntityTooLarge),
+		errType:    "PayloadTooLargeError",
+		details:    details,
+	}
+}
+
+// gatewayTimeoutError is the AppError of requests that ran past their deadline.
+func gatewayTimeoutError(details any) ungerr.AppError {
+	return statusError{
+		status:     http.StatusGatewayTimeout,
+		grpcStatus: 4, // DEADLINE_EXCEEDED
+		title:      http.StatusText(http.StatusGatewayTimeout),
+		errType:    "GatewayTimeoutError",
+		details:    details,
+	}
+}
+
+// clientClosedRequestError is the AppError of requests canceled because the client went away.
+func clientClosedRequestError(details any) ungerr.AppError {
+	return statusError{
+		status:     StatusClientClosedRequest,
+		grpcStatus: 1, // CANCELLED
+		title:      "Client Closed Request",
+		errType:    "ClientClosedRequestError",
+		details:    details,
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+type tenantContextKey struct{}
+
+// Tenant is the tenant a request was resolved to by NewTenantMiddleware.
+type Tenant struct {
+	ID   string
+	Name string
+	// Suspended tenants are rejected with a ForbiddenError.
+	Suspended bool
+	// Attributes holds application-specific data, such as a plan or a database name.
+	Attributes map[string]any
+}
+
+// TenantResolver looks up tenants by the identifier found in a request,
+// e.g. from a database or a static configuration.
+type TenantResolver interface {
+	// ResolveTenant returns the tenant with the given identifier.
+	// The boolean is false if no such tenant exists.
+	ResolveTenant(ctx context.Context, id string) (Tenant, bool, error)
+}
+
+// TenantResolverFunc adapts a function into a TenantResolver.
+type TenantResolverFunc func(ctx context.Context, id string) (Tenant, bool, error)
+
+func (f TenantResolverFunc) ResolveTenant(ctx context.Context, id string) (Tenant, bool, error) {
+	return f(ctx, id)
+}
+
+// TenantSource extracts a tenant identifier from a request, returning "" if it has none.
+// See TenantFromSubdomain, TenantFromHeader and TenantFromPathPrefix.
+type TenantSource func(ctx *gin.Context) string
+
+// TenantConfig configures NewTenantMiddleware.
+type TenantConfig struct {
+	// Sources are tried in order; the first identifier found is resolved.
+	Sources []TenantSource
+	// Resolver looks up the tenant of the identifier.
+	Resolver TenantResolver
+	// Authorize, if set, reports whether the request may access the tenant,
+	// e.g. whether the current Principal is a member. Denied requests get a ForbiddenError.
+	Authorize func(ctx *gin.Context, tenant Tenant) (bool, error)
+}
+
+// NewTenantMiddleware creates a middleware that resolves the tenant of every request and
+// stores it for CurrentTenant. Requests without a tenant identifier or with an unknown one
+// are aborted with a NotFoundError, and suspended or unauthorized tenants with a ForbiddenError.
+func (mp *MiddlewareProvider) NewTenantMiddleware(cfg TenantConfig) gin.HandlerFunc {
+	if len(cfg.Sources) == 0 {
+		mp.logger.Fatal("at least one tenant source is required")
+	}
+	if cfg.Resolver == nil {
+		mp.logger.Fatal("tenant resolver cannot be nil")
+	}
+
+	return func(ctx *gin.Context) {
+		id := tenantID(ctx, cfg.Sources)
+		if id == "" {
+			_ = ctx.Error(ungerr.NotFoundError("tenant not found"))
+			ctx.Abort()
+			return
+		}
+
+		tenant, found, err := cfg.Resolver.ResolveTenant(ctx, id)
+		if err != nil {
+			_ = ctx.Error(ungerr.Wrap(err, "error resolving tenant"))
+			ctx.Abort()
+			return
+		}
+		if !found {
+			_ = ctx.Error(ungerr.NotFoundError("tenant not found"))
+			ctx.Abort()
+			return
+		}
+		if tenant.Suspended {
+			_ = ctx.Error(ungerr.ForbiddenError("tenant is suspended"))
+			ctx.Abort()
+			return
+		}
+
+		if cfg.Authorize != nil {
+			allowed, err := cfg.Authorize(ctx, tenant)
+			if err != nil {
+				_ = ctx.Error(err)
+				ctx.Abort()
+				return
+			}
+			if !allowed {
+				_ = ctx.Error(ungerr.ForbiddenError("access to tenant denied"))
+				ctx.Abort()
+				return
+			}
+		}
+
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), tenantContextKey{}, tenant))
+		ctx.Next()
+	}
+}
+
+// CurrentTenant returns the tenant resolved by NewTenantMiddleware. It accepts a *gin.Context
+// or any context derived from the request context, so repositories can scope queries
+// without depending on Gin. The boolean is false if no tenant was resolved.
+func CurrentTenant(ctx context.Context) (Tenant, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if ginCtx.Request == nil {
+			return Tenant{}, false
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	tenant, ok := ctx.Value(tenantContextKey{}).(Tenant)
+	return tenant, ok
+}
+
+// TenantFromSubdomain reads the tenant from the leftmost label of the host under baseDomain,
+// e.g. "acme" for "acme.example.com" with baseDomain "example.com".
+func TenantFromSubdomain(baseDomain string) TenantSource {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+	return func(ctx *gin.Context) string {
+		host := strings.ToLower(ctx.Request.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, found := strings.CutSuffix(host, suffix)
+		if !found || sub == "" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// TenantFromHeader reads the tenant from a request header, e.g. "X-Tenant-ID".
+func TenantFromHeader(name string) TenantSource {
+	return func(ctx *gin.Context) string {
+		return strings.TrimSpace(ctx.GetHeader(name))
+	}
+}
+
+// TenantFromPathPrefix reads the tenant from the first segment of the request path,
+// e.g. "acme" for "/acme/orders". Routes are expected to be declared under a
+// parameter such as "/:tenant/orders".
+func TenantFromPathPrefix() TenantSource {
+	return func(ctx *gin.Context) string {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(ctx.Request.URL.Path, "/"), "/")
+		return segment
+	}
+}
+
+func tenantID(ctx *gin.Context, sources []TenantSource) string {
+	for _, source := range sources {
+		if id := source(ctx); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	tenants := map[string]Tenant{
+		"acme":     {ID: "acme", Name: "Acme"},
+		"globex":   {ID: "globex", Name: "Globex", Suspended: true},
+		"umbrella": {ID: "umbrella", Name: "Umbrella"},
+	}
+	resolver := TenantResolverFunc(func(_ context.Context, id string) (Tenant, bool, error) {
+		if id == "broken" {
+			return Tenant{}, false, errors.New("db down")
+		}
+		tenant, ok := tenants[id]
+		return tenant, ok, nil
+	})
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Use(mp.NewTenantMiddleware(TenantConfig{
+		Sources:  []TenantSource{TenantFromHeader("X-Tenant-ID"), TenantFromSubdomain("example.com")},
+		Resolver: resolver,
+		Authorize: func(_ *gin.Context, tenant Tenant) (bool, error) {
+			return tenant.ID != "umbrella", nil
+		},
+	}))
+	r.GET("/orders", func(ctx *gin.Context) {
+		tenant, ok := CurrentTenant(ctx.Request.Context())
+		assert.True(t, ok)
+		ctx.String(http.StatusOK, tenant.Name)
+	})
+
+	serve := func(host, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/orders", nil)
+		req.Host = host
+		if header != "" {
+			req.Header.Set("X-Tenant-ID", header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("from subdomain", func(t *testing.T) {
+		w := serve("acme.example.com:8080", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Acme", w.Body.String())
+	})
+
+	t.Run("header wins", func(t *testing.T) {
+		w := serve("other.example.com", "acme")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Acme", w.Body.String())
+	})
+
+	t.Run("missing tenant", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("example.com", "").Code)
+	})
+
+	t.Run("unknown tenant", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("initech.example.com", "").Code)
+	})
+
+	t.Run("suspended tenant", func(t *testing.T) {
+		w := serve("globex.example.com", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "tenant is suspended")
+	})
+
+	t.Run("unauthorized tenant", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve("umbrella.example.com", "").Code)
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, serve("broken.example.com", "").Code)
+	})
+}
+
+func TestTenantSources(t *testing.T) {
+	newCtx := func(target, host string) *gin.Context {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest("GET", target, nil)
+		ctx.Request.Host = host
+		return ctx
+	}
+
+	subdomain := TenantFromSubdomain("example.com")
+	assert.Equal(t, "acme", subdomain(newCtx("/", "ACME.example.com")))
+	assert.Equal(t, "", subdomain(newCtx("/", "a.b.example.com")))
+	assert.Equal(t, "", subdomain(newCtx("/", "example.com")))
+	assert.Equal(t, "", subdomain(newCtx("/", "acme.example.org")))
+
+	prefix := TenantFromPathPrefix()
+	assert.Equal(t, "acme", prefix(newCtx("/acme/orders", "")))
+	assert.Equal(t, "", prefix(newCtx("/", "")))
+
+	_, ok := CurrentTenant(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewAuthMiddlewareTyped is NewAuthMiddleware for check functions returning a typed value,
+// e.g. a Claims struct, instead of a map. The value is stored under a single context key
+// and read back with GetClaims. If T implements Principal it also becomes the request
+// Principal; otherwise the Principal only carries the token.
+// It is a function rather than a method because Go methods cannot have type parameters.
+func NewAuthMiddlewareTyped[T any](
+	mp *MiddlewareProvider,
+	authStrategy string,
+	tokenCheckFunc func(ctx *gin.Context, token string) (bool, T, error),
+	opts ...AuthOption,
+) gin.HandlerFunc {
+	if tokenCheckFunc == nil {
+		mp.logger.Fatalf("tokenCheckFunc cannot be nil")
+	}
+
+	return mp.newAuthMiddleware(authStrategy, func(ctx *gin.Context, token string) (bool, authResult, error) {
+		exists, claims, err := tokenCheckFunc(ctx, token)
+		if err != nil || !exists {
+			return exists, authResult{}, err
+		}
+
+		principal, ok := any(claims).(Principal)
+		if !ok {
+			principal = BasicPrincipal{Token: token}
+		}
+		return true, authResult{claims: claims, principal: principal}, nil
+	}, opts)
+}
+
+// GetClaims returns the claims stored by NewAuthMiddlewareTyped. Like CurrentPrincipal,
+// it accepts a *gin.Context or any context derived from the request context.
+// The boolean is false if the request was not authenticated or the claims are not a T.
+func GetClaims[T any](ctx context.Context) (T, bool) {
+	var val any
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		val, _ = claimsContextKey.Get(ginCtx)
+	} else {
+		val = ctx.Value(claimsRequestKey{})
+	}
+	claims, ok := val.(T)
+	return claims, ok
+}
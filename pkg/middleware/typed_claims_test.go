@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClaims struct {
+	UserID string
+	Admin  bool
+}
+
+type testPrincipalClaims struct {
+	BasicPrincipal
+	TenantID string
+}
+
+func TestNewAuthMiddlewareTyped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	t.Run("stores typed claims", func(t *testing.T) {
+		mw := NewAuthMiddlewareTyped(mp, "Bearer", func(ctx *gin.Context, token string) (bool, testClaims, error) {
+			return true, testClaims{UserID: "123", Admin: true}, nil
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer valid-token")
+
+		mw(c)
+
+		assert.False(t, c.IsAborted())
+		claims, ok := GetClaims[testClaims](c)
+		assert.True(t, ok)
+		assert.Equal(t, testClaims{UserID: "123", Admin: true}, claims)
+
+		_, ok = GetClaims[*testClaims](c)
+		assert.False(t, ok)
+
+		claims, ok = GetClaims[testClaims](c.Request.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "123", claims.UserID)
+
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.Equal(t, "valid-token", principal.(BasicPrincipal).Token)
+	})
+
+	t.Run("claims implementing Principal", func(t *testing.T) {
+		mw := NewAuthMiddlewareTyped(mp, "Bearer", func(ctx *gin.Context, token string) (bool, testPrincipalClaims, error) {
+			return true, testPrincipalClaims{BasicPrincipal{ID: "123", RoleNames: []string{"admin"}}, "acme"}, nil
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer valid-token")
+
+		mw(c)
+
+		principal, ok := CurrentPrincipal(c)
+		assert.True(t, ok)
+		assert.Equal(t, "123", principal.Subject())
+		assert.Equal(t, "acme", principal.(testPrincipalClaims).TenantID)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mw := NewAuthMiddlewareTyped(mp, "Bearer", func(ctx *gin.Context, token string) (bool, testClaims, error) {
+			return false, testClaims{}, nil
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer valid-token")
+
+		mw(c)
+
+		assert.True(t, c.IsAborted())
+		_, ok := GetClaims[testClaims](c)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerBindingFieldNames sync.Once
+
+// RegisterJSONFieldNames makes v report fields by their json tag, or their form, uri or
+// header tag for fields without one, so validation errors are keyed by the names clients
+// send. Fields without any of these tags keep their Go name. The error middleware registers it on Gin's
+// default validator; call it for any other validator whose errors reach the middleware.
+func RegisterJSONFieldNames(v *validator.Validate) {
+	v.RegisterTagNameFunc(jsonFieldName)
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri", "header"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// useJSONFieldNamesForBinding registers RegisterJSONFieldNames on Gin's default validator once.
+func useJSONFieldNamesForBinding() {
+	registerBindingFieldNames.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			RegisterJSONFieldNames(v)
+		}
+	})
+}
+
+// WithValidationTranslator makes the error middleware render validation errors with the
+// messages registered on uni, e.g. by the validator's translations packages:
+//
+//	uni := ut.New(en.New(), en.New(), id.New())
+//	trans, _ := uni.GetTranslator("en")
+//	_ = entranslations.RegisterDefaultTranslations(validate, trans)
+//
+// The translator of the request locale selected by NewLocaleMiddleware is used, falling
+// back to uni's fallback locale. Tags without a registered translation are rendered with
+// the i18n bundle's validation messages as before.
+func WithValidationTranslator(uni *ut.UniversalTranslator) ErrorOption {
+	return func(em *errorMiddleware) {
+		em.translator = uni
+	}
+}
+
+// validationDetails renders validation errors as a message per field, keyed by the field's
+// path from the validated struct, e.g. "email" or "address.street" or "items[0].sku".
+func (em *errorMiddleware) validationDetails(ctx *gin.Context, errs validator.ValidationErrors) map[string]string {
+	var trans ut.Translator
+	if em.translator != nil {
+		trans, _ = em.translator.GetTranslator(Locale(ctx))
+	}
+
+	details := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		details[validationFieldKey(fe)] = validationMessage(ctx, trans, fe)
+	}
+	return details
+}
+
+func validationFieldKey(fe validator.FieldError) string {
+	// The namespace starts with the name of the validated struct type, which clients never see.
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+// validationMessage renders a field error with trans, if it has a translation for the tag,
+// or the "validation.<tag>" message of the request locale, falling back to
+// "validation.default" and then the raw validator text.
+func validationMessage(ctx *gin.Context, trans ut.Translator, fe validator.FieldError) string {
+	if trans != nil {
+		// FieldError.Translate returns the raw validator text for tags without a translation.
+		if msg := fe.Translate(trans); msg != fe.Error() {
+			return msg
+		}
+	}
+	args := []any{fe.Field()}
+	if fe.Param() != "" {
+		args = append(args, fe.Param())
+	}
+	if msg, ok := translate(ctx, "validation."+fe.Tag(), args...); ok {
+		return msg
+	}
+	if msg, ok := translate(ctx, "validation.default", fe.Field()); ok {
+		return msg
+	}
+	return fe.Error()
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	idtranslations "github.com/go-playground/validator/v10/translations/id"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/i18n"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+type signupAddress struct {
+	Street string `json:"street" binding:"required"`
+}
+
+type signupItem struct {
+	SKU string `json:"sku" binding:"required"`
+}
+
+type signupRequest struct {
+	Email    string        `json:"email" binding:"required,email"`
+	Nickname string        `json:"nick_name,omitempty" binding:"max=3"`
+	Address  signupAddress `json:"address"`
+	Items    []signupItem  `json:"items" binding:"dive"`
+	Internal string        `json:"-" binding:"required"`
+}
+
+func TestValidationDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(), mp.NewLocaleMiddleware(i18n.NewBundle("en")))
+	r.POST("/signup", func(ctx *gin.Context) {
+		var req signupRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			_ = ctx.Error(ungerr.Wrap(err, "failed to bind JSON request"))
+		}
+	})
+
+	w := httptest.NewRecorder()
+	body := `{"email":"not-an-email","nick_name":"toolong","items":[{"sku":""}]}`
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/signup", strings.NewReader(body)))
+
+	var resp struct {
+		Errors []struct {
+			ErrorCode ErrorCode         `json:"errorCode"`
+			Detail    map[string]string `json:"detail"`
+		} `json:"errors"`
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, ErrorCodeValidationFailed, resp.Errors[0].ErrorCode)
+		assert.Equal(t, map[string]string{
+			"email":          "email must be a valid email address",
+			"nick_name":      "nick_name must be at most 3",
+			"address.street": "street is required",
+			"items[0].sku":   "sku is required",
+			"Internal":       "Internal is required",
+		}, resp.Errors[0].Detail)
+	}
+}
+
+func TestValidationFieldKeyWithoutTagNames(t *testing.T) {
+	type request struct {
+		Email string `json:"email" validate:"required"`
+	}
+
+	err := validator.New().Struct(request{})
+
+	var errs validator.ValidationErrors
+	assert.ErrorAs(t, err, &errs)
+	assert.Equal(t, "Email", validationFieldKey(errs[0]))
+
+	v := validator.New()
+	RegisterJSONFieldNames(v)
+	assert.ErrorAs(t, v.Struct(request{}), &errs)
+	assert.Equal(t, "email", validationFieldKey(errs[0]))
+}
+
+func TestRegisterJSONFieldNamesSources(t *testing.T) {
+	type request struct {
+		ID        string `uri:"id" validate:"required"`
+		Page      int    `form:"page" validate:"required"`
+		RequestID string `header:"X-Request-ID" validate:"required"`
+		Name      string `json:"name" form:"full_name" validate:"required"`
+	}
+
+	v := validator.New()
+	RegisterJSONFieldNames(v)
+
+	var errs validator.ValidationErrors
+	assert.ErrorAs(t, v.Struct(request{}), &errs)
+	var keys []string
+	for _, fe := range errs {
+		keys = append(keys, validationFieldKey(fe))
+	}
+	assert.Equal(t, []string{"id", "page", "X-Request-ID", "name"}, keys)
+}
+
+func TestWithValidationTranslator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+	mp := NewMiddlewareProvider(logger)
+
+	validate := validator.New()
+	RegisterJSONFieldNames(validate)
+	assert.NoError(t, validate.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "SKU-")
+	}))
+	uni := ut.New(en.New(), en.New(), id.New())
+	enTrans, _ := uni.GetTranslator("en")
+	idTrans, _ := uni.GetTranslator("id")
+	assert.NoError(t, entranslations.RegisterDefaultTranslations(validate, enTrans))
+	assert.NoError(t, idtranslations.RegisterDefaultTranslations(validate, idTrans))
+
+	bundle := i18n.NewBundle("en")
+	bundle.AddMessages("id", map[string]string{"validation.default": "%s tidak valid"})
+
+	type request struct {
+		Email string `json:"email" validate:"required,email"`
+		Code  string `json:"code" validate:"sku"`
+	}
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware(WithValidationTranslator(uni)), mp.NewLocaleMiddleware(bundle))
+	r.GET("/", func(ctx *gin.Context) {
+		_ = ctx.Error(ungerr.Wrap(validate.Struct(request{Email: "nope", Code: "xyz"}), "invalid request"))
+	})
+
+	cases := map[string]map[string]string{
+		"en": {
+			"email": "email must be a valid email address",
+			"code":  "code is invalid",
+		},
+		"id": {
+			"email": "email harus berupa alamat email yang valid",
+			"code":  "code tidak valid",
+		},
+	}
+	for locale, expected := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", locale)
+		r.ServeHTTP(w, req)
+
+		var resp struct {
+			Errors []struct {
+				Detail map[string]string `json:"detail"`
+			} `json:"errors"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), locale)
+		if assert.Len(t, resp.Errors, 1, locale) {
+			assert.Equal(t, expected, resp.Errors[0].Detail, locale)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents.
+const JSONAPIContentType = "application/vnd.api+json"
+
+// Format is the document format responses are rendered in.
+type Format int
+
+const (
+	// FormatStandard renders the JSONResponse envelope, negotiated by Respond.
+	FormatStandard Format = iota
+	// FormatJSONAPI renders JSON:API documents, see https://jsonapi.org/format/.
+	FormatJSONAPI
+)
+
+const formatContextKey = "github.com/itsLeonB/ginkgo/pkg/response.format"
+
+// UseFormat returns a middleware that makes Render, Respond and AbortWithResponse render
+// the requests of a router group in format, e.g.
+//
+//	v2 := r.Group("/v2", response.UseFormat(response.FormatJSONAPI))
+//
+// Errors answered by the error middleware follow the format too, unless it renders
+// problem details.
+func UseFormat(format Format) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(formatContextKey, format)
+		ctx.Next()
+	}
+}
+
+func formatOf(ctx *gin.Context) Format {
+	format, _ := ctx.Value(formatContextKey).(Format)
+	return format
+}
+
+// JSONAPIResource is implemented by data rendered in FormatJSONAPI. The attributes of the
+// resource object are the members of the data's JSON encoding, except "id".
+type JSONAPIResource interface {
+	JSONAPIType() string
+	JSONAPIID() string
+}
+
+// JSONAPIRelated is implemented by resources with relationships. The members of the
+// returned map are removed from the attributes.
+type JSONAPIRelated interface {
+	JSONAPIRelationships() map[string]JSONAPIRelationship
+}
+
+// JSONAPIRelationship links a resource to others. Data is a ResourceIdentifier for to-one
+// relationships, a []ResourceIdentifier for to-many ones, or nil for an empty to-one.
+type JSONAPIRelationship struct {
+	Data any            `json:"data"`
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+// ResourceIdentifier identifies a JSON:API resource.
+type ResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type jsonAPIDocument struct {
+	JSONAPI jsonAPIVersion   `json:"jsonapi"`
+	Data    *json.RawMessage `json:"data,omitempty"`
+	Errors  []jsonAPIError   `json:"errors,omitempty"`
+	Meta    map[string]any   `json:"meta,omitempty"`
+}
+
+type jsonAPIVersion struct {
+	Version string `json:"version"`
+}
+
+type jsonAPIResourceObject struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]json.RawMessage     `json:"attributes,omitempty"`
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+}
+
+type jsonAPIError struct {
+	Status string         `json:"status"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source *jsonAPISource `json:"source,omitempty"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+type jsonAPISource struct {
+	Pointer string `json:"pointer"`
+}
+
+// renderJSONAPI writes resp as a JSON:API document. The message and pagination go into the
+// top-level meta, next to resp.Meta. Errors become one error object each, except errors
+// whose detail is a message per field, such as validation errors, which become one error
+// object per field with a source pointer into the attributes.
+func renderJSONAPI(ctx *gin.Context, code int, resp JSONResponse) {
+	if !bodyAllowedForStatus(code) {
+		ctx.Status(code)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	doc, err := toJSONAPIDocument(code, resp)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+	if err := encode(buf, doc); err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Header("Content-Type", JSONAPIContentType)
+	ctx.Status(code)
+	_, _ = ctx.Writer.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+func toJSONAPIDocument(code int, resp JSONResponse) (jsonAPIDocument, error) {
+	doc := jsonAPIDocument{JSONAPI: jsonAPIVersion{Version: "1.1"}}
+
+	for _, respErr := range resp.Errors {
+		doc.Errors = append(doc.Errors, toJSONAPIErrors(code, respErr)...)
+	}
+	if len(doc.Errors) == 0 {
+		data, err := toJSONAPIData(resp.Data)
+		if err != nil {
+			return jsonAPIDocument{}, err
+		}
+		doc.Data = &data
+	}
+
+	if len(resp.Meta) > 0 || resp.Message != "" || !resp.Pagination.IsZero() {
+		doc.Meta = make(map[string]any, len(resp.Meta)+2)
+		maps.Copy(doc.Meta, resp.Meta)
+		if resp.Message != "" {
+			doc.Meta["message"] = resp.Message
+		}
+		if !resp.Pagination.IsZero() {
+			doc.Meta["pagination"] = resp.Pagination
+		}
+	}
+	return doc, nil
+}
+
+// toJSONAPIData encodes data as primary data: null, a resource object, or an array of them.
+func toJSONAPIData(data any) (json.RawMessage, error) {
+	if data == nil {
+		return json.RawMessage("null"), nil
+	}
+	if resource, ok := data.(JSONAPIResource); ok {
+		object, err := toJSONAPIResourceObject(resource)
+		if err != nil {
+			return nil, err
+		}
+		return marshalRaw(object)
+	}
+
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("response: %T does not implement JSONAPIResource", data)
+	}
+	objects := make([]jsonAPIResourceObject, value.Len())
+	for i := range objects {
+		resource, ok := value.Index(i).Interface().(JSONAPIResource)
+		if !ok {
+			return nil, fmt.Errorf("response: %T does not implement JSONAPIResource", value.Index(i).Interface())
+		}
+		object, err := toJSONAPIResourceObject(resource)
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = object
+	}
+	return marshalRaw(objects)
+}
+
+func toJSONAPIResourceObject(resource JSONAPIResource) (jsonAPIResourceObject, error) {
+	object := jsonAPIResourceObject{Type: resource.JSONAPIType(), ID: resource.JSONAPIID()}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, resource); err != nil {
+		return jsonAPIResourceObject{}, err
+	}
+	if err := json.Unmarshal(buf.Bytes(), &object.Attributes); err != nil {
+		return jsonAPIResourceObject{}, fmt.Errorf("response: attributes of %T must encode to a JSON object: %w", resource, err)
+	}
+	delete(object.Attributes, "id")
+
+	if related, ok := resource.(JSONAPIRelated); ok {
+		object.Relationships = related.JSONAPIRelationships()
+		for name := range object.Relationships {
+			delete(object.Attributes, name)
+		}
+	}
+	return object, nil
+}
+
+func toJSONAPIErrors(code int, err error) []jsonAPIError {
+	var fields struct {
+		Code      string         `json:"code"`
+		ErrorCode string         `json:"errorCode"`
+		Detail    any            `json:"detail"`
+		Debug     map[string]any `json:"debug"`
+	}
+	var buf bytes.Buffer
+	if encodeErr := encode(&buf, err); encodeErr == nil {
+		// Errors that don't encode to an object keep the zero fields.
+		_ = json.Unmarshal(buf.Bytes(), &fields)
+	}
+
+	base := jsonAPIError{Status: strconv.Itoa(code), Code: fields.ErrorCode, Title: fields.Code}
+	if base.Title == "" {
+		base.Title = err.Error()
+	}
+	if fields.Debug != nil {
+		base.Meta = map[string]any{"debug": fields.Debug}
+	}
+
+	perField, ok := fields.Detail.(map[string]any)
+	if !ok {
+		switch detail := fields.Detail.(type) {
+		case nil:
+		case string:
+			base.Detail = detail
+		default:
+			if base.Meta == nil {
+				base.Meta = map[string]any{}
+			}
+			base.Meta["detail"] = detail
+		}
+		return []jsonAPIError{base}
+	}
+
+	fieldNames := make([]string, 0, len(perField))
+	for name := range perField {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+
+	apiErrors := make([]jsonAPIError, len(fieldNames))
+	for i, name := range fieldNames {
+		apiErrors[i] = base
+		apiErrors[i].Detail = fmt.Sprint(perField[name])
+		apiErrors[i].Source = &jsonAPISource{Pointer: "/data/attributes/" + strings.ReplaceAll(name, ".", "/")}
+	}
+	return apiErrors
+}
+
+func marshalRaw(v any) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
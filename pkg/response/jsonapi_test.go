@@ -0,0 +1,132 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testArticle struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	AuthorID int    `json:"authorId"`
+}
+
+func (ta testArticle) JSONAPIType() string { return "articles" }
+func (ta testArticle) JSONAPIID() string   { return strconv.Itoa(ta.ID) }
+
+func (ta testArticle) JSONAPIRelationships() map[string]JSONAPIRelationship {
+	return map[string]JSONAPIRelationship{
+		"authorId": {Data: ResourceIdentifier{Type: "people", ID: strconv.Itoa(ta.AuthorID)}},
+	}
+}
+
+type testPerson struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (tp testPerson) JSONAPIType() string { return "people" }
+func (tp testPerson) JSONAPIID() string   { return tp.ID }
+
+func TestJSONAPIFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.Group("/v2", UseFormat(FormatJSONAPI)).GET("/jsonapi", handler)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v2/jsonapi", nil)
+		req.Header.Set("Accept", JSONAPIContentType)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("single resource with relationships", func(t *testing.T) {
+		w := serve(func(ctx *gin.Context) {
+			Respond(ctx, http.StatusOK, NewResponse(testArticle{ID: 1, Title: "Hello", AuthorID: 9}).
+				WithMessage("ok").
+				WithMeta("requestId", "req-1"))
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, JSONAPIContentType, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"jsonapi": {"version": "1.1"},
+			"data": {
+				"type": "articles",
+				"id": "1",
+				"attributes": {"title": "Hello"},
+				"relationships": {"authorId": {"data": {"type": "people", "id": "9"}}}
+			},
+			"meta": {"message": "ok", "requestId": "req-1"}
+		}`, w.Body.String())
+	})
+
+	t.Run("collection with pagination", func(t *testing.T) {
+		w := serve(func(ctx *gin.Context) {
+			Render(ctx, http.StatusOK, NewResponse([]testPerson{{ID: "a", Name: "Ann"}}).
+				WithPagination(QueryOptions{Page: 1, Limit: 1}, 2))
+		})
+
+		assert.JSONEq(t, `{
+			"jsonapi": {"version": "1.1"},
+			"data": [{"type": "people", "id": "a", "attributes": {"name": "Ann"}}],
+			"meta": {"pagination": {"totalData": 2, "currentPage": 1, "totalPages": 2, "hasNextPage": true, "hasPrevPage": false}}
+		}`, w.Body.String())
+	})
+
+	t.Run("null data", func(t *testing.T) {
+		w := serve(func(ctx *gin.Context) {
+			Respond(ctx, http.StatusOK, NewResponse(nil))
+		})
+
+		assert.JSONEq(t, `{"jsonapi": {"version": "1.1"}, "data": null}`, w.Body.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w := serve(func(ctx *gin.Context) {
+			AbortWithResponse(ctx, http.StatusUnprocessableEntity, NewErrorResponse(
+				testError{Code: "Unprocessable Entity", Detail: map[string]string{"title": "title is required", "author.name": "name is required"}},
+				errors.New("plain"),
+			))
+		})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.JSONEq(t, `{
+			"jsonapi": {"version": "1.1"},
+			"errors": [
+				{"status": "422", "title": "Unprocessable Entity", "detail": "name is required", "source": {"pointer": "/data/attributes/author/name"}},
+				{"status": "422", "title": "Unprocessable Entity", "detail": "title is required", "source": {"pointer": "/data/attributes/title"}},
+				{"status": "422", "title": "plain"}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("data that is not a resource", func(t *testing.T) {
+		w := serve(func(ctx *gin.Context) {
+			Respond(ctx, http.StatusOK, NewResponse(gin.H{"name": "ginkgo"}))
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("other groups are unaffected", func(t *testing.T) {
+		r := gin.New()
+		r.Group("/v2", UseFormat(FormatJSONAPI))
+		r.GET("/standard", func(ctx *gin.Context) {
+			Respond(ctx, http.StatusOK, NewResponse("data"))
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/standard", nil))
+
+		assert.JSONEq(t, `{"data":"data"}`, w.Body.String())
+	})
+}
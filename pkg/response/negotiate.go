@@ -0,0 +1,206 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+)
+
+// offeredMediaTypes are the media types Respond can produce, in order of preference.
+var offeredMediaTypes = []string{
+	binding.MIMEJSON,
+	binding.MIMEXML,
+	binding.MIMEXML2,
+	binding.MIMEMSGPACK,
+	binding.MIMEMSGPACK2,
+	binding.MIMEPROTOBUF,
+}
+
+// notAcceptable is the error rendered when no offered media type is acceptable.
+type notAcceptable struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+func (na notAcceptable) Error() string {
+	return na.Code + ": " + na.Detail
+}
+
+// Respond writes resp with the given status code in the media type the client prefers
+// according to its Accept header: JSON (the default, also used without an Accept header),
+// XML, MsgPack or protobuf. Clients accepting none of them get 406 Not Acceptable with a JSON body.
+// JSON responses are identical to Render's. XML responses have a <response> root with an
+// element per member, an <item> per array element, and <entry key="..."> for map keys that
+// are not valid element names. MsgPack uses the struct's json tags as keys. Protobuf responses
+// are a responsepb.Response; see responsepb/envelope.proto.
+// Requests of router groups using FormatJSONAPI always get a JSON:API document.
+func Respond(ctx *gin.Context, code int, resp JSONResponse) {
+	if formatOf(ctx) == FormatJSONAPI {
+		renderJSONAPI(ctx, code, resp)
+		return
+	}
+	mediaType := ctx.NegotiateFormat(offeredMediaTypes...)
+	if mediaType == "" {
+		Render(ctx, http.StatusNotAcceptable, NewErrorResponse(notAcceptable{
+			Code:   http.StatusText(http.StatusNotAcceptable),
+			Detail: "supported media types are " + strings.Join(offeredMediaTypes, ", "),
+		}))
+		return
+	}
+	respond(ctx, code, resp, mediaType)
+}
+
+// AbortWithResponse aborts the handler chain and renders resp like Respond, except that it
+// falls back to JSON when no offered media type is acceptable, so error statuses are never
+// replaced by 406.
+func AbortWithResponse(ctx *gin.Context, code int, resp JSONResponse) {
+	ctx.Abort()
+	if formatOf(ctx) == FormatJSONAPI {
+		renderJSONAPI(ctx, code, resp)
+		return
+	}
+	mediaType := ctx.NegotiateFormat(offeredMediaTypes...)
+	if mediaType == "" {
+		mediaType = binding.MIMEJSON
+	}
+	respond(ctx, code, resp, mediaType)
+}
+
+func respond(ctx *gin.Context, code int, resp JSONResponse, mediaType string) {
+	var encodeBody func(io.Writer, any) error
+	switch mediaType {
+	case binding.MIMEXML, binding.MIMEXML2:
+		encodeBody = encodeXML
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		encodeBody = encodeMsgPack
+	case binding.MIMEPROTOBUF:
+		encodeBody = encodeProtobuf
+	default:
+		Render(ctx, code, resp)
+		return
+	}
+
+	if !bodyAllowedForStatus(code) {
+		ctx.Status(code)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := encodeBody(buf, resp); err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	contentType := mediaType
+	if mediaType != binding.MIMEPROTOBUF {
+		contentType += "; charset=utf-8"
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Status(code)
+	_, _ = ctx.Writer.Write(buf.Bytes())
+}
+
+func encodeMsgPack(w io.Writer, v any) error {
+	var mh codec.MsgpackHandle
+	return codec.NewEncoder(w, &mh).Encode(v)
+}
+
+// encodeXML writes v as XML by way of its JSON encoding, so it honours the same tags and
+// MarshalJSON methods and supports maps, which encoding/xml can't marshal.
+func encodeXML(w io.Writer, v any) error {
+	var jsonBuf bytes.Buffer
+	if err := encode(&jsonBuf, v); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(&jsonBuf)
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLValue(enc, xml.StartElement{Name: xml.Name{Local: "response"}}, generic); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func writeXMLValue(enc *xml.Encoder, start xml.StartElement, v any) error {
+	if v == nil {
+		return nil
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := xml.StartElement{Name: xml.Name{Local: key}}
+			if !xmlNamePattern.MatchString(key) || strings.HasPrefix(strings.ToLower(key), "xml") {
+				child = xml.StartElement{
+					Name: xml.Name{Local: "entry"},
+					Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+				}
+			}
+			if err := writeXMLValue(enc, child, val[key]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if err := writeXMLValue(enc, xml.StartElement{Name: xml.Name{Local: "item"}}, item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := enc.EncodeToken(xml.CharData(val)); err != nil {
+			return err
+		}
+	case json.Number:
+		if err := enc.EncodeToken(xml.CharData(val.String())); err != nil {
+			return err
+		}
+	case bool:
+		text := "false"
+		if val {
+			text = "true"
+		}
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	default:
+		return errors.New("response: unsupported JSON value for XML encoding")
+	}
+
+	return enc.EncodeToken(start.End())
+}
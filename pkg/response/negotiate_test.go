@@ -0,0 +1,109 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+)
+
+type testError struct {
+	Code   string `json:"code"`
+	Detail any    `json:"detail"`
+}
+
+func (te testError) Error() string { return te.Code }
+
+func newNegotiatedContext(accept string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	return c, w
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := NewResponse(gin.H{"name": "ginkgo", "tags": []string{"a", "b"}}).
+		WithMessage("ok").
+		WithPagination(QueryOptions{Page: 1, Limit: 10}, 42)
+
+	t.Run("json by default", func(t *testing.T) {
+		expected, expectedW := newNegotiatedContext("")
+		Render(expected, http.StatusOK, resp)
+
+		for _, accept := range []string{"", "*/*", "application/json", "text/html, application/json"} {
+			c, w := newNegotiatedContext(accept)
+			Respond(c, http.StatusOK, resp)
+
+			assert.Equal(t, http.StatusOK, w.Code, accept)
+			assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"), accept)
+			assert.Equal(t, expectedW.Body.String(), w.Body.String(), accept)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		c, w := newNegotiatedContext("application/xml")
+		Respond(c, http.StatusCreated, resp)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<response><data><name>ginkgo</name><tags><item>a</item><item>b</item></tags></data>`+
+			`<message>ok</message><pagination><currentPage>1</currentPage><hasNextPage>true</hasNextPage>`+
+			`<hasPrevPage>false</hasPrevPage><totalData>42</totalData><totalPages>5</totalPages></pagination></response>`,
+			w.Body.String())
+	})
+
+	t.Run("xml with keys that are not element names", func(t *testing.T) {
+		c, w := newNegotiatedContext("text/xml")
+		Respond(c, http.StatusUnprocessableEntity, NewErrorResponse(testError{
+			Code:   "Unprocessable Entity",
+			Detail: map[string]string{"items[0].sku": "sku is required"},
+		}))
+
+		assert.Equal(t, "text/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(),
+			`<errors><item><code>Unprocessable Entity</code><detail><entry key="items[0].sku">sku is required</entry></detail></item></errors>`)
+	})
+
+	t.Run("msgpack", func(t *testing.T) {
+		c, w := newNegotiatedContext("application/x-msgpack")
+		Respond(c, http.StatusOK, resp)
+
+		assert.Equal(t, "application/x-msgpack; charset=utf-8", w.Header().Get("Content-Type"))
+		var decoded map[string]any
+		var mh codec.MsgpackHandle
+		mh.RawToString = true
+		assert.NoError(t, codec.NewDecoder(bytes.NewReader(w.Body.Bytes()), &mh).Decode(&decoded))
+		assert.Equal(t, "ok", decoded["message"])
+		assert.Contains(t, decoded, "pagination")
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		c, w := newNegotiatedContext("text/html")
+		Respond(c, http.StatusOK, resp)
+
+		var body map[string]any
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Contains(t, w.Body.String(), "application/xml")
+	})
+
+	t.Run("abort falls back to json", func(t *testing.T) {
+		c, w := newNegotiatedContext("text/html")
+		AbortWithResponse(c, http.StatusNotFound, NewErrorResponse(errors.New("missing")))
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+}
@@ -0,0 +1,94 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details object.
+type ProblemDetails struct {
+	// Type is a URI identifying the problem type. Defaults to "about:blank".
+	Type string
+	// Title is a short summary of the problem type. Defaults to the status text.
+	Title  string
+	Status int
+	// Detail explains this occurrence of the problem.
+	Detail string
+	// Instance is a URI identifying this occurrence, e.g. the request path.
+	Instance string
+	// Extensions are additional members, such as a list of validation errors.
+	Extensions map[string]any
+}
+
+// NewProblemDetails creates a ProblemDetails for status with its default type and title.
+func NewProblemDetails(status int, detail string) ProblemDetails {
+	return ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// MarshalJSON flattens Extensions into the object, as RFC 7807 requires.
+// Extensions cannot override the standard members.
+func (pd ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(pd.Extensions)+5)
+	maps.Copy(members, pd.Extensions)
+
+	members["type"] = pd.Type
+	if pd.Type == "" {
+		members["type"] = "about:blank"
+	}
+	members["title"] = pd.Title
+	if pd.Title == "" {
+		members["title"] = http.StatusText(pd.Status)
+	}
+	members["status"] = pd.Status
+	if pd.Detail != "" {
+		members["detail"] = pd.Detail
+	} else {
+		delete(members, "detail")
+	}
+	if pd.Instance != "" {
+		members["instance"] = pd.Instance
+	} else {
+		delete(members, "instance")
+	}
+
+	return json.Marshal(members)
+}
+
+// RenderProblem writes problem as an application/problem+json response with its status.
+// Encoding failures are attached to the context and answered with 500 Internal Server Error.
+func RenderProblem(ctx *gin.Context, problem ProblemDetails) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := encode(buf, problem); err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Header("Content-Type", ProblemContentType)
+	ctx.Status(problem.Status)
+	_, _ = ctx.Writer.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// AbortWithProblem aborts the handler chain and renders problem with RenderProblem.
+func AbortWithProblem(ctx *gin.Context, problem ProblemDetails) {
+	ctx.Abort()
+	RenderProblem(ctx, problem)
+}
@@ -0,0 +1,47 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("defaults and extensions", func(t *testing.T) {
+		problem := ProblemDetails{Status: http.StatusConflict, Extensions: map[string]any{
+			"request_id": "req-1",
+			"status":     999,
+		}}
+
+		data, err := json.Marshal(problem)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"type":"about:blank","title":"Conflict","status":409,"request_id":"req-1"}`, string(data))
+	})
+
+	t.Run("abort with problem", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		problem := NewProblemDetails(http.StatusNotFound, "order not found")
+		problem.Instance = "/orders/7"
+		AbortWithProblem(c, problem)
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Not Found",
+			"status": 404,
+			"detail": "order not found",
+			"instance": "/orders/7"
+		}`, w.Body.String())
+	})
+}
@@ -0,0 +1,117 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/itsLeonB/ginkgo/pkg/response/responsepb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func encodeProtobuf(w io.Writer, v any) error {
+	envelope, err := toProtoEnvelope(v.(JSONResponse))
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// toProtoEnvelope converts resp to its protobuf encoding. Data that is a proto.Message is
+// packed as a google.protobuf.Any; any other data and the error details are converted
+// through their JSON encoding, so they honour the same tags and MarshalJSON methods.
+func toProtoEnvelope(resp JSONResponse) (*responsepb.Response, error) {
+	envelope := &responsepb.Response{Message: resp.Message}
+
+	switch data := resp.Data.(type) {
+	case nil:
+	case proto.Message:
+		packed, err := anypb.New(data)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Payload = &responsepb.Response_Data{Data: packed}
+	default:
+		value, err := toProtoValue(data)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Payload = &responsepb.Response_JsonData{JsonData: value}
+	}
+
+	for _, respErr := range resp.Errors {
+		protoErr, err := toProtoError(respErr)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Errors = append(envelope.Errors, protoErr)
+	}
+
+	if !resp.Pagination.IsZero() {
+		envelope.Pagination = &responsepb.Pagination{
+			TotalData:   int64(resp.Pagination.TotalData),
+			CurrentPage: int64(resp.Pagination.CurrentPage),
+			TotalPages:  int64(resp.Pagination.TotalPages),
+			HasNextPage: resp.Pagination.HasNextPage,
+			HasPrevPage: resp.Pagination.HasPrevPage,
+		}
+	}
+
+	if len(resp.Meta) > 0 {
+		meta, err := toProtoValue(resp.Meta)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Meta = meta.GetStructValue()
+	}
+
+	return envelope, nil
+}
+
+// toProtoError converts an error of the envelope from its JSON object encoding, falling back
+// to the error's message as its code for errors without one, such as errors.New values.
+func toProtoError(err error) (*responsepb.Error, error) {
+	var fields struct {
+		Code      string `json:"code"`
+		ErrorCode string `json:"errorCode"`
+		Detail    any    `json:"detail"`
+	}
+	var buf bytes.Buffer
+	if encodeErr := encode(&buf, err); encodeErr != nil {
+		return nil, encodeErr
+	}
+	// Errors that don't encode to an object keep the zero fields.
+	_ = json.Unmarshal(buf.Bytes(), &fields)
+
+	protoErr := &responsepb.Error{Code: fields.Code, ErrorCode: fields.ErrorCode}
+	if protoErr.Code == "" {
+		protoErr.Code = err.Error()
+	}
+	if fields.Detail != nil {
+		detail, valueErr := structpb.NewValue(fields.Detail)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		protoErr.Detail = detail
+	}
+	return protoErr, nil
+}
+
+func toProtoValue(v any) (*structpb.Value, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(buf.Bytes(), &generic); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(generic)
+}
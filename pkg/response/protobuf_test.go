@@ -0,0 +1,65 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/response/responsepb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRespondProtobuf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	decode := func(t *testing.T, body []byte) *responsepb.Response {
+		var envelope responsepb.Response
+		assert.NoError(t, proto.Unmarshal(body, &envelope))
+		return &envelope
+	}
+
+	t.Run("json data", func(t *testing.T) {
+		c, w := newNegotiatedContext("application/x-protobuf")
+		Respond(c, http.StatusOK, NewResponse(gin.H{"name": "ginkgo", "stars": 42}).
+			WithMessage("ok").
+			WithPagination(QueryOptions{Page: 2, Limit: 10}, 42).
+			WithMeta("deprecation", "use /v2/repos"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-protobuf", w.Header().Get("Content-Type"))
+		envelope := decode(t, w.Body.Bytes())
+		assert.Equal(t, "ok", envelope.GetMessage())
+		assert.Equal(t, map[string]any{"name": "ginkgo", "stars": float64(42)}, envelope.GetJsonData().AsInterface())
+		assert.Equal(t, int64(42), envelope.GetPagination().GetTotalData())
+		assert.True(t, envelope.GetPagination().GetHasPrevPage())
+		assert.Equal(t, map[string]any{"deprecation": "use /v2/repos"}, envelope.GetMeta().AsMap())
+	})
+
+	t.Run("proto data", func(t *testing.T) {
+		c, w := newNegotiatedContext("application/x-protobuf")
+		Respond(c, http.StatusOK, NewResponse(&responsepb.Pagination{TotalData: 7}))
+
+		envelope := decode(t, w.Body.Bytes())
+		var data responsepb.Pagination
+		assert.NoError(t, envelope.GetData().UnmarshalTo(&data))
+		assert.Equal(t, int64(7), data.GetTotalData())
+		assert.Nil(t, envelope.GetPagination())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c, w := newNegotiatedContext("application/x-protobuf")
+		Respond(c, http.StatusUnprocessableEntity, NewErrorResponse(
+			testError{Code: "Unprocessable Entity", Detail: map[string]string{"email": "email is required"}},
+			errors.New("plain"),
+		))
+
+		envelope := decode(t, w.Body.Bytes())
+		if assert.Len(t, envelope.GetErrors(), 2) {
+			assert.Equal(t, "Unprocessable Entity", envelope.GetErrors()[0].GetCode())
+			assert.Equal(t, map[string]any{"email": "email is required"}, envelope.GetErrors()[0].GetDetail().AsInterface())
+			assert.Equal(t, "plain", envelope.GetErrors()[1].GetCode())
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Encoder writes the JSON encoding of v to w.
+// It lets services swap encoding/json for a faster drop-in such as jsoniter or sonic.
+type Encoder func(w io.Writer, v any) error
+
+// maxPooledBufferSize keeps buffers grown by unusually large responses out of the pool.
+const maxPooledBufferSize = 64 << 10
+
+var (
+	encoder atomic.Pointer[Encoder]
+
+	bufferPool = sync.Pool{
+		New: func() any { return new(bytes.Buffer) },
+	}
+	envelopePool = sync.Pool{
+		New: func() any { return new(JSONResponse) },
+	}
+)
+
+// SetEncoder replaces the encoder used by Render. Passing nil restores encoding/json.
+// It is meant to be called once at startup, e.g.
+//
+//	response.SetEncoder(func(w io.Writer, v any) error {
+//		return sonic.ConfigDefault.NewEncoder(w).Encode(v)
+//	})
+func SetEncoder(enc Encoder) {
+	if enc == nil {
+		encoder.Store(nil)
+		return
+	}
+	encoder.Store(&enc)
+}
+
+func encode(w io.Writer, v any) error {
+	if enc := encoder.Load(); enc != nil {
+		return (*enc)(w, v)
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Render writes resp as the JSON body of the response with the given status code.
+// Unlike ctx.JSON it encodes through a pooled envelope and buffer, which keeps
+// allocations flat for high-throughput handlers. Encoding failures are attached
+// to the context and answered with 500 Internal Server Error.
+// Requests of router groups using FormatJSONAPI get a JSON:API document instead.
+func Render(ctx *gin.Context, code int, resp JSONResponse) {
+	if formatOf(ctx) == FormatJSONAPI {
+		renderJSONAPI(ctx, code, resp)
+		return
+	}
+	if !bodyAllowedForStatus(code) {
+		ctx.Status(code)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	envelope := envelopePool.Get().(*JSONResponse)
+	*envelope = resp
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		*envelope = JSONResponse{}
+		envelopePool.Put(envelope)
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := encode(buf, envelope); err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Header("Content-Type", "application/json; charset=utf-8")
+	ctx.Status(code)
+	_, _ = ctx.Writer.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// AbortWithJSON aborts the handler chain and renders resp with Render.
+func AbortWithJSON(ctx *gin.Context, code int, resp JSONResponse) {
+	ctx.Abort()
+	Render(ctx, code, resp)
+}
+
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
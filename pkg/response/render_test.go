@@ -0,0 +1,105 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("matches ctx.JSON", func(t *testing.T) {
+		resp := NewResponse(gin.H{"name": "<ginkgo>"}).WithMessage("ok")
+
+		expected := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(expected)
+		c.JSON(http.StatusCreated, resp)
+
+		w := httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		Render(c, http.StatusCreated, resp)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, expected.Header().Get("Content-Type"), w.Header().Get("Content-Type"))
+		assert.Equal(t, expected.Body.String(), w.Body.String())
+	})
+
+	t.Run("no body for 204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		Render(c, http.StatusNoContent, NewResponse("ignored"))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("custom encoder", func(t *testing.T) {
+		SetEncoder(func(w io.Writer, v any) error {
+			_, err := io.WriteString(w, `{"custom":true}`)
+			return err
+		})
+		defer SetEncoder(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		Render(c, http.StatusOK, NewResponse("data"))
+
+		assert.Equal(t, `{"custom":true}`, w.Body.String())
+	})
+
+	t.Run("encoder error", func(t *testing.T) {
+		SetEncoder(func(w io.Writer, v any) error { return errors.New("boom") })
+		defer SetEncoder(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		Render(c, http.StatusOK, NewResponse("data"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.True(t, c.IsAborted())
+		assert.Len(t, c.Errors, 1)
+	})
+
+	t.Run("AbortWithJSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		AbortWithJSON(c, http.StatusBadRequest, NewResponse(nil).WithMessage("bad"))
+
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "bad", body["message"])
+	})
+}
+
+func BenchmarkRender(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	resp := NewResponse(map[string]any{"id": 1, "name": "ginkgo", "tags": []string{"a", "b"}}).
+		WithPagination(QueryOptions{Page: 1, Limit: 10}, 42)
+
+	b.Run("ctx.JSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.JSON(http.StatusOK, resp)
+		}
+	})
+
+	b.Run("Render", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			Render(c, http.StatusOK, resp)
+		}
+	})
+}
@@ -0,0 +1,379 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: envelope.proto
+
+// Package ginkgo.response.v1 is the protobuf encoding of ginkgo's standard response
+// envelope, sent to clients that accept application/x-protobuf.
+
+package responsepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Response mirrors response.JSONResponse.
+type Response struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// The payload is data if the handler returned a protobuf message, and json_data,
+	// its JSON encoding as a google.protobuf.Value, otherwise.
+	//
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Response_Data
+	//	*Response_JsonData
+	Payload       isResponse_Payload `protobuf_oneof:"payload"`
+	Errors        []*Error           `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	Pagination    *Pagination        `protobuf:"bytes,5,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	Meta          *structpb.Struct   `protobuf:"bytes,6,opt,name=meta,proto3" json:"meta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	mi := &file_envelope_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_envelope_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_envelope_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Response) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Response) GetPayload() isResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Response) GetData() *anypb.Any {
+	if x != nil {
+		if x, ok := x.Payload.(*Response_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+func (x *Response) GetJsonData() *structpb.Value {
+	if x != nil {
+		if x, ok := x.Payload.(*Response_JsonData); ok {
+			return x.JsonData
+		}
+	}
+	return nil
+}
+
+func (x *Response) GetErrors() []*Error {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *Response) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+func (x *Response) GetMeta() *structpb.Struct {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+type isResponse_Payload interface {
+	isResponse_Payload()
+}
+
+type Response_Data struct {
+	Data *anypb.Any `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+type Response_JsonData struct {
+	JsonData *structpb.Value `protobuf:"bytes,3,opt,name=json_data,json=jsonData,proto3,oneof"`
+}
+
+func (*Response_Data) isResponse_Payload() {}
+
+func (*Response_JsonData) isResponse_Payload() {}
+
+// Error mirrors an entry of the errors list of the JSON envelope.
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// code is the HTTP status text, e.g. "Not Found".
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// error_code is the stable machine-readable code, e.g. "NOT_FOUND".
+	ErrorCode string `protobuf:"bytes,2,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	// detail is a message or, for validation errors, a message per field.
+	Detail        *structpb.Value `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_envelope_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_envelope_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_envelope_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *Error) GetDetail() *structpb.Value {
+	if x != nil {
+		return x.Detail
+	}
+	return nil
+}
+
+// Pagination mirrors response.Pagination.
+type Pagination struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalData     int64                  `protobuf:"varint,1,opt,name=total_data,json=totalData,proto3" json:"total_data,omitempty"`
+	CurrentPage   int64                  `protobuf:"varint,2,opt,name=current_page,json=currentPage,proto3" json:"current_page,omitempty"`
+	TotalPages    int64                  `protobuf:"varint,3,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	HasNextPage   bool                   `protobuf:"varint,4,opt,name=has_next_page,json=hasNextPage,proto3" json:"has_next_page,omitempty"`
+	HasPrevPage   bool                   `protobuf:"varint,5,opt,name=has_prev_page,json=hasPrevPage,proto3" json:"has_prev_page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pagination) Reset() {
+	*x = Pagination{}
+	mi := &file_envelope_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pagination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pagination) ProtoMessage() {}
+
+func (x *Pagination) ProtoReflect() protoreflect.Message {
+	mi := &file_envelope_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pagination.ProtoReflect.Descriptor instead.
+func (*Pagination) Descriptor() ([]byte, []int) {
+	return file_envelope_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Pagination) GetTotalData() int64 {
+	if x != nil {
+		return x.TotalData
+	}
+	return 0
+}
+
+func (x *Pagination) GetCurrentPage() int64 {
+	if x != nil {
+		return x.CurrentPage
+	}
+	return 0
+}
+
+func (x *Pagination) GetTotalPages() int64 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+func (x *Pagination) GetHasNextPage() bool {
+	if x != nil {
+		return x.HasNextPage
+	}
+	return false
+}
+
+func (x *Pagination) GetHasPrevPage() bool {
+	if x != nil {
+		return x.HasPrevPage
+	}
+	return false
+}
+
+var File_envelope_proto protoreflect.FileDescriptor
+
+const file_envelope_proto_rawDesc = "" +
+	"\n" +
+	"\x0eenvelope.proto\x12\x12ginkgo.response.v1\x1a\x19google/protobuf/any.proto\x1a\x1cgoogle/protobuf/struct.proto\"\xb2\x02\n" +
+	"\bResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12*\n" +
+	"\x04data\x18\x02 \x01(\v2\x14.google.protobuf.AnyH\x00R\x04data\x125\n" +
+	"\tjson_data\x18\x03 \x01(\v2\x16.google.protobuf.ValueH\x00R\bjsonData\x121\n" +
+	"\x06errors\x18\x04 \x03(\v2\x19.ginkgo.response.v1.ErrorR\x06errors\x12>\n" +
+	"\n" +
+	"pagination\x18\x05 \x01(\v2\x1e.ginkgo.response.v1.PaginationR\n" +
+	"pagination\x12+\n" +
+	"\x04meta\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x04metaB\t\n" +
+	"\apayload\"j\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x02 \x01(\tR\terrorCode\x12.\n" +
+	"\x06detail\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\x06detail\"\xb7\x01\n" +
+	"\n" +
+	"Pagination\x12\x1d\n" +
+	"\n" +
+	"total_data\x18\x01 \x01(\x03R\ttotalData\x12!\n" +
+	"\fcurrent_page\x18\x02 \x01(\x03R\vcurrentPage\x12\x1f\n" +
+	"\vtotal_pages\x18\x03 \x01(\x03R\n" +
+	"totalPages\x12\"\n" +
+	"\rhas_next_page\x18\x04 \x01(\bR\vhasNextPage\x12\"\n" +
+	"\rhas_prev_page\x18\x05 \x01(\bR\vhasPrevPageB4Z2github.com/itsLeonB/ginkgo/pkg/response/responsepbb\x06proto3"
+
+var (
+	file_envelope_proto_rawDescOnce sync.Once
+	file_envelope_proto_rawDescData []byte
+)
+
+func file_envelope_proto_rawDescGZIP() []byte {
+	file_envelope_proto_rawDescOnce.Do(func() {
+		file_envelope_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_envelope_proto_rawDesc), len(file_envelope_proto_rawDesc)))
+	})
+	return file_envelope_proto_rawDescData
+}
+
+var file_envelope_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_envelope_proto_goTypes = []any{
+	(*Response)(nil),        // 0: ginkgo.response.v1.Response
+	(*Error)(nil),           // 1: ginkgo.response.v1.Error
+	(*Pagination)(nil),      // 2: ginkgo.response.v1.Pagination
+	(*anypb.Any)(nil),       // 3: google.protobuf.Any
+	(*structpb.Value)(nil),  // 4: google.protobuf.Value
+	(*structpb.Struct)(nil), // 5: google.protobuf.Struct
+}
+var file_envelope_proto_depIdxs = []int32{
+	3, // 0: ginkgo.response.v1.Response.data:type_name -> google.protobuf.Any
+	4, // 1: ginkgo.response.v1.Response.json_data:type_name -> google.protobuf.Value
+	1, // 2: ginkgo.response.v1.Response.errors:type_name -> ginkgo.response.v1.Error
+	2, // 3: ginkgo.response.v1.Response.pagination:type_name -> ginkgo.response.v1.Pagination
+	5, // 4: ginkgo.response.v1.Response.meta:type_name -> google.protobuf.Struct
+	4, // 5: ginkgo.response.v1.Error.detail:type_name -> google.protobuf.Value
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_envelope_proto_init() }
+func file_envelope_proto_init() {
+	if File_envelope_proto != nil {
+		return
+	}
+	file_envelope_proto_msgTypes[0].OneofWrappers = []any{
+		(*Response_Data)(nil),
+		(*Response_JsonData)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_envelope_proto_rawDesc), len(file_envelope_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_envelope_proto_goTypes,
+		DependencyIndexes: file_envelope_proto_depIdxs,
+		MessageInfos:      file_envelope_proto_msgTypes,
+	}.Build()
+	File_envelope_proto = out.File
+	file_envelope_proto_goTypes = nil
+	file_envelope_proto_depIdxs = nil
+}
@@ -0,0 +1,5 @@
+// Package responsepb holds the protobuf encoding of the standard response envelope,
+// defined in envelope.proto, which clients can use to decode application/x-protobuf responses.
+package responsepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative envelope.proto
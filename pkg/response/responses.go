@@ -1,6 +1,9 @@
 package response
 
-import "math"
+import (
+	"maps"
+	"math"
+)
 
 // QueryOptions represents common pagination query parameters for HTTP requests.
 // It includes validation tags to ensure proper values for page and limit parameters.
@@ -26,11 +29,14 @@ func (p Pagination) IsZero() bool {
 }
 
 // JSONResponse represents a standardized HTTP JSON response structure.
-// It can include a message, data payload, error information, and pagination metadata.
+// It can include a message, data payload, error information, pagination metadata,
+// and other metadata such as processing time or deprecation notices.
 type JSONResponse struct {
-	Data       any        `json:"data,omitzero"`
-	Errors     []error    `json:"errors,omitempty"`
-	Pagination Pagination `json:"pagination,omitzero"`
+	Message    string         `json:"message,omitempty"`
+	Data       any            `json:"data,omitzero"`
+	Errors     []error        `json:"errors,omitempty"`
+	Pagination Pagination     `json:"pagination,omitzero"`
+	Meta       map[string]any `json:"meta,omitempty"`
 }
 
 // NewResponse creates a basic JSONResponse with the specified message.
@@ -49,6 +55,23 @@ func NewErrorResponse(err ...error) JSONResponse {
 	}
 }
 
+// WithMessage returns a copy of the JSONResponse with the given message.
+// Pass a message key through middleware.Translate to localize it.
+func (jr JSONResponse) WithMessage(message string) JSONResponse {
+	jr.Message = message
+	return jr
+}
+
+// WithMeta returns a copy of the JSONResponse with the metadata entry key set to value.
+// The receiver's Meta map is copied, so responses built from a shared base don't affect each other.
+func (jr JSONResponse) WithMeta(key string, value any) JSONResponse {
+	meta := make(map[string]any, len(jr.Meta)+1)
+	maps.Copy(meta, jr.Meta)
+	meta[key] = value
+	jr.Meta = meta
+	return jr
+}
+
 // WithPagination calculates and adds pagination metadata to the JSONResponse.
 // It computes total pages and next/previous flags based on query options and total data count.
 // Returns a new JSONResponse with pagination metadata included.
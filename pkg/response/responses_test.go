@@ -1,6 +1,7 @@
 package response
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -20,6 +21,13 @@ func TestNewResponse(t *testing.T) {
 	})
 }
 
+func TestWithMessage(t *testing.T) {
+	resp := NewResponse("data").WithMessage("created")
+
+	assert.Equal(t, "created", resp.Message)
+	assert.Equal(t, "data", resp.Data)
+}
+
 func TestNewErrorResponse(t *testing.T) {
 	err := errors.New("something went wrong")
 	resp := NewErrorResponse(err)
@@ -74,3 +82,19 @@ func TestPagination(t *testing.T) {
 		assert.False(t, p.IsZero())
 	})
 }
+
+func TestWithMeta(t *testing.T) {
+	base := NewResponse("data").WithMeta("requestId", "req-1")
+	resp := base.WithMeta("processingTimeMs", 12)
+
+	assert.Equal(t, map[string]any{"requestId": "req-1"}, base.Meta)
+	assert.Equal(t, map[string]any{"requestId": "req-1", "processingTimeMs": 12}, resp.Meta)
+
+	data, err := json.Marshal(resp)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data":"data","meta":{"requestId":"req-1","processingTimeMs":12}}`, string(data))
+
+	data, err = json.Marshal(NewResponse("data"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data":"data"}`, string(data))
+}
@@ -0,0 +1,71 @@
+package response
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NDJSONContentType is the media type of newline-delimited JSON streams.
+const NDJSONContentType = "application/x-ndjson"
+
+// streamFlushInterval bounds how long encoded items may sit in the response buffer.
+const streamFlushInterval = time.Second
+
+// StreamJSON writes every item received from items as one line of JSON, for responses such as
+// large exports that are too big to buffer in a JSONResponse. Items are encoded with the
+// encoder set by SetEncoder and flushed to the client at least every second.
+// It returns nil once items is closed, or the context's error when the client disconnects.
+// The producer should stop on ctx.Request.Context().Done() too, since items is no longer
+// drained then. The status is already sent when StreamJSON returns, so errors cannot be
+// answered with an error response and must not be attached to the context.
+func StreamJSON[T any](ctx *gin.Context, items <-chan T) error {
+	ctx.Header("Content-Type", NDJSONContentType)
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(streamFlushInterval)
+	defer ticker.Stop()
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	done := ctx.Request.Context().Done()
+	pending := false
+	for {
+		select {
+		case <-done:
+			return ctx.Request.Context().Err()
+		case <-ticker.C:
+			if pending {
+				ctx.Writer.Flush()
+				pending = false
+			}
+		case item, ok := <-items:
+			if !ok {
+				ctx.Writer.Flush()
+				return nil
+			}
+			buf.Reset()
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+			line := append(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), '\n')
+			if _, err := ctx.Writer.Write(line); err != nil {
+				if ctxErr := ctx.Request.Context().Err(); ctxErr != nil {
+					return ctxErr
+				}
+				return err
+			}
+			pending = true
+		}
+	}
+}
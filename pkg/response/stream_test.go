@@ -0,0 +1,91 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type row struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	t.Run("writes one line per item", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/export", nil)
+
+		items := make(chan row, 2)
+		items <- row{ID: 1, Name: "a"}
+		items <- row{ID: 2, Name: "b"}
+		close(items)
+
+		err := StreamJSON(c, items)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, NDJSONContentType, w.Header().Get("Content-Type"))
+		assert.True(t, w.Flushed)
+		assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", w.Body.String())
+	})
+
+	t.Run("stops when the client disconnects", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		reqCtx, cancel := context.WithCancel(context.Background())
+		c.Request = httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(reqCtx)
+
+		items := make(chan row)
+		cancel()
+
+		err := StreamJSON(c, items)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("custom encoder without trailing newline", func(t *testing.T) {
+		SetEncoder(func(w io.Writer, v any) error {
+			_, err := io.WriteString(w, `{"custom":true}`)
+			return err
+		})
+		defer SetEncoder(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/export", nil)
+
+		items := make(chan int, 2)
+		items <- 1
+		items <- 2
+		close(items)
+
+		assert.NoError(t, StreamJSON(c, items))
+		assert.Equal(t, "{\"custom\":true}\n{\"custom\":true}\n", w.Body.String())
+	})
+
+	t.Run("encoder error", func(t *testing.T) {
+		SetEncoder(func(w io.Writer, v any) error { return errors.New("boom") })
+		defer SetEncoder(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/export", nil)
+
+		items := make(chan int, 1)
+		items <- 1
+
+		assert.EqualError(t, StreamJSON(c, items), "boom")
+		assert.Empty(t, c.Errors)
+	})
+}
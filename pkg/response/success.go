@@ -0,0 +1,33 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OK responds 200 OK with data and an optional message, negotiated like Respond.
+func OK(ctx *gin.Context, msg string, data any) {
+	Respond(ctx, http.StatusOK, NewResponse(data).WithMessage(msg))
+}
+
+// Created responds 201 Created with data and an optional message, negotiated like Respond.
+// location is the URL of the new resource, sent as the Location header unless empty.
+func Created(ctx *gin.Context, msg string, data any, location string) {
+	if location != "" {
+		ctx.Header("Location", location)
+	}
+	Respond(ctx, http.StatusCreated, NewResponse(data).WithMessage(msg))
+}
+
+// Accepted responds 202 Accepted with data, e.g. the ID of a queued job, and an optional
+// message, negotiated like Respond.
+func Accepted(ctx *gin.Context, msg string, data any) {
+	Respond(ctx, http.StatusAccepted, NewResponse(data).WithMessage(msg))
+}
+
+// NoContent responds 204 No Content without a body.
+func NoContent(ctx *gin.Context) {
+	ctx.Status(http.StatusNoContent)
+	ctx.Writer.WriteHeaderNow()
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuccessHelpers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("OK", func(t *testing.T) {
+		c, w := newNegotiatedContext("")
+		OK(c, "fetched", gin.H{"id": 1})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message":"fetched","data":{"id":1}}`, w.Body.String())
+	})
+
+	t.Run("Created", func(t *testing.T) {
+		c, w := newNegotiatedContext("")
+		Created(c, "created", gin.H{"id": 7}, "/orders/7")
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/orders/7", w.Header().Get("Location"))
+		assert.JSONEq(t, `{"message":"created","data":{"id":7}}`, w.Body.String())
+	})
+
+	t.Run("Created without location", func(t *testing.T) {
+		c, w := newNegotiatedContext("")
+		Created(c, "", gin.H{"id": 7}, "")
+
+		assert.Empty(t, w.Header().Get("Location"))
+		assert.JSONEq(t, `{"data":{"id":7}}`, w.Body.String())
+	})
+
+	t.Run("Accepted", func(t *testing.T) {
+		c, w := newNegotiatedContext("application/xml")
+		Accepted(c, "queued", gin.H{"jobId": "j-1"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), "<jobId>j-1</jobId>")
+	})
+
+	t.Run("NoContent", func(t *testing.T) {
+		c, w := newNegotiatedContext("")
+		NoContent(c)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
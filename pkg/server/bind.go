@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/itsLeonB/ungerr"
+)
+
+// BindAll binds every part of the request into a struct of type T in one call: path
+// parameters into fields tagged uri, the query string into fields tagged form, headers
+// into fields tagged header, and the body, chosen by its Content-Type like gin.Context.Bind
+// does. A source is only read if T has a field with its tag, so fields don't pick up
+// headers that happen to share their name. Sources bound later override earlier ones.
+// Fields no source sets keep the value of their default tag, like with BindRequest.
+// T is validated once after all sources are bound, so a request with several invalid
+// fields gets a single validation error listing all of them.
+func BindAll[T any](ctx *gin.Context) (T, error) {
+	var req T
+	if err := applyDefaults(&req); err != nil {
+		return req, ungerr.Wrap(err, "failed to apply request defaults")
+	}
+
+	params := make(map[string][]string, len(ctx.Params))
+	for _, param := range ctx.Params {
+		params[param.Key] = append(params[param.Key], param.Value)
+	}
+
+	sources := []struct {
+		tag  string
+		bind func() error
+	}{
+		{"uri", func() error { return binding.Uri.BindUri(params, &req) }},
+		{"form", func() error { return binding.Query.Bind(ctx.Request, &req) }},
+		{"header", func() error { return binding.Header.Bind(ctx.Request, &req) }},
+	}
+	for _, source := range sources {
+		if !hasFieldTag(reflect.TypeFor[T](), source.tag) {
+			continue
+		}
+		if err := source.bind(); err != nil && !isValidationError(err) {
+			return req, ungerr.Wrapf(err, "failed to bind request %s", source.tag)
+		}
+	}
+
+	if hasBody(ctx.Request) {
+		bindType := binding.Default(ctx.Request.Method, ctx.ContentType())
+		if err := bindType.Bind(ctx.Request, &req); err != nil && !isValidationError(err) {
+			return req, ungerr.Wrapf(err, "failed to bind request with type %s", bindType.Name())
+		}
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return req, ungerr.Wrap(err, "invalid request")
+	}
+
+	return req, nil
+}
+
+// isValidationError reports whether err comes from validating a bound struct rather than
+// from decoding the request. BindAll validates once after binding all sources, as fields
+// bound by a later source would fail validation of the earlier ones.
+func isValidationError(err error) bool {
+	var validationErrs validator.ValidationErrors
+	return errors.As(err, &validationErrs)
+}
+
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
+
+// hasFieldTag reports whether struct type t, or a struct embedded in it, has a field with tag.
+func hasFieldTag(t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+		if field.Anonymous && hasFieldTag(field.Type, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type updateOrderRequest struct {
+	ID        int    `uri:"id" binding:"required,min=1"`
+	DryRun    bool   `form:"dry_run"`
+	RequestID string `header:"X-Request-ID" binding:"required"`
+	Status    string `json:"status" binding:"required,oneof=paid shipped"`
+	Note      string `json:"note"`
+}
+
+func newBindAllRouter(t *testing.T, got *updateOrderRequest) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.PUT("/orders/:id", func(ctx *gin.Context) {
+		req, err := server.BindAll[updateOrderRequest](ctx)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		*got = req
+		ctx.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestBindAll(t *testing.T) {
+	t.Run("binds every source", func(t *testing.T) {
+		var got updateOrderRequest
+		r := newBindAllRouter(t, &got)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/orders/42?dry_run=true", strings.NewReader(`{"status":"paid","note":"leave at door"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "req-1")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, updateOrderRequest{
+			ID:        42,
+			DryRun:    true,
+			RequestID: "req-1",
+			Status:    "paid",
+			Note:      "leave at door",
+		}, got)
+	})
+
+	t.Run("one validation error for all sources", func(t *testing.T) {
+		var got updateOrderRequest
+		r := newBindAllRouter(t, &got)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/orders/0", strings.NewReader(`{"status":"lost"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		var resp struct {
+			Errors []struct {
+				ErrorCode middleware.ErrorCode `json:"errorCode"`
+				Detail    map[string]string    `json:"detail"`
+			} `json:"errors"`
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, middleware.ErrorCodeValidationFailed, resp.Errors[0].ErrorCode)
+		assert.ElementsMatch(t, []string{"id", "X-Request-ID", "status"}, keysOf(resp.Errors[0].Detail))
+	})
+
+	t.Run("decoding error", func(t *testing.T) {
+		var got updateOrderRequest
+		r := newBindAllRouter(t, &got)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/orders/1", strings.NewReader(`{"status":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "req-1")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ignores sources without tags", func(t *testing.T) {
+		type search struct {
+			Date string `json:"date"`
+		}
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?Date=query", nil)
+		c.Request.Header.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+
+		got, err := server.BindAll[search](c)
+		assert.NoError(t, err)
+		assert.Empty(t, got.Date)
+	})
+}
+
+func keysOf(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
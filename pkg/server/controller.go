@@ -0,0 +1,86 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// RouteDef declares a route of a Controller. Middleware names middlewares of the set passed
+// to RegisterControllers; they run in the set's order, not in the order listed here.
+type RouteDef struct {
+	Method     string
+	Path       string
+	Handler    gin.HandlerFunc
+	Middleware []string
+}
+
+// Controller groups the routes of a resource or feature.
+type Controller interface {
+	Routes() []RouteDef
+}
+
+// NamedMiddleware is a middleware that routes can refer to by name.
+type NamedMiddleware struct {
+	Name    string
+	Handler gin.HandlerFunc
+}
+
+// RegisterControllers registers the routes of controllers on the engine of registry. middlewares is the set of
+// middlewares routes may use, in the order they run in, e.g. auth before permission checks,
+// so every route gets a consistent ordering regardless of how its RouteDef lists them.
+// The middleware names of each route are recorded in registry. It fails without registering
+// anything if a route uses an unknown middleware or is declared twice.
+func RegisterControllers(registry *RouteRegistry, middlewares []NamedMiddleware, controllers ...Controller) error {
+	byName := make(map[string]int, len(middlewares))
+	for i, mw := range middlewares {
+		if _, ok := byName[mw.Name]; ok {
+			return ungerr.Unknownf("middleware %s is declared twice", mw.Name)
+		}
+		byName[mw.Name] = i
+	}
+
+	type route struct {
+		def   RouteDef
+		chain []int
+	}
+	var routes []route
+	seen := make(map[string]struct{})
+	for _, controller := range controllers {
+		for _, def := range controller.Routes() {
+			key := def.Method + " " + def.Path
+			if _, ok := seen[key]; ok {
+				return ungerr.Unknownf("route %s is declared twice", key)
+			}
+			seen[key] = struct{}{}
+
+			chain := make([]int, 0, len(def.Middleware))
+			for _, name := range def.Middleware {
+				i, ok := byName[name]
+				if !ok {
+					return ungerr.Unknownf("route %s uses unknown middleware %s", key, name)
+				}
+				if !slices.Contains(chain, i) {
+					chain = append(chain, i)
+				}
+			}
+			slices.Sort(chain)
+			routes = append(routes, route{def, chain})
+		}
+	}
+
+	for _, r := range routes {
+		handlers := make([]gin.HandlerFunc, 0, len(r.chain)+1)
+		names := make([]string, 0, len(r.chain))
+		for _, i := range r.chain {
+			handlers = append(handlers, middlewares[i].Handler)
+			names = append(names, middlewares[i].Name)
+		}
+		registry.engine.Handle(r.def.Method, r.def.Path, append(handlers, r.def.Handler)...)
+		if len(names) > 0 {
+			registry.SetMiddleware(r.def.Method, r.def.Path, names...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+type routeList []server.RouteDef
+
+func (rl routeList) Routes() []server.RouteDef { return rl }
+
+func TestRegisterControllers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	trace := func(name string) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			ctx.Set("trace", ctx.GetString("trace")+name+",")
+		}
+	}
+	middlewares := []server.NamedMiddleware{
+		{Name: "auth", Handler: trace("auth")},
+		{Name: "permission", Handler: trace("permission")},
+		{Name: "cache", Handler: trace("cache")},
+	}
+	handler := func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, strings.TrimSuffix(ctx.GetString("trace"), ","))
+	}
+
+	t.Run("registers routes with ordered middleware", func(t *testing.T) {
+		r := gin.New()
+		registry := server.NewRouteRegistry(r)
+		err := server.RegisterControllers(registry, middlewares,
+			routeList{
+				{Method: http.MethodGet, Path: "/users", Handler: handler, Middleware: []string{"cache", "auth"}},
+				{Method: http.MethodPost, Path: "/users", Handler: handler, Middleware: []string{"permission", "auth", "auth"}},
+			},
+			routeList{
+				{Method: http.MethodGet, Path: "/health", Handler: handler},
+			},
+		)
+		assert.NoError(t, err)
+
+		for path, expected := range map[string]string{
+			"GET /users":  "auth,cache",
+			"POST /users": "auth,permission",
+			"GET /health": "",
+		} {
+			method, target, _ := strings.Cut(path, " ")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+			assert.Equal(t, expected, w.Body.String(), path)
+		}
+
+		routes := registry.Routes()
+		assert.Len(t, routes, 3)
+		assert.Nil(t, routes[0].Middleware)
+		assert.Equal(t, []string{"auth", "cache"}, routes[1].Middleware)
+		assert.Equal(t, []string{"auth", "permission"}, routes[2].Middleware)
+	})
+
+	t.Run("unknown middleware", func(t *testing.T) {
+		r := gin.New()
+		err := server.RegisterControllers(server.NewRouteRegistry(r), middlewares, routeList{
+			{Method: http.MethodGet, Path: "/ok", Handler: handler},
+			{Method: http.MethodGet, Path: "/users", Handler: handler, Middleware: []string{"audit"}},
+		})
+
+		assert.ErrorContains(t, err, "route GET /users uses unknown middleware audit")
+		assert.Empty(t, r.Routes())
+	})
+
+	t.Run("duplicate route", func(t *testing.T) {
+		err := server.RegisterControllers(server.NewRouteRegistry(gin.New()), middlewares,
+			routeList{{Method: http.MethodGet, Path: "/users", Handler: handler}},
+			routeList{{Method: http.MethodGet, Path: "/users", Handler: handler}},
+		)
+
+		assert.ErrorContains(t, err, "route GET /users is declared twice")
+	})
+
+	t.Run("duplicate middleware name", func(t *testing.T) {
+		err := server.RegisterControllers(server.NewRouteRegistry(gin.New()), append(middlewares, server.NamedMiddleware{Name: "auth"}))
+
+		assert.ErrorContains(t, err, "middleware auth is declared twice")
+	})
+}
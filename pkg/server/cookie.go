@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ungerr"
+)
+
+// GetCookie reads and parses the request cookie name, like GetPathParam does for path
+// parameters. It returns false if the cookie is not set, and a 400 Bad Request error if
+// its value cannot be parsed as T.
+func GetCookie[T any](ctx *gin.Context, name string) (T, bool, error) {
+	var zero T
+
+	value, err := ctx.Cookie(name)
+	if err != nil {
+		return zero, false, nil
+	}
+
+	parsed, err := ezutil.Parse[T](value)
+	if err != nil {
+		return zero, true, middleware.WithErrorCode(ungerr.BadRequestError("invalid cookie "+name), middleware.ErrorCodeInvalidFieldValue)
+	}
+
+	return parsed, true, nil
+}
+
+// GetRequiredCookie is like GetCookie but returns a 400 Bad Request error if the cookie
+// is not set.
+func GetRequiredCookie[T any](ctx *gin.Context, name string) (T, error) {
+	value, exists, err := GetCookie[T](ctx, name)
+	if err == nil && !exists {
+		err = ungerr.BadRequestError("missing cookie " + name)
+	}
+	return value, err
+}
+
+// CookieOption configures a cookie set by SetCookie or ClearCookie.
+type CookieOption func(*http.Cookie)
+
+// WithCookiePath scopes the cookie to path. Defaults to "/".
+func WithCookiePath(path string) CookieOption {
+	return func(c *http.Cookie) {
+		if path != "" {
+			c.Path = path
+		}
+	}
+}
+
+// WithCookieDomain makes the cookie available to domain and its subdomains. By default
+// it is only sent to the host that set it.
+func WithCookieDomain(domain string) CookieOption {
+	return func(c *http.Cookie) {
+		c.Domain = domain
+	}
+}
+
+// WithCookieMaxAge makes the cookie expire after maxAge. By default it is a session cookie.
+func WithCookieMaxAge(maxAge time.Duration) CookieOption {
+	return func(c *http.Cookie) {
+		if maxAge >= time.Second {
+			c.MaxAge = int(maxAge.Seconds())
+		}
+	}
+}
+
+// WithCookieSameSite sets the cookie's SameSite attribute. Defaults to http.SameSiteLaxMode.
+func WithCookieSameSite(mode http.SameSite) CookieOption {
+	return func(c *http.Cookie) {
+		c.SameSite = mode
+	}
+}
+
+// WithCookieInsecure lets the cookie be sent over plain HTTP, e.g. in local development.
+func WithCookieInsecure() CookieOption {
+	return func(c *http.Cookie) {
+		c.Secure = false
+	}
+}
+
+// WithCookieScriptAccess lets scripts read the cookie through document.cookie.
+func WithCookieScriptAccess() CookieOption {
+	return func(c *http.Cookie) {
+		c.HttpOnly = false
+	}
+}
+
+// SetCookie sets a cookie on the response. Unless configured otherwise, the cookie is
+// sent for every path over HTTPS only, is hidden from scripts and has SameSite=Lax.
+// The value is escaped like gin.Context.SetCookie does, so GetCookie reads it back as is.
+func SetCookie(ctx *gin.Context, name, value string, opts ...CookieOption) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		Path:     "/",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	for _, opt := range opts {
+		opt(cookie)
+	}
+	http.SetCookie(ctx.Writer, cookie)
+}
+
+// ClearCookie tells the client to delete the cookie name. Pass the path and domain
+// options it was set with, as the client only deletes a cookie that matches them.
+func ClearCookie(ctx *gin.Context, name string, opts ...CookieOption) {
+	SetCookie(ctx, name, "", append(opts, func(c *http.Cookie) { c.MaxAge = -1 })...)
+}
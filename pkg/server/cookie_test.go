@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCookieContext(cookies ...*http.Cookie) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		c.Request.AddCookie(cookie)
+	}
+	return c
+}
+
+func TestGetCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid cookie", func(t *testing.T) {
+		c := newCookieContext(&http.Cookie{Name: "page_size", Value: "50"})
+
+		val, exists, err := server.GetCookie[int](c, "page_size")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, 50, val)
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		val, exists, err := server.GetCookie[int](newCookieContext(), "page_size")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		assert.Zero(t, val)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		c := newCookieContext(&http.Cookie{Name: "page_size", Value: "many"})
+
+		_, exists, err := server.GetCookie[int](c, "page_size")
+		assert.True(t, exists)
+		var appErr ungerr.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.HttpStatus())
+		assert.Equal(t, "invalid cookie page_size", appErr.Details())
+		code, _ := middleware.ErrorCodeOf(err)
+		assert.Equal(t, middleware.ErrorCodeInvalidFieldValue, code)
+	})
+}
+
+func TestGetRequiredCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid cookie", func(t *testing.T) {
+		c := newCookieContext(&http.Cookie{Name: "theme", Value: "dark"})
+
+		val, err := server.GetRequiredCookie[string](c, "theme")
+		assert.NoError(t, err)
+		assert.Equal(t, "dark", val)
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		_, err := server.GetRequiredCookie[string](newCookieContext(), "theme")
+
+		var appErr ungerr.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.HttpStatus())
+		assert.Equal(t, "missing cookie theme", appErr.Details())
+	})
+}
+
+func TestSetCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("secure defaults", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		server.SetCookie(c, "greeting", "hello world")
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "hello+world", cookies[0].Value)
+		assert.Equal(t, "/", cookies[0].Path)
+		assert.True(t, cookies[0].Secure)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		assert.Zero(t, cookies[0].MaxAge)
+
+		val, err := server.GetRequiredCookie[string](newCookieContext(cookies[0]), "greeting")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", val)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		server.SetCookie(c, "session", "abc",
+			server.WithCookiePath("/app"),
+			server.WithCookieDomain("example.com"),
+			server.WithCookieMaxAge(time.Hour),
+			server.WithCookieSameSite(http.SameSiteStrictMode),
+			server.WithCookieInsecure(),
+			server.WithCookieScriptAccess(),
+		)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "/app", cookies[0].Path)
+		assert.Equal(t, "example.com", cookies[0].Domain)
+		assert.Equal(t, 3600, cookies[0].MaxAge)
+		assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+		assert.False(t, cookies[0].Secure)
+		assert.False(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		server.ClearCookie(c, "session", server.WithCookiePath("/app"))
+
+		assert.Equal(t, "session=; Path=/app; Max-Age=0; HttpOnly; Secure; SameSite=Lax", w.Header().Get("Set-Cookie"))
+	})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	type paging struct {
+		Limit int `default:"20"`
+	}
+	type request struct {
+		Name     string        `default:"anonymous"`
+		Active   bool          `default:"true"`
+		Retries  int8          `default:"3"`
+		Size     uint          `default:"512"`
+		Ratio    float64       `default:"0.5"`
+		Timeout  time.Duration `default:"1m30s"`
+		Since    time.Time     `default:"2024-01-02T03:04:05Z"`
+		TenantID uuid.UUID     `default:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+		Tags     []string      `default:"a, b"`
+		Codes    []int         `default:"1,2"`
+		Cursor   *int          `default:"7"`
+		Paging   paging
+		Empty    string
+		hidden   string `default:"x"`
+	}
+
+	var req request
+	require.NoError(t, applyDefaults(&req))
+
+	assert.Equal(t, request{
+		Name:     "anonymous",
+		Active:   true,
+		Retries:  3,
+		Size:     512,
+		Ratio:    0.5,
+		Timeout:  90 * time.Second,
+		Since:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		TenantID: uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		Tags:     []string{"a", "b"},
+		Codes:    []int{1, 2},
+		Cursor:   req.Cursor,
+		Paging:   paging{Limit: 20},
+	}, req)
+	if assert.NotNil(t, req.Cursor) {
+		assert.Equal(t, 7, *req.Cursor)
+	}
+
+	t.Run("invalid default", func(t *testing.T) {
+		var bad struct {
+			Limit int8 `default:"1000"`
+		}
+		assert.ErrorContains(t, applyDefaults(&bad), `invalid default "1000" of field .Limit`)
+
+		var unsupported struct {
+			Lookup map[string]int `default:"a"`
+		}
+		assert.ErrorContains(t, applyDefaults(&unsupported), "unsupported type map[string]int")
+	})
+}
+
+func TestBindDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type searchRequest struct {
+		Query string `form:"q" json:"q"`
+		Page  int    `form:"page" json:"page" default:"1"`
+		Limit int    `form:"limit" json:"limit" default:"20" binding:"max=100"`
+	}
+
+	t.Run("BindRequest", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?q=shoes&limit=50", nil)
+
+		req, err := BindRequest[searchRequest](c, binding.Query)
+		require.NoError(t, err)
+		assert.Equal(t, searchRequest{Query: "shoes", Page: 1, Limit: 50}, req)
+	})
+
+	t.Run("BindJSON", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"q":"shoes","page":0}`))
+
+		req, err := BindJSON[searchRequest](c)
+		require.NoError(t, err)
+		assert.Equal(t, searchRequest{Query: "shoes", Page: 0, Limit: 20}, req, "values sent override defaults")
+	})
+
+	t.Run("BindAll", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?page=3", nil)
+
+		req, err := BindAll[searchRequest](c)
+		require.NoError(t, err)
+		assert.Equal(t, searchRequest{Page: 3, Limit: 20}, req)
+	})
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// DocsUI selects the documentation viewer served by MountDocs.
+type DocsUI int
+
+const (
+	DocsSwaggerUI DocsUI = iota
+	DocsRedoc
+)
+
+const (
+	defaultSwaggerUIAssets = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5.17.14"
+	defaultRedocAssets     = "https://cdn.jsdelivr.net/npm/redoc@2.1.5/bundles"
+)
+
+//go:embed docs/*.html
+var docsTemplates embed.FS
+
+var docsPages = template.Must(template.ParseFS(docsTemplates, "docs/*.html"))
+
+// DocsConfig configures MountDocs.
+type DocsConfig struct {
+	// UI is the viewer, Swagger UI by default.
+	UI DocsUI
+	// Title is the page title. Defaults to "API documentation".
+	Title string
+	// Spec is the OpenAPI document, in JSON or YAML, served next to the page.
+	Spec []byte
+	// SpecURL is the URL of the OpenAPI document if Spec is empty, e.g. one served elsewhere.
+	SpecURL string
+	// AssetsURL is where the viewer's scripts and stylesheets are loaded from, e.g. a
+	// self-hosted copy of swagger-ui-dist. Defaults to a pinned version on jsDelivr.
+	AssetsURL string
+	// Auth guards the page and the document if set, e.g. an auth or IP allowlist middleware.
+	Auth gin.HandlerFunc
+}
+
+// MountDocs serves an API documentation page at relativePath of router, and cfg.Spec at
+// relativePath + "/openapi.json" or "/openapi.yaml", depending on its format.
+func MountDocs(router gin.IRouter, relativePath string, cfg DocsConfig) {
+	if len(cfg.Spec) == 0 && cfg.SpecURL == "" {
+		log.Fatal("either Spec or SpecURL is required")
+	}
+	if cfg.Title == "" {
+		cfg.Title = "API documentation"
+	}
+	page, assetsURL := "swagger-ui.html", defaultSwaggerUIAssets
+	if cfg.UI == DocsRedoc {
+		page, assetsURL = "redoc.html", defaultRedocAssets
+	}
+	if cfg.AssetsURL != "" {
+		assetsURL = strings.TrimSuffix(cfg.AssetsURL, "/")
+	}
+
+	guarded := func(handler gin.HandlerFunc) []gin.HandlerFunc {
+		if cfg.Auth != nil {
+			return []gin.HandlerFunc{cfg.Auth, handler}
+		}
+		return []gin.HandlerFunc{handler}
+	}
+	relativePath = strings.TrimSuffix(relativePath, "/")
+
+	specFile, specType := "openapi.yaml", "application/yaml"
+	if trimmed := bytes.TrimSpace(cfg.Spec); len(trimmed) > 0 && trimmed[0] == '{' {
+		specFile, specType = "openapi.json", "application/json"
+	}
+	if len(cfg.Spec) > 0 {
+		router.GET(relativePath+"/"+specFile, guarded(func(ctx *gin.Context) {
+			ctx.Data(http.StatusOK, specType, cfg.Spec)
+		})...)
+	}
+
+	router.GET(relativePath, guarded(func(ctx *gin.Context) {
+		specURL := cfg.SpecURL
+		if len(cfg.Spec) > 0 {
+			// FullPath includes the prefix of the router group.
+			specURL = strings.TrimSuffix(ctx.FullPath(), "/") + "/" + specFile
+		}
+
+		var buf bytes.Buffer
+		if err := docsPages.ExecuteTemplate(&buf, page, map[string]string{
+			"Title":     cfg.Title,
+			"SpecURL":   specURL,
+			"AssetsURL": assetsURL,
+		}); err != nil {
+			_ = ctx.Error(ungerr.Wrap(err, "failed to render docs page"))
+			return
+		}
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	})...)
+}
@@ -0,0 +1,77 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMountDocs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(r *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("swagger ui with embedded spec", func(t *testing.T) {
+		r := gin.New()
+		server.MountDocs(r.Group("/api"), "/docs", server.DocsConfig{
+			Title: "Orders API",
+			Spec:  []byte(`{"openapi":"3.1.0"}`),
+		})
+
+		w := serve(r, "/api/docs")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "<title>Orders API</title>")
+		assert.Contains(t, w.Body.String(), `url: "/api/docs/openapi.json"`)
+		assert.Contains(t, w.Body.String(), "swagger-ui-dist@")
+
+		w = serve(r, "/api/docs/openapi.json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"openapi":"3.1.0"}`, w.Body.String())
+	})
+
+	t.Run("redoc with yaml spec and self-hosted assets", func(t *testing.T) {
+		r := gin.New()
+		server.MountDocs(r, "/docs/", server.DocsConfig{
+			UI:        server.DocsRedoc,
+			Spec:      []byte("openapi: 3.1.0\n"),
+			AssetsURL: "/static/redoc/",
+		})
+
+		w := serve(r, "/docs")
+		assert.Contains(t, w.Body.String(), `<redoc spec-url="/docs/openapi.yaml">`)
+		assert.Contains(t, w.Body.String(), `src="/static/redoc/redoc.standalone.js"`)
+		assert.Equal(t, "application/yaml", serve(r, "/docs/openapi.yaml").Header().Get("Content-Type"))
+	})
+
+	t.Run("spec url and auth", func(t *testing.T) {
+		r := gin.New()
+		server.MountDocs(r, "/docs", server.DocsConfig{
+			SpecURL: "https://example.com/openapi.json",
+			Auth: func(ctx *gin.Context) {
+				if ctx.GetHeader("Authorization") == "" {
+					ctx.AbortWithStatus(http.StatusUnauthorized)
+				}
+			},
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, serve(r, "/docs").Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "/docs/openapi.json").Code)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `url: "https://example.com/openapi.json"`)
+	})
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// ServeFileDownload sends content as a file attachment named name. It supports Range and
+// If-Range requests so interrupted downloads can be resumed, and conditional requests
+// based on modTime, which may be zero if unknown. The Content-Type is derived from the
+// extension of name, or sniffed from content. Seek and read errors are attached to the
+// context for the error middleware; errors after the body has started only get logged,
+// since the response can no longer be replaced.
+func ServeFileDownload(ctx *gin.Context, name string, modTime time.Time, content io.ReadSeeker) {
+	if _, err := content.Seek(0, io.SeekEnd); err != nil {
+		_ = ctx.Error(ungerr.Wrapf(err, "failed to determine size of download %s", name))
+		ctx.Abort()
+		return
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		_ = ctx.Error(ungerr.Wrapf(err, "failed to rewind download %s", name))
+		ctx.Abort()
+		return
+	}
+
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+
+	reader := &downloadReader{ReadSeeker: content}
+	http.ServeContent(ctx.Writer, ctx.Request, name, modTime, reader)
+	if reader.err != nil {
+		_ = ctx.Error(ungerr.Wrapf(reader.err, "failed to read download %s", name))
+		ctx.Abort()
+	}
+}
+
+// downloadReader records the first read error, which http.ServeContent discards.
+type downloadReader struct {
+	io.ReadSeeker
+	err error
+}
+
+func (dr *downloadReader) Read(p []byte) (int, error) {
+	n, err := dr.ReadSeeker.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && dr.err == nil {
+		dr.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingSeeker struct {
+	io.ReadSeeker
+	seekErr error
+	readErr error
+	reads   int
+}
+
+func (fs *failingSeeker) Seek(offset int64, whence int) (int64, error) {
+	if fs.seekErr != nil {
+		return 0, fs.seekErr
+	}
+	return fs.ReadSeeker.Seek(offset, whence)
+}
+
+func (fs *failingSeeker) Read(p []byte) (int, error) {
+	if fs.readErr != nil && fs.reads > 0 {
+		return 0, fs.readErr
+	}
+	fs.reads++
+	return fs.ReadSeeker.Read(p[:min(len(p), 4)])
+}
+
+func TestServeFileDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	serve := func(content io.ReadSeeker, header http.Header) *httptest.ResponseRecorder {
+		mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware())
+		r.GET("/download", func(ctx *gin.Context) {
+			ServeFileDownload(ctx, "report 2024.csv", modTime, content)
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/download", nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("full download", func(t *testing.T) {
+		w := serve(strings.NewReader("id,name\n1,a\n"), nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="report 2024.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+		assert.Equal(t, modTime.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+		assert.Equal(t, "id,name\n1,a\n", w.Body.String())
+	})
+
+	t.Run("range request", func(t *testing.T) {
+		w := serve(strings.NewReader("id,name\n1,a\n"), http.Header{"Range": {"bytes=8-"}})
+
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "bytes 8-11/12", w.Header().Get("Content-Range"))
+		assert.Equal(t, "1,a\n", w.Body.String())
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		w := serve(strings.NewReader("id,name\n"), http.Header{
+			"If-Modified-Since": {modTime.Format(http.TimeFormat)},
+		})
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("seek error goes through error middleware", func(t *testing.T) {
+		w := serve(&failingSeeker{ReadSeeker: strings.NewReader("x"), seekErr: errors.New("bad seek")}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("read error mid-body keeps partial response", func(t *testing.T) {
+		content := &failingSeeker{ReadSeeker: strings.NewReader("id,name\n1,a\n"), readErr: errors.New("disk failed")}
+		w := serve(content, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "id,n", w.Body.String())
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultDrainLogInterval = 5 * time.Second
+
+// inFlightTracker counts the connections that are serving a request, through the
+// http.Server's ConnState callback.
+type inFlightTracker struct {
+	active atomic.Int64
+	states sync.Map // net.Conn -> http.ConnState
+}
+
+func (t *inFlightTracker) track(srv *http.Server) {
+	next := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		prev, _ := t.states.Swap(conn, state)
+		if prev == http.StateActive && state != http.StateActive {
+			t.active.Add(-1)
+		}
+		if prev != http.StateActive && state == http.StateActive {
+			t.active.Add(1)
+		}
+		if state == http.StateHijacked || state == http.StateClosed {
+			t.states.Delete(conn)
+		}
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+func (t *inFlightTracker) count() int64 {
+	return t.active.Load()
+}
+
+// WithDrainLogInterval sets how often shutting down logs the number of requests still in
+// flight. Defaults to 5 seconds.
+func WithDrainLogInterval(interval time.Duration) HttpOption {
+	return func(hs *Http) {
+		if interval > 0 {
+			hs.drainLogInterval = interval
+		}
+	}
+}
+
+// drain shuts srv down, logging the requests still in flight every drain log interval. If
+// ctx is done first, the remaining connections are closed forcibly.
+func (hs *Http) drain(ctx context.Context) error {
+	ticker := time.NewTicker(hs.drainLogInterval)
+	defer ticker.Stop()
+
+	done := make(chan error, 1)
+	go func() { done <- hs.srv.Shutdown(ctx) }()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				hs.logger.Warnf("force closing %d in-flight requests after %s", hs.inFlight.count(), hs.timeout)
+				_ = hs.srv.Close()
+			}
+			return err
+		case <-ticker.C:
+			hs.logger.Infof("waiting for %d in-flight requests to finish...", hs.inFlight.count())
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itsLeonB/ezutil/v2"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formatLogger keeps the formatted messages logged through it.
+type formatLogger struct {
+	ezutil.Logger
+	mu       sync.Mutex
+	messages []string
+}
+
+func (fl *formatLogger) record(format string, args []any) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.messages = append(fl.messages, fmt.Sprintf(format, args...))
+}
+
+func (fl *formatLogger) Infof(format string, args ...any)  { fl.record(format, args) }
+func (fl *formatLogger) Warnf(format string, args ...any)  { fl.record(format, args) }
+func (fl *formatLogger) Errorf(format string, args ...any) { fl.record(format, args) }
+
+func (fl *formatLogger) logged() []string {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	return slices.Clone(fl.messages)
+}
+
+func TestDrain(t *testing.T) {
+	logger := &formatLogger{Logger: simple.NewLogger("test", true, 0)}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var connStates sync.Map
+	hs, err := NewServer(
+		&http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				started <- struct{}{}
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			}),
+			ConnState: func(conn net.Conn, state http.ConnState) { connStates.Store(conn, state) },
+		},
+		WithLogger(logger),
+		WithListener(ln),
+		WithShutdownTimeout(200*time.Millisecond),
+		WithDrainLogInterval(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+	go func() { _ = hs.Run(context.Background()) }()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	for range 2 {
+		go func() {
+			if resp, err := client.Get("http://" + ln.Addr().String()); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		<-started
+	}
+	assert.EqualValues(t, 2, hs.inFlight.count())
+
+	err = hs.Shutdown(context.Background())
+	assert.ErrorContains(t, err, "error shutting down")
+	assert.ErrorContains(t, err, "context deadline exceeded")
+	close(release)
+
+	messages := logger.logged()
+	assert.Contains(t, messages, "waiting for 2 in-flight requests to finish...")
+	assert.Contains(t, messages, "force closing 2 in-flight requests after 200ms")
+	assert.Eventually(t, func() bool { return hs.inFlight.count() == 0 }, time.Second, time.Millisecond)
+
+	var seen int
+	connStates.Range(func(any, any) bool {
+		seen++
+		return true
+	})
+	assert.Equal(t, 2, seen, "ConnState set by the caller still runs")
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// HealthChecker checks a dependency of the service, such as a database, for
+// Http.ReadinessHandler.
+type HealthChecker interface {
+	// Name identifies the dependency in readiness responses and logs.
+	Name() string
+	// Check returns an error if the dependency is unavailable.
+	Check(ctx context.Context) error
+}
+
+// Pinger is implemented by clients with a context-aware Ping, such as *pgxpool.Pool.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type pingHealthChecker struct {
+	name    string
+	timeout time.Duration
+	ping    func(ctx context.Context) error
+}
+
+func newPingHealthChecker(name string, timeout time.Duration, ping func(ctx context.Context) error) HealthChecker {
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	return pingHealthChecker{name, timeout, ping}
+}
+
+func (phc pingHealthChecker) Name() string {
+	return phc.name
+}
+
+func (phc pingHealthChecker) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, phc.timeout)
+	defer cancel()
+	return phc.ping(ctx)
+}
+
+// NewSQLHealthChecker checks db with PingContext. A timeout <= 0 defaults to 2 seconds.
+func NewSQLHealthChecker(name string, db *sql.DB, timeout time.Duration) HealthChecker {
+	return newPingHealthChecker(name, timeout, db.PingContext)
+}
+
+// NewPingHealthChecker checks a client with its Ping method, e.g. a *pgxpool.Pool.
+// A timeout <= 0 defaults to 2 seconds.
+func NewPingHealthChecker(name string, pinger Pinger, timeout time.Duration) HealthChecker {
+	return newPingHealthChecker(name, timeout, pinger.Ping)
+}
+
+// NewRedisHealthChecker checks client with PING. A timeout <= 0 defaults to 2 seconds.
+func NewRedisHealthChecker(name string, client redis.UniversalClient, timeout time.Duration) HealthChecker {
+	return newPingHealthChecker(name, timeout, func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
+
+// runHealthChecks runs checkers concurrently and returns the error of each failed one by name.
+func runHealthChecks(ctx context.Context, checkers []HealthChecker) map[string]error {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed = make(map[string]error)
+	)
+	for _, checker := range checkers {
+		wg.Go(func() {
+			if err := checker.Check(ctx); err != nil {
+				mu.Lock()
+				failed[checker.Name()] = err
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return failed
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (pf pingerFunc) Ping(ctx context.Context) error { return pf(ctx) }
+
+func TestHealthCheckers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ping timeout", func(t *testing.T) {
+		checker := NewPingHealthChecker("pool", pingerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), 10*time.Millisecond)
+
+		assert.Equal(t, "pool", checker.Name())
+		assert.ErrorIs(t, checker.Check(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		db, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/db")
+		assert.NoError(t, err)
+		defer func() { _ = db.Close() }()
+
+		assert.Error(t, NewSQLHealthChecker("db", db, time.Second).Check(ctx))
+	})
+
+	t.Run("redis unavailable", func(t *testing.T) {
+		client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+		defer func() { _ = client.Close() }()
+
+		assert.Error(t, NewRedisHealthChecker("redis", client, time.Second).Check(ctx))
+	})
+
+	// Runs against the Redis server at REDIS_ADDR and is skipped if it is not set.
+	t.Run("redis", func(t *testing.T) {
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			t.Skip("REDIS_ADDR not set")
+		}
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		defer func() { _ = client.Close() }()
+
+		assert.NoError(t, NewRedisHealthChecker("redis", client, 0).Check(ctx))
+	})
+}
+
+func TestReadinessHandlerChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hs := New(&http.Server{}, time.Second, simple.NewLogger("test", true, 0), nil)
+	healthy := NewPingHealthChecker("db", pingerFunc(func(context.Context) error { return nil }), 0)
+	broken := NewPingHealthChecker("cache", pingerFunc(func(context.Context) error { return errors.New("refused") }), 0)
+
+	serve := func(checkers ...HealthChecker) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/ready", hs.ReadinessHandler(checkers...))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return w
+	}
+
+	w := serve(healthy)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"status":"ready","checks":{"db":"ok"}}}`, w.Body.String())
+
+	w = serve(healthy, broken)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"data":{"status":"unavailable","checks":{"db":"ok","cache":"failed"}}}`, w.Body.String())
+
+	w = serve()
+	assert.JSONEq(t, `{"data":{"status":"ready"}}`, w.Body.String())
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net"
+	"os"
+	"time"
+
+	"github.com/itsLeonB/ezutil/v2"
+)
+
+// HttpOption configures optional behaviour of Http.
+type HttpOption func(*Http)
+
+// WithShutdownTimeout bounds how long draining connections may take, after which the
+// remaining ones are closed forcibly. Defaults to 10 seconds.
+func WithShutdownTimeout(timeout time.Duration) HttpOption {
+	return func(hs *Http) {
+		if timeout > 0 {
+			hs.timeout = timeout
+		}
+	}
+}
+
+// WithLogger sets the logger of the server's lifecycle events.
+func WithLogger(logger ezutil.Logger) HttpOption {
+	return func(hs *Http) {
+		if logger != nil {
+			hs.logger = logger
+		}
+	}
+}
+
+// WithShutdownHook adds hook to the functions run after the server has stopped, in the
+// order they were added, e.g. to close database pools. All hooks run even if one fails.
+func WithShutdownHook(hook func() error) HttpOption {
+	return func(hs *Http) {
+		if hook != nil {
+			hs.shutdownHooks = append(hs.shutdownHooks, hook)
+		}
+	}
+}
+
+// WithSignals sets the signals that make ServeGracefully shut down, SIGINT and SIGTERM by default.
+func WithSignals(signals ...os.Signal) HttpOption {
+	return func(hs *Http) {
+		if len(signals) > 0 {
+			hs.signals = signals
+		}
+	}
+}
+
+// WithListener makes the server accept connections on ln instead of listening on the
+// http.Server's Addr, e.g. for socket activation or a listener on a random port in tests.
+func WithListener(ln net.Listener) HttpOption {
+	return func(hs *Http) {
+		if ln != nil {
+			hs.listener = ln
+		}
+	}
+}
+
+// WithPreStopDelay makes ServeGracefully wait for delay between failing readiness checks
+// and draining connections, so load balancers notice and stop routing new traffic first.
+// It should be a few times the readiness probe interval.
+func WithPreStopDelay(delay time.Duration) HttpOption {
+	return func(hs *Http) {
+		if delay > 0 {
+			hs.preStopDelay = delay
+		}
+	}
+}
+
+// WithReadHeaderTimeout bounds how long reading a request's headers may take, which
+// protects against clients that send them slowly. Defaults to 10 seconds unless the
+// http.Server sets it.
+func WithReadHeaderTimeout(timeout time.Duration) HttpOption {
+	return func(hs *Http) {
+		if timeout > 0 {
+			hs.srv.ReadHeaderTimeout = timeout
+		}
+	}
+}
+
+// WithIdleTimeout bounds how long a keep-alive connection may wait for its next request.
+// Defaults to 2 minutes unless the http.Server sets it or ReadTimeout.
+func WithIdleTimeout(timeout time.Duration) HttpOption {
+	return func(hs *Http) {
+		if timeout > 0 {
+			hs.srv.IdleTimeout = timeout
+		}
+	}
+}
+
+// WithMaxHeaderBytes limits the size of a request's headers, including the request line.
+// Defaults to http.DefaultMaxHeaderBytes (1 MB).
+func WithMaxHeaderBytes(n int) HttpOption {
+	return func(hs *Http) {
+		if n > 0 {
+			hs.srv.MaxHeaderBytes = n
+		}
+	}
+}
+
+// WithMaxConnections limits how many connections the server keeps open at once. Further
+// clients wait in the listen backlog until a connection closes.
+func WithMaxConnections(n int) HttpOption {
+	return func(hs *Http) {
+		if n > 0 {
+			hs.maxConns = n
+		}
+	}
+}
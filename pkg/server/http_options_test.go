@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServer(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{})
+
+		require.NoError(t, err)
+		assert.Equal(t, defaultShutdownTimeout, hs.timeout)
+		assert.NotNil(t, hs.logger)
+		assert.Equal(t, []os.Signal{os.Interrupt, syscall.SIGTERM}, hs.signals)
+		assert.Nil(t, hs.listener)
+		assert.Equal(t, defaultReadHeaderTimeout, hs.srv.ReadHeaderTimeout)
+		assert.Equal(t, defaultIdleTimeout, hs.srv.IdleTimeout)
+	})
+
+	t.Run("keeps the server's timeouts", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{ReadHeaderTimeout: time.Second, ReadTimeout: time.Minute})
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, hs.srv.ReadHeaderTimeout)
+		assert.Zero(t, hs.srv.IdleTimeout, "falls back to ReadTimeout")
+	})
+
+	t.Run("connection tuning", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{},
+			WithReadHeaderTimeout(time.Second),
+			WithIdleTimeout(time.Minute),
+			WithIdleTimeout(0),
+			WithMaxHeaderBytes(4096),
+			WithMaxConnections(100),
+			WithMaxConnections(-1),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, hs.srv.ReadHeaderTimeout)
+		assert.Equal(t, time.Minute, hs.srv.IdleTimeout)
+		assert.Equal(t, 4096, hs.srv.MaxHeaderBytes)
+		assert.Equal(t, 100, hs.maxConns)
+	})
+
+	t.Run("nil server", func(t *testing.T) {
+		_, err := NewServer(nil)
+		assert.EqualError(t, err, "http.Server cannot be nil")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		logger := simple.NewLogger("test", true, 0)
+		hs, err := NewServer(&http.Server{},
+			WithShutdownTimeout(time.Minute),
+			WithShutdownTimeout(0),
+			WithLogger(logger),
+			WithSignals(syscall.SIGHUP),
+			WithShutdownHook(nil),
+			WithPreStopDelay(time.Second),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, hs.timeout)
+		assert.Same(t, logger, hs.logger)
+		assert.Equal(t, []os.Signal{syscall.SIGHUP}, hs.signals)
+		assert.Empty(t, hs.shutdownHooks)
+		assert.Equal(t, time.Second, hs.preStopDelay)
+	})
+
+	t.Run("listener and shutdown hooks", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		var calls []string
+		hs, err := NewServer(&http.Server{Handler: http.NotFoundHandler()},
+			WithListener(ln),
+			WithShutdownHook(func() error {
+				calls = append(calls, "db")
+				return errors.New("db busy")
+			}),
+			WithShutdownHook(func() error {
+				calls = append(calls, "cache")
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- hs.Run(ctx) }()
+
+		assert.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err == nil {
+				_ = conn.Close()
+			}
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+
+		err = <-done
+		assert.ErrorContains(t, err, "db busy")
+		assert.Equal(t, []string{"db", "cache"}, calls)
+	})
+}
+
+func TestWithMaxConnections(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	hs, err := NewServer(&http.Server{Handler: http.NotFoundHandler()},
+		WithLogger(simple.NewLogger("test", true, 0)),
+		WithListener(ln),
+		WithMaxConnections(1),
+	)
+	require.NoError(t, err)
+	go func() { _ = hs.Run(context.Background()) }()
+	defer func() { _ = hs.Shutdown(context.Background()) }()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 100 * time.Millisecond}
+	url := "http://" + ln.Addr().String()
+	require.Eventually(t, func() bool {
+		resp, err := client.Get(url)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	held, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	_, err = held.Write([]byte("GET / HTTP/1.1\r\n"))
+	require.NoError(t, err)
+
+	_, err = client.Get(url)
+	assert.Error(t, err, "second connection waits for the first")
+
+	require.NoError(t, held.Close())
+	client.Timeout = 0
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -2,66 +2,341 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/itsLeonB/ezutil/v2"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/response"
+	"github.com/itsLeonB/ungerr"
+)
+
+const (
+	defaultShutdownTimeout   = 10 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
 )
 
 type Http struct {
-	srv          *http.Server
-	timeout      time.Duration
-	logger       ezutil.Logger
-	shutdownFunc func() error
+	srv              *http.Server
+	timeout          time.Duration
+	logger           ezutil.Logger
+	startupHooks     []func(context.Context) error
+	shutdownHooks    []func() error
+	preStopDelay     time.Duration
+	drainLogInterval time.Duration
+	inFlight         inFlightTracker
+	signals          []os.Signal
+	listener         net.Listener
+	maxConns         int
+	tls              bool
+	certFile         string
+	keyFile          string
+	redirectAddr     string
+	redirect         *http.Server
+	shuttingDown     atomic.Bool
+	running          atomic.Bool
+	stop             chan struct{}
+	stopOnce         sync.Once
+	done             chan struct{}
+	runErr           error
 }
 
-func New(srv *http.Server, timeout time.Duration, logger ezutil.Logger, shutdownFunc func() error) *Http {
+// NewServer creates an Http serving srv. Without options it shuts down within 10 seconds
+// on SIGINT or SIGTERM, and logs to stderr. Unless srv sets them, reading request headers
+// times out after 10 seconds and idle keep-alive connections are closed after 2 minutes.
+func NewServer(srv *http.Server, opts ...HttpOption) (*Http, error) {
+	if srv == nil {
+		return nil, errors.New("http.Server cannot be nil")
+	}
+
+	hs := &Http{
+		srv:              srv,
+		timeout:          defaultShutdownTimeout,
+		drainLogInterval: defaultDrainLogInterval,
+		logger:           simple.NewLogger(packageName, false, 0),
+		signals:          []os.Signal{os.Interrupt, syscall.SIGTERM},
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+	if srv.ReadHeaderTimeout == 0 {
+		srv.ReadHeaderTimeout = defaultReadHeaderTimeout
+	}
+	if srv.IdleTimeout == 0 && srv.ReadTimeout == 0 {
+		srv.IdleTimeout = defaultIdleTimeout
+	}
+	for _, opt := range opts {
+		opt(hs)
+	}
+	return hs, nil
+}
+
+// New is like NewHttp but exits the process if the arguments are invalid.
+//
+// Deprecated: Use NewServer.
+func New(srv *http.Server, timeout time.Duration, logger ezutil.Logger, shutdownFunc func() error, opts ...HttpOption) *Http {
 	if logger == nil {
 		log.Fatal("logger cannot be nil")
 	}
-	if srv == nil {
-		logger.Fatal("http.Server cannot be nil")
+	hs, err := NewHttp(srv, timeout, logger, shutdownFunc, opts...)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	return hs
+}
+
+// NewHttp creates an Http serving srv. timeout bounds how long shutting down may take,
+// and shutdownFunc, which may be nil, releases resources such as database pools after
+// the server has stopped.
+//
+// Deprecated: Use NewServer with WithShutdownTimeout, WithLogger and WithShutdownHook.
+func NewHttp(srv *http.Server, timeout time.Duration, logger ezutil.Logger, shutdownFunc func() error, opts ...HttpOption) (*Http, error) {
+	if logger == nil {
+		return nil, errors.New("logger cannot be nil")
 	}
 	if timeout <= 0 {
-		logger.Fatal("timeout must be > 0")
+		return nil, errors.New("timeout must be > 0")
 	}
 	if shutdownFunc == nil {
 		logger.Warn("shutdownFunc is nil, continuing...")
 	}
 
-	return &Http{srv, timeout, logger, shutdownFunc}
+	return NewServer(srv, append([]HttpOption{
+		WithShutdownTimeout(timeout),
+		WithLogger(logger),
+		WithShutdownHook(shutdownFunc),
+	}, opts...)...)
 }
 
-// ServeGracefully starts the HTTP server and handles graceful shutdown
+// ReadinessHandler responds 200 OK while the server accepts traffic and its dependencies
+// pass checkers, and 503 Service Unavailable once ServeGracefully starts shutting down or
+// when a check fails. Failed checks are logged and listed in the response by name, without
+// their errors. Mount it at the path of the load balancer's readiness probe.
+func (hs *Http) ReadinessHandler(checkers ...HealthChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if hs.shuttingDown.Load() {
+			response.Render(ctx, http.StatusServiceUnavailable, response.NewResponse(gin.H{"status": "shutting down"}))
+			return
+		}
+
+		checks := make(map[string]string, len(checkers))
+		for _, checker := range checkers {
+			checks[checker.Name()] = "ok"
+		}
+		failed := runHealthChecks(ctx.Request.Context(), checkers)
+		for name, err := range failed {
+			checks[name] = "failed"
+			hs.logger.WithContext(ctx).WithError(err).WithField("health_check", name).Warn("health check failed")
+		}
+
+		data := gin.H{"status": "ready"}
+		if len(checks) > 0 {
+			data["checks"] = checks
+		}
+		if len(failed) > 0 {
+			data["status"] = "unavailable"
+			response.Render(ctx, http.StatusServiceUnavailable, response.NewResponse(data))
+			return
+		}
+		response.Render(ctx, http.StatusOK, response.NewResponse(data))
+	}
+}
+
+// ServeGracefully starts the HTTP server and handles graceful shutdown.
+// On SIGINT or SIGTERM (see WithSignals) or when Shutdown is called, ReadinessHandler starts failing, and after the
+// pre-stop delay the server drains its connections and the shutdown hooks release the
+// remaining resources. It exits the process if the server fails; use Run to handle
+// errors instead. Like before Run existed, shutdown hook errors alone are only logged.
 func (hs *Http) ServeGracefully() {
+	ctx, stop := signal.NotifyContext(context.Background(), hs.signals...)
+	defer stop()
+
+	err := hs.Run(ctx)
+	if hookErr, ok := err.(shutdownHookError); ok {
+		hs.logger.Errorf("error in terminating resources: %s", hookErr.hookErrs)
+		return
+	}
+	if err != nil {
+		hs.logger.Fatal(err.Error())
+	}
+}
+
+// Run starts the HTTP server and shuts it down gracefully when ctx is done or Shutdown is
+// called, like ServeGracefully does on SIGINT or SIGTERM. It returns an error if a startup
+// hook fails, if the server cannot listen, if connections are not drained within the
+// timeout, or if a shutdown hook fails. The shutdown hooks also run when the server cannot
+// listen. A server can only be run once.
+func (hs *Http) Run(ctx context.Context) error {
+	if !hs.running.CompareAndSwap(false, true) {
+		return errors.New("server is already running or has been stopped")
+	}
+	defer close(hs.done)
+
+	hs.runErr = hs.run(ctx)
+	return hs.runErr
+}
+
+// Shutdown stops a server started with Run or ServeGracefully without sending the process a
+// signal, e.g. from tests or admin tooling, and waits until it has shut down. It returns the
+// server's shutdown error, or ctx's error if ctx is done first. Calling Shutdown before the
+// server has started makes it shut down as soon as it starts.
+func (hs *Http) Shutdown(ctx context.Context) error {
+	hs.stopOnce.Do(func() { close(hs.stop) })
+	if !hs.running.Load() {
+		return nil
+	}
+
+	select {
+	case <-hs.done:
+		return hs.runErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RegisterStartupHook adds hook to the functions Run calls before the server starts
+// listening, in the order they were added, e.g. to run migrations or warm caches. The
+// hooks get Run's context, so a signal received during startup cancels them. If a hook
+// fails, the remaining hooks are skipped, the shutdown hooks release what was set up and
+// Run returns the hook's error without serving any request.
+func (hs *Http) RegisterStartupHook(hook func(ctx context.Context) error) {
+	if hook != nil {
+		hs.startupHooks = append(hs.startupHooks, hook)
+	}
+}
+
+// Done returns a channel that is closed once the server has stopped running.
+func (hs *Http) Done() <-chan struct{} {
+	return hs.done
+}
+
+func (hs *Http) run(ctx context.Context) error {
+	if err := hs.runStartupHooks(ctx); err != nil {
+		if hookErr := hs.runShutdownHooks(); hookErr != nil {
+			err = errors.Join(err, hookErr)
+		}
+		return err
+	}
+
+	hs.inFlight.track(hs.srv)
+	listenErr := make(chan error, 2)
 	go func() {
-		hs.logger.Infof("starting server on: %s", hs.srv.Addr)
-		if err := hs.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			hs.logger.Fatalf("error server listen and serve: %s", err.Error())
+		if err := hs.serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			listenErr <- err
 		}
 	}()
 
-	exit := make(chan os.Signal, 1)
-	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
-	<-exit
+	hs.redirect = hs.newRedirectServer()
+	if hs.redirect != nil {
+		go func() {
+			hs.logger.Infof("redirecting HTTP to HTTPS on: %s", hs.redirect.Addr)
+			if err := hs.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				listenErr <- err
+			}
+		}()
+	}
+
+	select {
+	case err := <-listenErr:
+		_ = hs.srv.Close()
+		if hs.redirect != nil {
+			_ = hs.redirect.Close()
+		}
+		err = ungerr.Wrap(err, "error server listen and serve")
+		if hookErr := hs.runShutdownHooks(); hookErr != nil {
+			err = errors.Join(err, hookErr)
+		}
+		return err
+	case <-ctx.Done():
+	case <-hs.stop:
+	}
+	return hs.shutdown()
+}
+
+func (hs *Http) shutdown() error {
+	hs.shuttingDown.Store(true)
+	if hs.preStopDelay > 0 {
+		hs.logger.Infof("failing readiness checks for %s before shutting down...", hs.preStopDelay)
+		time.Sleep(hs.preStopDelay)
+	}
 	hs.logger.Info("shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), hs.timeout)
 	defer cancel()
 
-	if err := hs.srv.Shutdown(ctx); err != nil {
-		hs.logger.Fatalf("error shutting down: %s", err.Error())
+	// The main server is always drained and the hooks always run, whatever fails before.
+	var redirectDone chan error
+	if hs.redirect != nil {
+		redirectDone = make(chan error, 1)
+		go func() { redirectDone <- hs.redirect.Shutdown(ctx) }()
 	}
 
-	if hs.shutdownFunc != nil {
-		if err := hs.shutdownFunc(); err != nil {
-			hs.logger.Errorf("error in terminating resources: %s", err.Error())
+	var errs []error
+	if err := hs.drain(ctx); err != nil {
+		errs = append(errs, ungerr.Wrap(err, "error shutting down"))
+	}
+	if redirectDone != nil {
+		if err := <-redirectDone; err != nil {
+			_ = hs.redirect.Close()
+			errs = append(errs, ungerr.Wrap(err, "error shutting down HTTPS redirect"))
 		}
 	}
+	if err := hs.runShutdownHooks(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	if len(errs) > 1 {
+		return errors.Join(errs...)
+	}
 
 	hs.logger.Info("server successfully shutdown")
+	return nil
+}
+
+func (hs *Http) runStartupHooks(ctx context.Context) error {
+	for _, hook := range hs.startupHooks {
+		if err := ctx.Err(); err != nil {
+			return ungerr.Wrap(err, "startup canceled")
+		}
+		if err := hook(ctx); err != nil {
+			return ungerr.Wrap(err, "error in startup hook")
+		}
+	}
+	return nil
+}
+
+func (hs *Http) runShutdownHooks() error {
+	var hookErrs []error
+	for _, hook := range hs.shutdownHooks {
+		if err := hook(); err != nil {
+			hookErrs = append(hookErrs, err)
+		}
+	}
+	if len(hookErrs) > 0 {
+		joined := errors.Join(hookErrs...)
+		return shutdownHookError{ungerr.Wrap(joined, "error in terminating resources"), joined}
+	}
+	return nil
+}
+
+// shutdownHookError is the error of failed shutdown hooks, which ServeGracefully logs
+// instead of exiting on.
+type shutdownHookError struct {
+	error
+	hookErrs error // the errors of the hooks, without a stack trace
+}
+
+func (e shutdownHookError) Unwrap() error {
+	return e.error
 }
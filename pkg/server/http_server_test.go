@@ -1,12 +1,18 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/itsLeonB/ezutil/v2/simple"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -22,7 +28,7 @@ func TestNew(t *testing.T) {
 		assert.NotNil(t, s)
 		assert.Equal(t, srv, s.srv)
 		assert.Equal(t, timeout, s.timeout)
-		assert.NotNil(t, s.shutdownFunc)
+		assert.Len(t, s.shutdownHooks, 1)
 	})
 
 	t.Run("nil shutdown func", func(t *testing.T) {
@@ -32,11 +38,253 @@ func TestNew(t *testing.T) {
 		s := New(srv, timeout, logger, nil)
 
 		assert.NotNil(t, s)
-		assert.Nil(t, s.shutdownFunc)
+		assert.Empty(t, s.shutdownHooks)
 	})
 
-	// Note: We cannot easily test fatal exits in unit tests without causing the test runner to exit.
-	// Typically, we would refactor the constructor to return error or use a mock logger that captures Fatal calls.
-	// Since the current implementation calls log.Fatal or logger.Fatal directly, we skip those negative test cases here
-	// or would need to run them in a subprocess.
+	t.Run("invalid arguments return errors", func(t *testing.T) {
+		_, err := NewHttp(nil, time.Second, logger, nil)
+		assert.EqualError(t, err, "http.Server cannot be nil")
+
+		_, err = NewHttp(&http.Server{}, 0, logger, nil)
+		assert.EqualError(t, err, "timeout must be > 0")
+
+		_, err = NewHttp(&http.Server{}, time.Second, nil, nil)
+		assert.EqualError(t, err, "logger cannot be nil")
+	})
+}
+
+func TestReadinessDuringShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := simple.NewLogger("test", true, 0)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{}
+	var released atomic.Bool
+	hs := New(srv, time.Second, logger, func() error {
+		released.Store(true)
+		return nil
+	}, WithPreStopDelay(200*time.Millisecond))
+
+	r := gin.New()
+	r.GET("/ready", hs.ReadinessHandler())
+	srv.Handler = r
+	go func() { _ = srv.Serve(ln) }()
+
+	// Without keep-alives the client leaves no connections behind for Shutdown to wait for.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	ready := func() int {
+		resp, err := client.Get("http://" + ln.Addr().String() + "/ready")
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, ready())
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, hs.shutdown())
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return ready() == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	assert.False(t, released.Load())
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown did not finish")
+	}
+	assert.True(t, released.Load())
+	assert.Equal(t, 0, ready())
+}
+
+func TestRun(t *testing.T) {
+	logger := simple.NewLogger("test", true, 0)
+
+	t.Run("shuts down when the context is done", func(t *testing.T) {
+		var released atomic.Bool
+		hs, err := NewHttp(&http.Server{Addr: "127.0.0.1:0"}, time.Second, logger, func() error {
+			released.Store(true)
+			return nil
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		assert.NoError(t, hs.Run(ctx))
+		assert.True(t, released.Load())
+	})
+
+	t.Run("listen error", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = ln.Close() }()
+
+		var released atomic.Bool
+		hs, err := NewHttp(&http.Server{Addr: ln.Addr().String()}, time.Second, logger, func() error {
+			released.Store(true)
+			return errors.New("pool still busy")
+		})
+		require.NoError(t, err)
+
+		err = hs.Run(context.Background())
+		assert.ErrorContains(t, err, "error server listen and serve")
+		assert.ErrorContains(t, err, "address already in use")
+		assert.ErrorContains(t, err, "pool still busy")
+		assert.True(t, released.Load(), "shutdown hooks release resources set up before listening")
+	})
+
+	t.Run("shutdown func error", func(t *testing.T) {
+		hs, err := NewHttp(&http.Server{Addr: "127.0.0.1:0"}, time.Second, logger, func() error {
+			return errors.New("pool still busy")
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err = hs.Run(ctx)
+		assert.ErrorContains(t, err, "error in terminating resources")
+		assert.ErrorContains(t, err, "pool still busy")
+	})
+}
+
+func TestServeGracefullyLogsShutdownHookErrors(t *testing.T) {
+	logger := &formatLogger{Logger: simple.NewLogger("test", true, 0)}
+	hs, err := NewHttp(&http.Server{Addr: "127.0.0.1:0"}, time.Second, logger, func() error {
+		return errors.New("pool still busy")
+	})
+	require.NoError(t, err)
+
+	go func() {
+		assert.Eventually(t, hs.running.Load, time.Second, time.Millisecond)
+		_ = hs.Shutdown(context.Background())
+	}()
+	hs.ServeGracefully()
+
+	assert.Contains(t, logger.logged(), "error in terminating resources: pool still busy")
+}
+
+func TestShutdown(t *testing.T) {
+	logger := simple.NewLogger("test", true, 0)
+
+	t.Run("stops a running server", func(t *testing.T) {
+		var released atomic.Bool
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger), WithShutdownHook(func() error {
+			released.Store(true)
+			return nil
+		}))
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() { done <- hs.Run(context.Background()) }()
+		require.Eventually(t, hs.running.Load, time.Second, time.Millisecond)
+
+		assert.NoError(t, hs.Shutdown(context.Background()))
+		assert.True(t, released.Load())
+		assert.NoError(t, <-done)
+		_, open := <-hs.Done()
+		assert.False(t, open)
+
+		assert.NoError(t, hs.Shutdown(context.Background()), "shutting down twice")
+		assert.EqualError(t, hs.Run(context.Background()), "server is already running or has been stopped")
+	})
+
+	t.Run("before the server starts", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger))
+		require.NoError(t, err)
+
+		assert.NoError(t, hs.Shutdown(context.Background()))
+		assert.NoError(t, hs.Run(context.Background()))
+	})
+
+	t.Run("returns the shutdown error", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger), WithShutdownHook(func() error {
+			return errors.New("pool still busy")
+		}))
+		require.NoError(t, err)
+
+		go func() { _ = hs.Run(context.Background()) }()
+		require.Eventually(t, hs.running.Load, time.Second, time.Millisecond)
+
+		assert.ErrorContains(t, hs.Shutdown(context.Background()), "pool still busy")
+	})
+
+	t.Run("context done before the server stops", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger), WithPreStopDelay(time.Second))
+		require.NoError(t, err)
+
+		go func() { _ = hs.Run(context.Background()) }()
+		require.Eventually(t, hs.running.Load, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, hs.Shutdown(ctx), context.DeadlineExceeded)
+		<-hs.Done()
+	})
+}
+
+func TestStartupHooks(t *testing.T) {
+	logger := simple.NewLogger("test", true, 0)
+
+	t.Run("run in order before serving", func(t *testing.T) {
+		var calls []string
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger))
+		require.NoError(t, err)
+		hs.RegisterStartupHook(func(context.Context) error {
+			calls = append(calls, "migrate")
+			return nil
+		})
+		hs.RegisterStartupHook(nil)
+		hs.RegisterStartupHook(func(context.Context) error {
+			calls = append(calls, "warm up")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		assert.NoError(t, hs.Run(ctx))
+		assert.Equal(t, []string{"migrate", "warm up"}, calls)
+	})
+
+	t.Run("failure aborts startup", func(t *testing.T) {
+		var released, skipped atomic.Bool
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger), WithShutdownHook(func() error {
+			released.Store(true)
+			return nil
+		}))
+		require.NoError(t, err)
+		hs.RegisterStartupHook(func(context.Context) error {
+			return errors.New("migration failed")
+		})
+		hs.RegisterStartupHook(func(context.Context) error {
+			skipped.Store(true)
+			return nil
+		})
+
+		err = hs.Run(context.Background())
+		assert.ErrorContains(t, err, "error in startup hook")
+		assert.ErrorContains(t, err, "migration failed")
+		assert.False(t, skipped.Load())
+		assert.True(t, released.Load())
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		hs, err := NewServer(&http.Server{Addr: "127.0.0.1:0"}, WithLogger(logger))
+		require.NoError(t, err)
+		hs.RegisterStartupHook(func(context.Context) error {
+			t.Error("hook ran after the context was canceled")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorContains(t, hs.Run(ctx), "context canceled")
+	})
 }
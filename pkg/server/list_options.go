@@ -0,0 +1,199 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ungerr"
+)
+
+// SortDirection is the direction of a SortField.
+type SortDirection string
+
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+// FilterOperator compares a field against the values of a Filter.
+type FilterOperator string
+
+const (
+	FilterEqual          FilterOperator = "eq"
+	FilterNotEqual       FilterOperator = "ne"
+	FilterGreaterThan    FilterOperator = "gt"
+	FilterGreaterOrEqual FilterOperator = "gte"
+	FilterLessThan       FilterOperator = "lt"
+	FilterLessOrEqual    FilterOperator = "lte"
+	FilterIn             FilterOperator = "in"
+	FilterLike           FilterOperator = "like"
+)
+
+// ListField whitelists a field for sorting and filtering in a ListSpec.
+type ListField struct {
+	// Column is the name repositories should use for the field, e.g. a database column.
+	// It defaults to the field's name.
+	Column string
+	// Sortable allows the field in the sort parameter.
+	Sortable bool
+	// Operators are the filter operators allowed on the field. The field cannot be
+	// filtered on if it is empty.
+	Operators []FilterOperator
+}
+
+// ListSpec declares which fields of a list endpoint can be sorted and filtered on.
+type ListSpec struct {
+	// Fields maps the field names used in the query string to their whitelist entry.
+	Fields map[string]ListField
+	// DefaultSort is used when the request has no sort parameter.
+	DefaultSort []SortField
+}
+
+// SortField is one field of the sort parameter.
+type SortField struct {
+	Field     string
+	Column    string
+	Direction SortDirection
+}
+
+// Filter is one filter[...] parameter. Values holds a single value, except for FilterIn
+// whose comma-separated values are split.
+type Filter struct {
+	Field    string
+	Column   string
+	Operator FilterOperator
+	Values   []string
+}
+
+// ListOptions are the sort and filter parameters of a list request, validated against a
+// ListSpec. Pagination is bound separately into a response.QueryOptions.
+type ListOptions struct {
+	Sort    []SortField
+	Filters []Filter
+}
+
+// OrderBy returns the sort as an SQL ORDER BY list such as "created_at DESC, id ASC", or ""
+// if there is none. It only contains whitelisted columns, so it is safe to concatenate.
+func (lo ListOptions) OrderBy() string {
+	clauses := make([]string, len(lo.Sort))
+	for i, field := range lo.Sort {
+		clauses[i] = field.Column + " " + strings.ToUpper(string(field.Direction))
+	}
+	return strings.Join(clauses, ", ")
+}
+
+var filterParamPattern = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// ParseListOptions parses the sort and filter query parameters of the request:
+//
+//	?sort=created_at:desc,name&filter[status]=active&filter[price][gte]=10&filter[tag][in]=a,b
+//
+// The sort direction defaults to ascending and the filter operator to FilterEqual.
+// Fields, operators and directions that spec does not allow are rejected with a
+// VALIDATION_FAILED error whose details are keyed by query parameter.
+func ParseListOptions(ctx *gin.Context, spec ListSpec) (ListOptions, error) {
+	query := ctx.Request.URL.Query()
+	details := map[string]string{}
+
+	var opts ListOptions
+	if sortParam := query.Get("sort"); sortParam != "" {
+		opts.Sort = parseSort(sortParam, spec, details)
+	} else {
+		opts.Sort = slices.Clone(spec.DefaultSort)
+		for i, sortField := range opts.Sort {
+			if sortField.Column == "" {
+				opts.Sort[i].Column = columnOf(sortField.Field, spec.Fields[sortField.Field])
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		filter, detail := parseFilter(key, query[key], spec)
+		if detail != "" {
+			details[key] = detail
+			continue
+		}
+		opts.Filters = append(opts.Filters, filter)
+	}
+
+	if len(details) > 0 {
+		return ListOptions{}, middleware.WithErrorCode(ungerr.ValidationError(details), middleware.ErrorCodeValidationFailed)
+	}
+	return opts, nil
+}
+
+func parseSort(param string, spec ListSpec, details map[string]string) []SortField {
+	var fields []SortField
+	for part := range strings.SplitSeq(param, ",") {
+		name, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		field, ok := spec.Fields[name]
+		if !ok || !field.Sortable {
+			details["sort"] = fmt.Sprintf("cannot sort by %q", name)
+			return nil
+		}
+		if slices.ContainsFunc(fields, func(sf SortField) bool { return sf.Field == name }) {
+			details["sort"] = fmt.Sprintf("%q is sorted by more than once", name)
+			return nil
+		}
+
+		sortField := SortField{Field: name, Column: columnOf(name, field), Direction: SortAscending}
+		switch SortDirection(strings.ToLower(direction)) {
+		case "", SortAscending:
+		case SortDescending:
+			sortField.Direction = SortDescending
+		default:
+			details["sort"] = fmt.Sprintf("invalid direction %q for %q, use asc or desc", direction, name)
+			return nil
+		}
+		fields = append(fields, sortField)
+	}
+	return fields
+}
+
+func parseFilter(key string, values []string, spec ListSpec) (Filter, string) {
+	match := filterParamPattern.FindStringSubmatch(key)
+	if match == nil {
+		return Filter{}, "malformed filter, use filter[field] or filter[field][operator]"
+	}
+
+	name, operator := match[1], FilterOperator(match[2])
+	if operator == "" {
+		operator = FilterEqual
+	}
+	field, ok := spec.Fields[name]
+	if !ok || len(field.Operators) == 0 {
+		return Filter{}, fmt.Sprintf("cannot filter by %q", name)
+	}
+	if !slices.Contains(field.Operators, operator) {
+		return Filter{}, fmt.Sprintf("operator %q is not allowed for %q", operator, name)
+	}
+	if len(values) > 1 {
+		return Filter{}, "must be given once"
+	}
+
+	filterValues := values
+	if operator == FilterIn {
+		filterValues = strings.Split(values[0], ",")
+	}
+	return Filter{Field: name, Column: columnOf(name, field), Operator: operator, Values: filterValues}, ""
+}
+
+func columnOf(name string, field ListField) string {
+	if field.Column != "" {
+		return field.Column
+	}
+	return name
+}
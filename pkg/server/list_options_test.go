@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	spec := server.ListSpec{
+		Fields: map[string]server.ListField{
+			"createdAt": {Column: "created_at", Sortable: true, Operators: []server.FilterOperator{server.FilterGreaterOrEqual, server.FilterLessThan}},
+			"name":      {Sortable: true},
+			"status":    {Operators: []server.FilterOperator{server.FilterEqual, server.FilterIn}},
+		},
+		DefaultSort: []server.SortField{{Field: "createdAt", Direction: server.SortDescending}},
+	}
+
+	parse := func(rawQuery string) (server.ListOptions, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/orders?"+rawQuery, nil)
+		return server.ParseListOptions(c, spec)
+	}
+
+	t.Run("sort and filters", func(t *testing.T) {
+		opts, err := parse("sort=name,createdAt:DESC&filter[status][in]=paid,shipped&filter[createdAt][gte]=2024-01-01&page=2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []server.SortField{
+			{Field: "name", Column: "name", Direction: server.SortAscending},
+			{Field: "createdAt", Column: "created_at", Direction: server.SortDescending},
+		}, opts.Sort)
+		assert.Equal(t, []server.Filter{
+			{Field: "createdAt", Column: "created_at", Operator: server.FilterGreaterOrEqual, Values: []string{"2024-01-01"}},
+			{Field: "status", Column: "status", Operator: server.FilterIn, Values: []string{"paid", "shipped"}},
+		}, opts.Filters)
+		assert.Equal(t, "name ASC, created_at DESC", opts.OrderBy())
+	})
+
+	t.Run("default sort and operator", func(t *testing.T) {
+		opts, err := parse("filter[status]=paid&filterMode=any&filters=x")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "created_at DESC", opts.OrderBy())
+		assert.Equal(t, []server.Filter{
+			{Field: "status", Column: "status", Operator: server.FilterEqual, Values: []string{"paid"}},
+		}, opts.Filters)
+	})
+
+	t.Run("rejects what the spec does not allow", func(t *testing.T) {
+		_, err := parse("sort=password&filter[name]=x&filter[status][like]=p%25&filter[createdAt][lt]=a&filter[createdAt][lt]=b&filter[status=x")
+
+		appError, ok := err.(ungerr.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, appError.HttpStatus())
+		code, _ := middleware.ErrorCodeOf(err)
+		assert.Equal(t, middleware.ErrorCodeValidationFailed, code)
+		assert.Equal(t, map[string]string{
+			"sort":                  `cannot sort by "password"`,
+			"filter[name]":          `cannot filter by "name"`,
+			"filter[status][like]":  `operator "like" is not allowed for "status"`,
+			"filter[createdAt][lt]": "must be given once",
+			"filter[status":         "malformed filter, use filter[field] or filter[field][operator]",
+		}, appError.Details())
+	})
+
+	t.Run("invalid direction", func(t *testing.T) {
+		_, err := parse("sort=name:up")
+
+		appError, _ := err.(ungerr.AppError)
+		assert.Equal(t, map[string]string{"sort": `invalid direction "up" for "name", use asc or desc`}, appError.Details())
+	})
+}
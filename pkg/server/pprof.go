@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pprofProfiles are the runtime profiles served by name.
+var pprofProfiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// MountPprof serves the net/http/pprof endpoints at relativePath of router, e.g.
+//
+//	server.MountPprof(r, "/debug/pprof", mp.NewIPAllowlistMiddleware("10.0.0.0/8"))
+//
+// so production services can be profiled without a second HTTP server. guards, such as an
+// auth or IP allowlist middleware, run before every endpoint; at least one is required,
+// since profiles expose internals and the CPU profile and trace endpoints are expensive.
+// The index page is served at relativePath + "/". Mind the server's WriteTimeout when
+// collecting CPU profiles or traces longer than it. It panics if no guards are given.
+func MountPprof(router gin.IRouter, relativePath string, guards ...gin.HandlerFunc) {
+	if len(guards) == 0 {
+		panic("server: MountPprof requires at least one guard, pprof endpoints must not be public")
+	}
+	group := router.Group(strings.TrimSuffix(relativePath, "/"), guards...)
+
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range pprofProfiles {
+		group.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
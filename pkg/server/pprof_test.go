@@ -0,0 +1,49 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMountPprof(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	server.MountPprof(r.Group("/admin"), "/debug/pprof/", func(ctx *gin.Context) {
+		if ctx.GetHeader("X-Admin") != "yes" {
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+	})
+
+	serve := func(path string, admin bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if admin {
+			req.Header.Set("X-Admin", "yes")
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/admin/debug/pprof/", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine")
+
+	w = serve("/admin/debug/pprof/goroutine?debug=1", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine profile:")
+
+	assert.Equal(t, http.StatusOK, serve("/admin/debug/pprof/cmdline", true).Code)
+	assert.Equal(t, http.StatusForbidden, serve("/admin/debug/pprof/heap", false).Code)
+	assert.Equal(t, http.StatusForbidden, serve("/admin/debug/pprof/", false).Code)
+}
+
+func TestMountPprofWithoutGuards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	assert.Panics(t, func() { server.MountPprof(gin.New(), "/debug/pprof") })
+}
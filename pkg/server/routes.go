@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/response"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware,omitempty"`
+}
+
+// RouteRegistry lists the routes of an engine together with the names of their
+// middlewares, which Gin itself does not keep. Create one per engine with NewRouteRegistry
+// and register routes through it, e.g. with RegisterControllers.
+type RouteRegistry struct {
+	engine     *gin.Engine
+	mu         sync.RWMutex
+	middleware map[string][]string
+}
+
+// NewRouteRegistry creates a RouteRegistry of the routes of engine.
+func NewRouteRegistry(engine *gin.Engine) *RouteRegistry {
+	return &RouteRegistry{engine: engine, middleware: make(map[string][]string)}
+}
+
+// SetMiddleware records the names of the middlewares attached to a route, so Routes can
+// report them. Route registration helpers call it.
+func (rr *RouteRegistry) SetMiddleware(method, path string, middleware ...string) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.middleware[method+" "+path] = middleware
+}
+
+// Routes lists all routes registered on the engine, sorted by path and method.
+func (rr *RouteRegistry) Routes() []RouteInfo {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	ginRoutes := rr.engine.Routes()
+	routes := make([]RouteInfo, len(ginRoutes))
+	for i, route := range ginRoutes {
+		routes[i] = RouteInfo{
+			Method:     route.Method,
+			Path:       route.Path,
+			Handler:    route.Handler,
+			Middleware: rr.middleware[route.Method+" "+route.Path],
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+// Handler returns a handler that responds with the routes of the engine as JSON, useful
+// for debugging, documentation and smoke tests. Guard it like other internal endpoints.
+func (rr *RouteRegistry) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, response.NewResponse(rr.Routes()))
+	}
+}
@@ -0,0 +1,45 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/users", func(c *gin.Context) {})
+	r.GET("/users", func(c *gin.Context) {})
+	registry := server.NewRouteRegistry(r)
+	r.GET("/routes", registry.Handler())
+	registry.SetMiddleware("POST", "/users", "auth", "permission")
+
+	routes := registry.Routes()
+	assert.Len(t, routes, 3)
+	assert.Equal(t, "GET", routes[1].Method)
+	assert.Equal(t, "/users", routes[1].Path)
+	assert.Nil(t, routes[1].Middleware)
+	assert.Equal(t, "POST", routes[2].Method)
+	assert.Equal(t, []string{"auth", "permission"}, routes[2].Middleware)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/routes", nil))
+
+	var body struct {
+		Data []server.RouteInfo `json:"data"`
+	}
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, routes, body.Data)
+
+	other := gin.New()
+	other.POST("/users", func(c *gin.Context) {})
+	assert.Nil(t, server.NewRouteRegistry(other).Routes()[0].Middleware, "registries do not share metadata")
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+)
+
+// ServerGroup runs several servers, e.g. a public API and an admin or metrics server on
+// another port, in one lifecycle: they start together, and when one of them fails or the
+// group is stopped, all of them shut down gracefully.
+type ServerGroup struct {
+	servers []*Http
+}
+
+// NewServerGroup creates a ServerGroup of servers, each keeping its own options such as
+// shutdown timeout, pre-stop delay and shutdown hooks.
+func NewServerGroup(servers ...*Http) (*ServerGroup, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("server group needs at least one server")
+	}
+	if slices.Contains(servers, nil) {
+		return nil, errors.New("server cannot be nil")
+	}
+	return &ServerGroup{servers: servers}, nil
+}
+
+// ServeGracefully runs the servers until the process receives any of their signals (see
+// WithSignals), then shuts all of them down. It exits the process if a server fails; use
+// Run to handle errors instead.
+func (sg *ServerGroup) ServeGracefully() {
+	var signals []os.Signal
+	for _, hs := range sg.servers {
+		for _, sig := range hs.signals {
+			if !slices.Contains(signals, sig) {
+				signals = append(signals, sig)
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+
+	if err := sg.Run(ctx); err != nil {
+		sg.servers[0].logger.Fatal(err.Error())
+	}
+}
+
+// Run starts the servers and shuts all of them down concurrently when ctx is done, when
+// Shutdown is called or as soon as one of them fails. It waits for every server to stop
+// and returns their errors joined together.
+func (sg *ServerGroup) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(sg.servers))
+	var wg sync.WaitGroup
+	for i, hs := range sg.servers {
+		wg.Go(func() {
+			if errs[i] = hs.Run(ctx); errs[i] != nil {
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Shutdown stops every server of the group, like Http.Shutdown, and waits until they have
+// shut down. It returns their shutdown errors joined together, or ctx's error if ctx is
+// done first.
+func (sg *ServerGroup) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(sg.servers))
+	var wg sync.WaitGroup
+	for i, hs := range sg.servers {
+		wg.Go(func() {
+			errs[i] = hs.Shutdown(ctx)
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
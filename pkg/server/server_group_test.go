@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroupMember(t *testing.T, ln net.Listener, hook func() error) *Http {
+	t.Helper()
+	hs, err := NewServer(
+		&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})},
+		WithLogger(simple.NewLogger("test", true, 0)),
+		WithListener(ln),
+		WithShutdownHook(hook),
+	)
+	require.NoError(t, err)
+	return hs
+}
+
+func TestNewServerGroup(t *testing.T) {
+	_, err := NewServerGroup()
+	assert.EqualError(t, err, "server group needs at least one server")
+
+	_, err = NewServerGroup(nil)
+	assert.EqualError(t, err, "server cannot be nil")
+}
+
+func TestServerGroupRun(t *testing.T) {
+	t.Run("serves until context is done", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		var listeners []net.Listener
+		var servers []*Http
+		stopped := make(chan string, 2)
+		for _, name := range []string{"api", "admin"} {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			listeners = append(listeners, ln)
+			servers = append(servers, newGroupMember(t, ln, func() error {
+				stopped <- name
+				return nil
+			}))
+		}
+		sg, err := NewServerGroup(servers...)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- sg.Run(ctx) }()
+
+		for _, ln := range listeners {
+			resp, err := client.Get("http://" + ln.Addr().String())
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+
+		cancel()
+		assert.NoError(t, <-done)
+		assert.ElementsMatch(t, []string{"api", "admin"}, []string{<-stopped, <-stopped})
+	})
+
+	t.Run("failure of one server shuts down the others", func(t *testing.T) {
+		taken, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = taken.Close() }()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		stopped := make(chan struct{}, 1)
+		healthy := newGroupMember(t, ln, func() error {
+			stopped <- struct{}{}
+			return nil
+		})
+		failing, err := NewServer(&http.Server{Addr: taken.Addr().String()}, WithLogger(simple.NewLogger("test", true, 0)))
+		require.NoError(t, err)
+
+		sg, err := NewServerGroup(healthy, failing)
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() { done <- sg.Run(context.Background()) }()
+
+		select {
+		case err := <-done:
+			assert.ErrorContains(t, err, "address already in use")
+		case <-time.After(5 * time.Second):
+			t.Fatal("server group did not stop")
+		}
+		assert.Len(t, stopped, 1)
+	})
+	t.Run("shutdown", func(t *testing.T) {
+		var servers []*Http
+		stopped := make(chan struct{}, 2)
+		for range 2 {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			servers = append(servers, newGroupMember(t, ln, func() error {
+				stopped <- struct{}{}
+				return nil
+			}))
+		}
+		sg, err := NewServerGroup(servers...)
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() { done <- sg.Run(context.Background()) }()
+		require.Eventually(t, func() bool {
+			return servers[0].running.Load() && servers[1].running.Load()
+		}, time.Second, time.Millisecond)
+
+		assert.NoError(t, sg.Shutdown(context.Background()))
+		assert.NoError(t, <-done)
+		assert.Len(t, stopped, 2)
+	})
+}
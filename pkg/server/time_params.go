@@ -0,0 +1,80 @@
+package server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ungerr"
+)
+
+type timeParseConfig struct {
+	layouts  []string
+	location *time.Location
+}
+
+// TimeOption configures how GetTimePathParam and GetTimeQuery parse times.
+type TimeOption func(*timeParseConfig)
+
+// WithTimeLayouts sets the layouts a time may be written in, tried in order. Defaults to
+// time.RFC3339 and time.DateOnly.
+func WithTimeLayouts(layouts ...string) TimeOption {
+	return func(cfg *timeParseConfig) {
+		if len(layouts) > 0 {
+			cfg.layouts = layouts
+		}
+	}
+}
+
+// WithTimeLocation sets the time zone of times written without one, e.g. dates. Defaults
+// to UTC. Times with an offset keep it.
+func WithTimeLocation(loc *time.Location) TimeOption {
+	return func(cfg *timeParseConfig) {
+		if loc != nil {
+			cfg.location = loc
+		}
+	}
+}
+
+// GetTimePathParam extracts and parses a path parameter holding a time, like GetPathParam.
+// It returns false if the parameter does not exist, and a VALIDATION_FAILED error keyed by
+// the parameter if it matches none of the layouts.
+func GetTimePathParam(ctx *gin.Context, key string, opts ...TimeOption) (time.Time, bool, error) {
+	value, exists := ctx.Params.Get(key)
+	if !exists {
+		return time.Time{}, false, nil
+	}
+	t, err := parseTimeParam(key, value, opts)
+	return t, true, err
+}
+
+// GetTimeQuery is like GetTimePathParam for the query parameter key, e.g. ?from=2024-01-31.
+// An empty value is treated like a missing one.
+func GetTimeQuery(ctx *gin.Context, key string, opts ...TimeOption) (time.Time, bool, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := parseTimeParam(key, value, opts)
+	return t, true, err
+}
+
+func parseTimeParam(key, value string, opts []TimeOption) (time.Time, error) {
+	cfg := timeParseConfig{
+		layouts:  []string{time.RFC3339, time.DateOnly},
+		location: time.UTC,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	for _, layout := range cfg.layouts {
+		if t, err := time.ParseInLocation(layout, value, cfg.location); err == nil {
+			return t, nil
+		}
+	}
+
+	details := map[string]string{key: "must be a time in the format " + strings.Join(cfg.layouts, " or ")}
+	return time.Time{}, middleware.WithErrorCode(ungerr.ValidationError(details), middleware.ErrorCodeValidationFailed)
+}
@@ -0,0 +1,105 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/itsLeonB/ungerr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTimeQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	newContext := func(target string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return c
+	}
+
+	t.Run("RFC 3339", func(t *testing.T) {
+		val, exists, err := server.GetTimeQuery(newContext("/?from=2024-01-31T10:00:00%2B02:00"), "from", server.WithTimeLocation(jakarta))
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.True(t, val.Equal(time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC)))
+		_, offset := val.Zone()
+		assert.Equal(t, 2*60*60, offset, "keeps the written offset")
+	})
+
+	t.Run("date only", func(t *testing.T) {
+		val, _, err := server.GetTimeQuery(newContext("/?from=2024-01-31"), "from")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), val)
+
+		val, _, err = server.GetTimeQuery(newContext("/?from=2024-01-31"), "from", server.WithTimeLocation(jakarta))
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, jakarta), val)
+	})
+
+	t.Run("custom layouts", func(t *testing.T) {
+		val, _, err := server.GetTimeQuery(newContext("/?month=2024-02"), "month", server.WithTimeLayouts("2006-01"), server.WithTimeLayouts())
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), val)
+	})
+
+	t.Run("missing or empty", func(t *testing.T) {
+		for _, target := range []string{"/", "/?from="} {
+			val, exists, err := server.GetTimeQuery(newContext(target), "from")
+			assert.NoError(t, err)
+			assert.False(t, exists)
+			assert.True(t, val.IsZero())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, exists, err := server.GetTimeQuery(newContext("/?from=31/01/2024"), "from")
+		assert.True(t, exists)
+
+		var appErr ungerr.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusUnprocessableEntity, appErr.HttpStatus())
+		assert.Equal(t, map[string]string{"from": "must be a time in the format 2006-01-02T15:04:05Z07:00 or 2006-01-02"}, appErr.Details())
+		code, _ := middleware.ErrorCodeOf(err)
+		assert.Equal(t, middleware.ErrorCodeValidationFailed, code)
+	})
+}
+
+func TestGetTimePathParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid param", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Params = gin.Params{{Key: "date", Value: "2024-01-31"}}
+
+		val, exists, err := server.GetTimePathParam(c, "date", server.WithTimeLayouts(time.DateOnly))
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), val)
+	})
+
+	t.Run("missing param", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+		_, exists, err := server.GetTimePathParam(c, "date")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("invalid param", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Params = gin.Params{{Key: "date", Value: "2024-01-31T10:00"}}
+
+		_, _, err := server.GetTimePathParam(c, "date", server.WithTimeLayouts(time.DateOnly))
+
+		var appErr ungerr.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, map[string]string{"date": "must be a time in the format 2006-01-02"}, appErr.Details())
+	})
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/netutil"
+)
+
+// WithTLS makes the server serve HTTPS with the certificate and key in the given PEM files.
+func WithTLS(certFile, keyFile string) HttpOption {
+	return func(hs *Http) {
+		hs.certFile, hs.keyFile = certFile, keyFile
+		hs.tls = true
+	}
+}
+
+// WithTLSConfig makes the server serve HTTPS with cfg, which must provide the certificates,
+// e.g. through Certificates or GetCertificate as set up by autocert.
+func WithTLSConfig(cfg *tls.Config) HttpOption {
+	return func(hs *Http) {
+		if cfg != nil {
+			hs.srv.TLSConfig = cfg
+			hs.tls = true
+		}
+	}
+}
+
+// WithHTTPRedirect starts a second server on addr, e.g. ":80", that redirects every request
+// to the same URL on the HTTPS server. It only applies together with WithTLS or WithTLSConfig,
+// and is shut down with the main server.
+func WithHTTPRedirect(addr string) HttpOption {
+	return func(hs *Http) {
+		hs.redirectAddr = addr
+	}
+}
+
+// serve runs the main server until it is shut down.
+func (hs *Http) serve() error {
+	ln := hs.listener
+	if ln == nil && hs.maxConns > 0 {
+		addr := hs.srv.Addr
+		if addr == "" {
+			addr = ":http"
+			if hs.tls {
+				addr = ":https"
+			}
+		}
+		var err error
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return err
+		}
+	}
+
+	if ln != nil {
+		if hs.maxConns > 0 {
+			ln = netutil.LimitListener(ln, hs.maxConns)
+		}
+		hs.logger.Infof("starting server on: %s", ln.Addr())
+		if hs.tls {
+			return hs.srv.ServeTLS(ln, hs.certFile, hs.keyFile)
+		}
+		return hs.srv.Serve(ln)
+	}
+
+	hs.logger.Infof("starting server on: %s", hs.srv.Addr)
+	if hs.tls {
+		return hs.srv.ListenAndServeTLS(hs.certFile, hs.keyFile)
+	}
+	return hs.srv.ListenAndServe()
+}
+
+// newRedirectServer returns the HTTP→HTTPS redirect server, or nil if there is none.
+func (hs *Http) newRedirectServer() *http.Server {
+	if !hs.tls || hs.redirectAddr == "" {
+		return nil
+	}
+
+	addr := hs.srv.Addr
+	if hs.listener != nil {
+		addr = hs.listener.Addr().String()
+	}
+	_, httpsPort, _ := net.SplitHostPort(addr)
+
+	return &http.Server{
+		Addr:              hs.redirectAddr,
+		ReadHeaderTimeout: hs.timeout,
+		Handler:           httpsRedirectHandler(httpsPort),
+	}
+}
+
+// httpsRedirectHandler redirects to the request's URL with the https scheme and httpsPort,
+// permanently. Methods other than GET and HEAD get 308 so clients repeat them unchanged.
+func httpsRedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host += ":" + httpsPort
+		}
+
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
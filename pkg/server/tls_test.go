@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 and its key to dir.
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	return ln.Addr().String()
+}
+
+func TestTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+	insecure := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	redirectAddr := freeAddr(t)
+
+	hs, err := NewServer(
+		&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto + " secure"))
+		})},
+		WithLogger(simple.NewLogger("test", true, 0)),
+		WithListener(ln),
+		WithTLS(certFile, keyFile),
+		WithHTTPRedirect(redirectAddr),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- hs.Run(ctx) }()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = insecure.Get("https://" + ln.Addr().String() + "/orders")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, resp.TLS)
+	_ = resp.Body.Close()
+
+	require.Eventually(t, func() bool {
+		resp, err = insecure.Get("http://" + redirectAddr + "/orders?page=2")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	assert.Equal(t, "https://127.0.0.1:"+port+"/orders?page=2", resp.Header.Get("Location"))
+	_ = resp.Body.Close()
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	for _, tc := range []struct {
+		name, method, host, port, location string
+		code                               int
+	}{
+		{"default port", http.MethodGet, "example.com", "443", "https://example.com/a?b=c", http.StatusMovedPermanently},
+		{"custom port", http.MethodHead, "example.com:8080", "8443", "https://example.com:8443/a?b=c", http.StatusMovedPermanently},
+		{"ipv6", http.MethodGet, "[::1]:80", "", "https://[::1]/a?b=c", http.StatusMovedPermanently},
+		{"post keeps method", http.MethodPost, "example.com", "443", "https://example.com/a?b=c", http.StatusPermanentRedirect},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "/a?b=c", nil)
+			req.Host = tc.host
+
+			httpsRedirectHandler(tc.port).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+			assert.Equal(t, tc.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestShutdownWhenRedirectFails(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	redirectAddr := freeAddr(t)
+
+	var released bool
+	hs, err := NewServer(
+		&http.Server{Handler: http.NotFoundHandler()},
+		WithLogger(simple.NewLogger("test", true, 0)),
+		WithListener(ln),
+		WithTLS(certFile, keyFile),
+		WithHTTPRedirect(redirectAddr),
+		WithShutdownTimeout(100*time.Millisecond),
+		WithShutdownHook(func() error {
+			released = true
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- hs.Run(ctx) }()
+
+	// A connection that never sends a request keeps the redirect server from shutting down in time.
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", redirectAddr)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	defer func() { _ = conn.Close() }()
+
+	cancel()
+	err = <-done
+	assert.ErrorContains(t, err, "error shutting down HTTPS redirect")
+	assert.True(t, released, "shutdown hooks still run")
+
+	_, err = net.DialTimeout("tcp", ln.Addr().String(), 100*time.Millisecond)
+	assert.Error(t, err, "the main server is closed")
+}
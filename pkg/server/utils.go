@@ -1,9 +1,14 @@
 package server
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
 	"github.com/itsLeonB/ezutil/v2"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
 	"github.com/itsLeonB/ginkgo/pkg/response"
 	"github.com/itsLeonB/ungerr"
 	"go.opentelemetry.io/otel"
@@ -43,12 +48,36 @@ func GetRequiredPathParam[T any](ctx *gin.Context, key string) (T, error) {
 	return ezutil.Parse[T](paramValue)
 }
 
+// GetUUIDParam extracts a required path parameter holding a UUID, such as a resource ID.
+// Unlike GetRequiredPathParam, a malformed value is reported as a 400 Bad Request error
+// naming the parameter rather than an internal error.
+func GetUUIDParam(ctx *gin.Context, key string) (uuid.UUID, error) {
+	paramValue, exists := ctx.Params.Get(key)
+	if !exists {
+		return uuid.Nil, ungerr.Unknownf("missing path param: %s", key)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, middleware.WithErrorCode(
+			ungerr.BadRequestError(fmt.Sprintf("path param %s must be a valid UUID", key)),
+			middleware.ErrorCodeInvalidFieldValue,
+		)
+	}
+
+	return id, nil
+}
+
 // BindRequest binds the incoming HTTP request to a struct of type T using the specified binding type.
 // It supports various Gin binding types such as JSON, XML, Query, etc.
+// Fields the request leaves out keep the value of their default tag, e.g. `default:"20"`.
 // Returns the bound struct or an error if binding fails.
 func BindRequest[T any](ctx *gin.Context, bindType binding.Binding) (T, error) {
 	var zero T
 
+	if err := applyDefaults(&zero); err != nil {
+		return zero, ungerr.Wrap(err, "failed to apply request defaults")
+	}
 	if err := ctx.ShouldBindWith(&zero, bindType); err != nil {
 		return zero, ungerr.Wrapf(err, "failed to bind request with type %s", bindType.Name())
 	}
@@ -56,8 +85,12 @@ func BindRequest[T any](ctx *gin.Context, bindType binding.Binding) (T, error) {
 	return zero, nil
 }
 
+// BindJSON is like BindRequest with binding.JSON.
 func BindJSON[T any](ctx *gin.Context) (T, error) {
 	var zero T
+	if err := applyDefaults(&zero); err != nil {
+		return zero, ungerr.Wrap(err, "failed to apply request defaults")
+	}
 	if err := ctx.ShouldBindJSON(&zero); err != nil {
 		return zero, ungerr.Wrap(err, "failed to bind JSON request")
 	}
@@ -67,6 +100,8 @@ func BindJSON[T any](ctx *gin.Context) (T, error) {
 // GetFromContext retrieves a value from the Gin context and type-asserts it to type T.
 // Returns the typed value or an error if the key does not exist or type assertion fails.
 // Useful for retrieving typed data stored in context by middleware.
+// Values stored by your own middlewares are better kept under a middleware.ContextKey,
+// which can't collide with other keys.
 func GetFromContext[T any](ctx *gin.Context, key string) (T, error) {
 	var zero T
 
@@ -83,6 +118,18 @@ func GetFromContext[T any](ctx *gin.Context, key string) (T, error) {
 	return asserted, nil
 }
 
+// MustGetFromContext is like GetFromContext for values that a preceding middleware
+// guarantees, such as the authenticated user. If the key does not exist or the value is
+// not a T, it aborts the request with middleware.PanicWithError, which the error middleware
+// answers with a 500 Internal Server Error, so handlers need no error plumbing for it.
+func MustGetFromContext[T any](ctx *gin.Context, key string) T {
+	val, err := GetFromContext[T](ctx, key)
+	if err != nil {
+		middleware.PanicWithError(ctx, err)
+	}
+	return val
+}
+
 // GetAndParseFromContext retrieves a string value from the Gin context and parses it to type T.
 // It combines GetFromContext and Parse operations in a single function call.
 // Returns the parsed value or an error if the key doesn't exist or parsing fails.
@@ -105,9 +152,28 @@ func Handler(handlerName string, successCode int, handler func(ctx *gin.Context)
 		defer span.End()
 
 		if resp, err := handler(ctx); err == nil {
-			ctx.JSON(successCode, response.JSONResponse{Data: resp})
+			response.Render(ctx, successCode, response.JSONResponse{Data: resp})
 		} else {
 			_ = ctx.Error(err)
 		}
 	}
 }
+
+// ListHandler is like Handler for list endpoints. The handler returns a page of items, the
+// total number of items and the pagination it used, from which the response's pagination
+// metadata is computed with WithPagination. It responds 200 OK.
+func ListHandler[T any](handlerName string, handler func(ctx *gin.Context) (items T, total int, opts response.QueryOptions, err error)) gin.HandlerFunc {
+	tracer := otel.GetTracerProvider().Tracer(packageName)
+	return func(ctx *gin.Context) {
+		c, span := tracer.Start(ctx.Request.Context(), handlerName)
+		ctx.Request = ctx.Request.WithContext(c)
+		defer span.End()
+
+		items, total, opts, err := handler(ctx)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		response.Render(ctx, http.StatusOK, response.NewResponse(items).WithPagination(opts, total))
+	}
+}
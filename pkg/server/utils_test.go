@@ -8,6 +8,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/response"
 	"github.com/itsLeonB/ginkgo/pkg/server"
 	"github.com/stretchr/testify/assert"
 )
@@ -69,6 +73,44 @@ func TestGetRequiredPathParam(t *testing.T) {
 	})
 }
 
+func TestGetUUIDParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid param", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Params = gin.Params{{Key: "id", Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}}
+
+		val, err := server.GetUUIDParam(c, "id")
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"), val)
+	})
+
+	t.Run("missing param", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+		_, err := server.GetUUIDParam(c, "id")
+		assert.ErrorContains(t, err, "missing path param: id")
+	})
+
+	t.Run("malformed param", func(t *testing.T) {
+		mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+		r := gin.New()
+		r.Use(mp.NewErrorMiddleware())
+		r.GET("/orders/:id", func(ctx *gin.Context) {
+			if _, err := server.GetUUIDParam(ctx, "id"); err != nil {
+				_ = ctx.Error(err)
+			}
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/not-a-uuid", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "path param id must be a valid UUID")
+		assert.Contains(t, w.Body.String(), string(middleware.ErrorCodeInvalidFieldValue))
+	})
+}
+
 func TestBindJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -155,6 +197,39 @@ func TestBindRequest(t *testing.T) {
 	})
 }
 
+func TestMustGetFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("userID", "user-1")
+	})
+	r.GET("/ok", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, server.MustGetFromContext[string](ctx, "userID"))
+	})
+	r.GET("/missing", func(ctx *gin.Context) {
+		server.MustGetFromContext[string](ctx, "tenantID")
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/wrong-type", func(ctx *gin.Context) {
+		server.MustGetFromContext[int](ctx, "userID")
+		ctx.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user-1", w.Body.String())
+
+	for _, path := range []string{"/missing", "/wrong-type"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code, path)
+	}
+}
+
 func TestGetAndParseFromContext(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -215,3 +290,39 @@ func TestHandler(t *testing.T) {
 		assert.Len(t, c.Errors, 1)
 	})
 }
+
+func TestListHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/orders?page=2&limit=2", nil)
+
+		handler := server.ListHandler("TestListHandler.success", func(ctx *gin.Context) ([]string, int, response.QueryOptions, error) {
+			opts, err := server.BindRequest[response.QueryOptions](ctx, binding.Query)
+			return []string{"c", "d"}, 5, opts, err
+		})
+
+		handler(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"data": ["c", "d"],
+			"pagination": {"totalData": 5, "currentPage": 2, "totalPages": 3, "hasNextPage": true, "hasPrevPage": true}
+		}`, w.Body.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/orders", nil)
+
+		handler := server.ListHandler("TestListHandler.error", func(ctx *gin.Context) ([]string, int, response.QueryOptions, error) {
+			return nil, 0, response.QueryOptions{}, assert.AnError
+		})
+
+		handler(c)
+		assert.Len(t, c.Errors, 1)
+		assert.False(t, c.Writer.Written())
+	})
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ungerr"
+)
+
+// VersioningConfig configures NewAPIVersions.
+type VersioningConfig struct {
+	// Versions lists the API versions from oldest to newest, e.g. "v1", "v2".
+	Versions []string
+	// Default is the version of unprefixed requests that don't ask for one. Defaults to the
+	// oldest version, so clients that never opted into versioning keep their behaviour.
+	Default string
+	// Vendor enables vendor media types, e.g. "acme" accepts "application/vnd.acme.v2+json".
+	// The version media type parameter, e.g. "application/json; version=2", is always accepted.
+	Vendor string
+}
+
+// Deprecation describes the deprecation of an API version or handler, sent with the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers.
+type Deprecation struct {
+	// Since is when the API was deprecated. If zero, "Deprecation: true" is sent.
+	Since time.Time
+	// Sunset is when the API will stop working. Omitted if zero.
+	Sunset time.Time
+	// Link points to migration documentation. Omitted if empty.
+	Link string
+}
+
+// APIVersions routes requests to the handler of the API version they ask for, either with a
+// path prefix, as in /v2/users, or on the unprefixed path with the Accept header. Each
+// version inherits the routes of the previous one unless it registers its own handler, so
+// a new version only declares what changed. Register the routes with Register once all
+// handlers are added.
+type APIVersions struct {
+	cfg          VersioningConfig
+	vendorType   *regexp.Regexp
+	routes       []versionedRoute
+	deprecations map[string]Deprecation
+}
+
+type versionedRoute struct {
+	method   string
+	path     string
+	handlers map[string]gin.HandlerFunc
+}
+
+// NewAPIVersions creates an empty set of versioned routes.
+func NewAPIVersions(cfg VersioningConfig) *APIVersions {
+	if len(cfg.Versions) == 0 {
+		log.Fatal("at least one API version is required")
+	}
+	if cfg.Default == "" {
+		cfg.Default = cfg.Versions[0]
+	}
+	if !slices.Contains(cfg.Versions, cfg.Default) {
+		log.Fatalf("unknown default API version %s", cfg.Default)
+	}
+
+	av := &APIVersions{cfg: cfg, deprecations: make(map[string]Deprecation)}
+	if cfg.Vendor != "" {
+		av.vendorType = regexp.MustCompile(`^application/vnd\.` + regexp.QuoteMeta(cfg.Vendor) + `\.([^+]+)\+json$`)
+	}
+	return av
+}
+
+// Handle sets the handler of a route in version and, by inheritance, in later versions that
+// don't set their own. Route middlewares belong on the router group passed to Register.
+func (av *APIVersions) Handle(version, method, path string, handler gin.HandlerFunc) {
+	if !slices.Contains(av.cfg.Versions, version) {
+		log.Fatalf("unknown API version %s", version)
+	}
+	for i := range av.routes {
+		if av.routes[i].method == method && av.routes[i].path == path {
+			av.routes[i].handlers[version] = handler
+			return
+		}
+	}
+	av.routes = append(av.routes, versionedRoute{method, path, map[string]gin.HandlerFunc{version: handler}})
+}
+
+// Deprecate marks every route served by version as deprecated.
+func (av *APIVersions) Deprecate(version string, deprecation Deprecation) {
+	av.deprecations[version] = deprecation
+}
+
+// Register adds the routes to router: every version's routes under its path prefix, and the
+// unprefixed routes, which are dispatched on the Accept header. Requests asking for an
+// unknown version are answered with 400 Bad Request, and requests for a route that does
+// not exist yet in the version they ask for with 404 Not Found.
+func (av *APIVersions) Register(router gin.IRouter) {
+	for _, route := range av.routes {
+		for _, version := range av.cfg.Versions {
+			if handler := av.resolve(route, version); handler != nil {
+				router.Handle(route.method, "/"+version+route.path, av.serve(version, handler))
+			}
+		}
+		router.Handle(route.method, route.path, av.dispatch(route))
+	}
+}
+
+// resolve returns the handler of route in version, inherited from earlier versions if needed.
+func (av *APIVersions) resolve(route versionedRoute, version string) gin.HandlerFunc {
+	for i := slices.Index(av.cfg.Versions, version); i >= 0; i-- {
+		if handler, ok := route.handlers[av.cfg.Versions[i]]; ok {
+			return handler
+		}
+	}
+	return nil
+}
+
+func (av *APIVersions) serve(version string, handler gin.HandlerFunc) gin.HandlerFunc {
+	deprecation, deprecated := av.deprecations[version]
+	return func(ctx *gin.Context) {
+		if deprecated {
+			setDeprecationHeaders(ctx, deprecation)
+		}
+		handler(ctx)
+	}
+}
+
+func (av *APIVersions) dispatch(route versionedRoute) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		version := av.requestedVersion(ctx.GetHeader("Accept"))
+		if !slices.Contains(av.cfg.Versions, version) {
+			_ = ctx.Error(ungerr.BadRequestError("unsupported API version " + version))
+			ctx.Abort()
+			return
+		}
+		handler := av.resolve(route, version)
+		if handler == nil {
+			_ = ctx.Error(ungerr.NotFoundError(route.path + " does not exist in API version " + version))
+			ctx.Abort()
+			return
+		}
+		av.serve(version, handler)(ctx)
+	}
+}
+
+// requestedVersion returns the version asked for by the first media range of accept that
+// names one, or the default version.
+func (av *APIVersions) requestedVersion(accept string) string {
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if version, ok := params["version"]; ok {
+			if _, err := strconv.Atoi(version); err == nil {
+				version = "v" + version
+			}
+			return version
+		}
+		if av.vendorType != nil {
+			if match := av.vendorType.FindStringSubmatch(mediaType); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return av.cfg.Default
+}
+
+// Deprecated returns a middleware that marks a single route as deprecated. Use
+// APIVersions.Deprecate to deprecate a whole API version.
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		setDeprecationHeaders(ctx, deprecation)
+	}
+}
+
+func setDeprecationHeaders(ctx *gin.Context, deprecation Deprecation) {
+	if deprecation.Since.IsZero() {
+		ctx.Header("Deprecation", "true")
+	} else {
+		ctx.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+	}
+	if !deprecation.Sunset.IsZero() {
+		ctx.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		ctx.Writer.Header().Add("Link", "<"+deprecation.Link+`>; rel="deprecation"`)
+	}
+}
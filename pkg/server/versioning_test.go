@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ginkgo/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reply := func(body string) gin.HandlerFunc {
+		return func(ctx *gin.Context) { ctx.String(http.StatusOK, body) }
+	}
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	av := server.NewAPIVersions(server.VersioningConfig{Versions: []string{"v1", "v2", "v3"}, Vendor: "acme"})
+	av.Handle("v1", http.MethodGet, "/users", reply("users v1"))
+	av.Handle("v2", http.MethodGet, "/users", reply("users v2"))
+	av.Handle("v1", http.MethodGet, "/orders", reply("orders v1"))
+	av.Handle("v3", http.MethodGet, "/reports", reply("reports v3"))
+	av.Deprecate("v1", server.Deprecation{
+		Since:  time.Unix(1700000000, 0),
+		Sunset: sunset,
+		Link:   "https://example.com/migrate",
+	})
+
+	mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	av.Register(r.Group("/api"))
+
+	serve := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("path prefix", func(t *testing.T) {
+		for path, body := range map[string]string{
+			"/api/v1/users":   "users v1",
+			"/api/v2/users":   "users v2",
+			"/api/v3/users":   "users v2",
+			"/api/v3/orders":  "orders v1",
+			"/api/v3/reports": "reports v3",
+		} {
+			w := serve(path, "")
+			assert.Equal(t, http.StatusOK, w.Code, path)
+			assert.Equal(t, body, w.Body.String(), path)
+		}
+		assert.Equal(t, http.StatusNotFound, serve("/api/v1/reports", "").Code)
+	})
+
+	t.Run("accept header", func(t *testing.T) {
+		assert.Equal(t, "users v1", serve("/api/users", "").Body.String())
+		assert.Equal(t, "users v2", serve("/api/users", "application/json; version=2").Body.String())
+		assert.Equal(t, "users v2", serve("/api/users", "application/vnd.acme.v3+json").Body.String())
+		assert.Equal(t, http.StatusNotFound, serve("/api/reports", "application/json; version=v1").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("/api/users", "application/vnd.acme.v9+json").Code)
+	})
+
+	t.Run("deprecated version", func(t *testing.T) {
+		for _, w := range []*httptest.ResponseRecorder{serve("/api/v1/users", ""), serve("/api/users", "")} {
+			assert.Equal(t, "@1700000000", w.Header().Get("Deprecation"))
+			assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", w.Header().Get("Sunset"))
+			assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, w.Header().Get("Link"))
+		}
+		assert.Empty(t, serve("/api/v2/users", "").Header().Get("Deprecation"))
+	})
+}
+
+func TestDeprecated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/legacy", server.Deprecated(server.Deprecation{}), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legacy", nil))
+
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Empty(t, w.Header().Get("Link"))
+}
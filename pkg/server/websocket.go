@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/itsLeonB/ungerr"
+)
+
+const (
+	defaultPingInterval        = 30 * time.Second
+	defaultPongTimeout         = 60 * time.Second
+	defaultControlWriteTimeout = 10 * time.Second
+)
+
+// WebsocketHandler serves an upgraded websocket connection. ctx is the Gin context of the
+// upgrade request, so values set by earlier middlewares, such as the principal of the auth
+// middleware, the request ID and the request logger, are available as usual.
+// ctx.Request.Context() is canceled when the server shuts down. The handler must keep
+// reading from conn, which is how pongs and close frames are processed; reads fail once the
+// client disconnects or stops answering pings. conn is closed when the handler returns.
+type WebsocketHandler func(ctx *gin.Context, conn *websocket.Conn) error
+
+// WebsocketOption configures optional behaviour of WrapWebsocketHandler.
+type WebsocketOption func(*websocketConfig)
+
+type websocketConfig struct {
+	upgrader     websocket.Upgrader
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+}
+
+// WithUpgrader sets the upgrader used for the handshake, e.g. to set buffer sizes or a
+// CheckOrigin function. By default only same-origin requests are upgraded.
+func WithUpgrader(upgrader websocket.Upgrader) WebsocketOption {
+	return func(cfg *websocketConfig) {
+		cfg.upgrader = upgrader
+	}
+}
+
+// WithPingInterval sets how often a ping is sent and how long a pong may take to arrive
+// before reads fail. The defaults are 30 and 60 seconds.
+func WithPingInterval(interval, pongTimeout time.Duration) WebsocketOption {
+	return func(cfg *websocketConfig) {
+		if interval > 0 && pongTimeout > 0 {
+			cfg.pingInterval = interval
+			cfg.pongTimeout = pongTimeout
+		}
+	}
+}
+
+// WrapWebsocketHandler creates a Gin handler that upgrades the request to a websocket and
+// serves it with handler. Requests that are not a valid websocket handshake are attached to
+// the context as a 400, 403 or 405 AppError for the error middleware to answer, like any
+// other handler error. After the upgrade the connection is pinged to detect dead clients,
+// and it is closed with a "going away" close frame when the *http.Server shuts down.
+// Errors returned by handler are logged with the request logger, and the client receives
+// an "internal error" close frame.
+func WrapWebsocketHandler(handler WebsocketHandler, opts ...WebsocketOption) gin.HandlerFunc {
+	cfg := websocketConfig{
+		pingInterval: defaultPingInterval,
+		pongTimeout:  defaultPongTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(ctx *gin.Context) {
+		var upgradeErr error
+		upgrader := cfg.upgrader
+		upgrader.Error = func(_ http.ResponseWriter, _ *http.Request, status int, reason error) {
+			upgradeErr = websocketUpgradeError(status, reason)
+		}
+
+		conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+		if err != nil {
+			if upgradeErr == nil {
+				upgradeErr = ungerr.Wrap(err, "error upgrading to websocket")
+			}
+			_ = ctx.Error(upgradeErr)
+			ctx.Abort()
+			return
+		}
+		defer conn.Close()
+
+		sessionCtx, cancel := context.WithCancel(ctx.Request.Context())
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(sessionCtx)
+
+		session := &websocketSession{conn: conn, cancel: cancel}
+		registry := websocketRegistryFor(ctx.Request)
+		if !registry.add(session) {
+			session.goAway()
+			return
+		}
+		defer registry.remove(session)
+
+		stopPings := keepAlive(conn, cfg.pingInterval, cfg.pongTimeout)
+		defer stopPings()
+
+		if err := handler(ctx, conn); err != nil && !isExpectedClose(err) {
+			middleware.GetLogger(ctx).WithError(err).Error("websocket handler failed")
+			_ = conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "internal error"),
+				time.Now().Add(defaultControlWriteTimeout),
+			)
+		}
+	}
+}
+
+func websocketUpgradeError(status int, reason error) error {
+	switch status {
+	case http.StatusForbidden:
+		return ungerr.ForbiddenError(reason.Error())
+	case http.StatusMethodNotAllowed:
+		return ungerr.MethodNotAllowedError(reason.Error())
+	default:
+		return ungerr.BadRequestError(reason.Error())
+	}
+}
+
+func isExpectedClose(err error) bool {
+	return websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) ||
+		errors.Is(err, context.Canceled)
+}
+
+// keepAlive pings conn every interval and fails reads if no pong arrives within pongTimeout.
+// The returned function stops the pings.
+func keepAlive(conn *websocket.Conn, interval, pongTimeout time.Duration) func() {
+	_ = conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				deadline := time.Now().Add(defaultControlWriteTimeout)
+				if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+type websocketSession struct {
+	conn   *websocket.Conn
+	cancel context.CancelFunc
+}
+
+// goAway tells the client the server is shutting down and cancels the handler's context.
+// Reads fail once the client answers the close frame, or after the control write timeout.
+func (ws *websocketSession) goAway() {
+	deadline := time.Now().Add(defaultControlWriteTimeout)
+	_ = ws.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+		deadline,
+	)
+	_ = ws.conn.SetReadDeadline(deadline)
+	ws.cancel()
+}
+
+// websocketRegistry tracks the websockets of an *http.Server, which does not close hijacked
+// connections on Shutdown itself.
+type websocketRegistry struct {
+	mu       sync.Mutex
+	sessions map[*websocketSession]struct{}
+	closed   bool
+}
+
+var websocketRegistries sync.Map // *http.Server -> *websocketRegistry
+
+// websocketRegistryFor returns the registry of the server handling req, hooking it into the
+// server's shutdown the first time. Requests not served by an *http.Server, as in tests that
+// call ServeHTTP directly, get a registry of their own.
+func websocketRegistryFor(req *http.Request) *websocketRegistry {
+	registry := &websocketRegistry{sessions: make(map[*websocketSession]struct{})}
+	srv, ok := req.Context().Value(http.ServerContextKey).(*http.Server)
+	if !ok {
+		return registry
+	}
+	existing, loaded := websocketRegistries.LoadOrStore(srv, registry)
+	if loaded {
+		return existing.(*websocketRegistry)
+	}
+	srv.RegisterOnShutdown(func() {
+		websocketRegistries.Delete(srv)
+		registry.shutdown()
+	})
+	return registry
+}
+
+func (wr *websocketRegistry) add(session *websocketSession) bool {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	if wr.closed {
+		return false
+	}
+	wr.sessions[session] = struct{}{}
+	return true
+}
+
+func (wr *websocketRegistry) remove(session *websocketSession) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	delete(wr.sessions, session)
+}
+
+func (wr *websocketRegistry) shutdown() {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.closed = true
+	for session := range wr.sessions {
+		session.goAway()
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/itsLeonB/ezutil/v2/simple"
+	"github.com/itsLeonB/ginkgo/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebsocketRouter(handler WebsocketHandler, opts ...WebsocketOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	mp := middleware.NewMiddlewareProvider(simple.NewLogger("test", true, 0))
+
+	r := gin.New()
+	r.Use(mp.NewErrorMiddleware())
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("principal", "user-1")
+	})
+	r.GET("/ws", WrapWebsocketHandler(handler, opts...))
+	return r
+}
+
+func dialWebsocket(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func echo(ctx *gin.Context, conn *websocket.Conn) error {
+	principal := ctx.GetString("principal")
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(principal+": "+string(msg))); err != nil {
+			return err
+		}
+	}
+}
+
+func TestWrapWebsocketHandler(t *testing.T) {
+	t.Run("echo with context values", func(t *testing.T) {
+		srv := httptest.NewServer(newWebsocketRouter(echo))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv.URL)
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "user-1: hello", string(msg))
+	})
+
+	t.Run("plain request goes through error middleware", func(t *testing.T) {
+		r := newWebsocketRouter(echo)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.NotEmpty(t, body["errors"])
+	})
+
+	t.Run("handler error closes with internal error", func(t *testing.T) {
+		srv := httptest.NewServer(newWebsocketRouter(func(ctx *gin.Context, conn *websocket.Conn) error {
+			return errors.New("boom")
+		}))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv.URL)
+		_, _, err := conn.ReadMessage()
+
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseInternalServerErr), err)
+	})
+
+	t.Run("pings keep the connection alive", func(t *testing.T) {
+		srv := httptest.NewServer(newWebsocketRouter(echo, WithPingInterval(10*time.Millisecond, 50*time.Millisecond)))
+		defer srv.Close()
+
+		conn := dialWebsocket(t, srv.URL)
+		pings := make(chan struct{}, 16)
+		conn.SetPingHandler(func(data string) error {
+			pings <- struct{}{}
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for range 3 {
+			select {
+			case <-pings:
+			case <-time.After(time.Second):
+				t.Fatal("no ping received")
+			}
+		}
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("still here")))
+	})
+
+	t.Run("server shutdown sends going away", func(t *testing.T) {
+		canceled := make(chan struct{})
+		handler := func(ctx *gin.Context, conn *websocket.Conn) error {
+			err := echo(ctx, conn)
+			<-ctx.Request.Context().Done()
+			close(canceled)
+			return err
+		}
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := &http.Server{Handler: newWebsocketRouter(handler)}
+		go func() { _ = srv.Serve(ln) }()
+
+		conn := dialWebsocket(t, "http://"+ln.Addr().String())
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+		_, _, err = conn.ReadMessage()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, srv.Shutdown(ctx))
+
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("handler context not canceled")
+		}
+	})
+}
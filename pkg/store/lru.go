@@ -0,0 +1,104 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key  string
+	item memoryItem
+}
+
+// LRUStore is an in-process Store holding at most a fixed number of keys,
+// evicting the least recently used one when full. It suits caches, where
+// an unbounded MemoryStore could grow without limit.
+type LRUStore struct {
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+	mu       sync.Mutex
+}
+
+// NewLRUStore creates an empty LRUStore holding up to capacity keys.
+func NewLRUStore(capacity int) *LRUStore {
+	return &LRUStore{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (ls *LRUStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	elem, exists := ls.entries[key]
+	if !exists {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if entry.item.expired(time.Now()) {
+		ls.remove(elem)
+		return nil, false, nil
+	}
+	ls.order.MoveToFront(elem)
+	return entry.item.value, true, nil
+}
+
+func (ls *LRUStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.set(key, value, ttl)
+	return nil
+}
+
+func (ls *LRUStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if elem, exists := ls.entries[key]; exists && !elem.Value.(*lruEntry).item.expired(time.Now()) {
+		return false, nil
+	}
+	ls.set(key, value, ttl)
+	return true, nil
+}
+
+func (ls *LRUStore) Delete(_ context.Context, key string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if elem, exists := ls.entries[key]; exists {
+		ls.remove(elem)
+	}
+	return nil
+}
+
+// Len returns the number of keys held, including expired ones not yet evicted.
+func (ls *LRUStore) Len() int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	return ls.order.Len()
+}
+
+func (ls *LRUStore) set(key string, value []byte, ttl time.Duration) {
+	if elem, exists := ls.entries[key]; exists {
+		elem.Value.(*lruEntry).item = newMemoryItem(value, ttl)
+		ls.order.MoveToFront(elem)
+		return
+	}
+
+	ls.entries[key] = ls.order.PushFront(&lruEntry{key, newMemoryItem(value, ttl)})
+	if ls.order.Len() > ls.capacity {
+		ls.remove(ls.order.Back())
+	}
+}
+
+func (ls *LRUStore) remove(elem *list.Element) {
+	ls.order.Remove(elem)
+	delete(ls.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("set and get", func(t *testing.T) {
+		s := NewLRUStore(2)
+		assert.NoError(t, s.Set(ctx, "key", []byte("value"), 0))
+
+		val, exists, err := s.Get(ctx, "key")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, []byte("value"), val)
+
+		_, exists, err = s.Get(ctx, "missing")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		s := NewLRUStore(2)
+		assert.NoError(t, s.Set(ctx, "a", []byte("1"), 0))
+		assert.NoError(t, s.Set(ctx, "b", []byte("2"), 0))
+		_, _, _ = s.Get(ctx, "a")
+		assert.NoError(t, s.Set(ctx, "c", []byte("3"), 0))
+
+		assert.Equal(t, 2, s.Len())
+		_, exists, _ := s.Get(ctx, "b")
+		assert.False(t, exists)
+		_, exists, _ = s.Get(ctx, "a")
+		assert.True(t, exists)
+		_, exists, _ = s.Get(ctx, "c")
+		assert.True(t, exists)
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		s := NewLRUStore(2)
+		assert.NoError(t, s.Set(ctx, "short", []byte("value"), time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+
+		_, exists, err := s.Get(ctx, "short")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, 0, s.Len())
+	})
+
+	t.Run("set if not exists", func(t *testing.T) {
+		s := NewLRUStore(2)
+		stored, err := s.SetNX(ctx, "once", []byte("first"), 0)
+		assert.NoError(t, err)
+		assert.True(t, stored)
+
+		stored, err = s.SetNX(ctx, "once", []byte("second"), 0)
+		assert.NoError(t, err)
+		assert.False(t, stored)
+
+		val, _, _ := s.Get(ctx, "once")
+		assert.Equal(t, []byte("first"), val)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s := NewLRUStore(2)
+		assert.NoError(t, s.Set(ctx, "deleted", []byte("value"), 0))
+		assert.NoError(t, s.Delete(ctx, "deleted"))
+
+		_, exists, err := s.Get(ctx, "deleted")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (mi memoryItem) expired(now time.Time) bool {
+	return !mi.expiresAt.IsZero() && now.After(mi.expiresAt)
+}
+
+// MemoryStore is an in-process Store for single-instance deployments and tests.
+// Expired keys are removed lazily on access and by a background sweep.
+type MemoryStore struct {
+	items map[string]memoryItem
+	mu    sync.RWMutex
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	ms := &MemoryStore{items: make(map[string]memoryItem)}
+	go ms.cleanupExpired()
+	return ms
+}
+
+func (ms *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	ms.mu.RLock()
+	item, exists := ms.items[key]
+	ms.mu.RUnlock()
+
+	if !exists || item.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return item.value, true, nil
+}
+
+func (ms *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.items[key] = newMemoryItem(value, ttl)
+	return nil
+}
+
+func (ms *MemoryStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if item, exists := ms.items[key]; exists && !item.expired(time.Now()) {
+		return false, nil
+	}
+	ms.items[key] = newMemoryItem(value, ttl)
+	return true, nil
+}
+
+func (ms *MemoryStore) Delete(_ context.Context, key string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.items, key)
+	return nil
+}
+
+func newMemoryItem(value []byte, ttl time.Duration) memoryItem {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	return item
+}
+
+func (ms *MemoryStore) cleanupExpired() {
+	for {
+		time.Sleep(time.Minute)
+		now := time.Now()
+		ms.mu.Lock()
+		for key, item := range ms.items {
+			if item.expired(now) {
+				delete(ms.items, key)
+			}
+		}
+		ms.mu.Unlock()
+	}
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	t.Run("set and get", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "key", []byte("value"), 0))
+
+		val, exists, err := s.Get(ctx, "key")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, []byte("value"), val)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, exists, err := s.Get(ctx, "missing")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "short", []byte("value"), time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+
+		_, exists, err := s.Get(ctx, "short")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("set if not exists", func(t *testing.T) {
+		stored, err := s.SetNX(ctx, "once", []byte("first"), 0)
+		assert.NoError(t, err)
+		assert.True(t, stored)
+
+		stored, err = s.SetNX(ctx, "once", []byte("second"), 0)
+		assert.NoError(t, err)
+		assert.False(t, stored)
+
+		val, _, _ := s.Get(ctx, "once")
+		assert.Equal(t, []byte("first"), val)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "deleted", []byte("value"), 0))
+		assert.NoError(t, s.Delete(ctx, "deleted"))
+
+		_, exists, err := s.Get(ctx, "deleted")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store backed by Redis, for state shared across instances
+// (revocation lists, sessions, rate limits). Keys are namespaced with a prefix.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a RedisStore using client. The prefix, e.g. "myapp:",
+// is prepended to every key so several applications can share a Redis database.
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client, prefix}
+}
+
+func (rs *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	val, err := rs.client.Get(ctx, rs.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
+}
+
+func (rs *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return rs.client.Set(ctx, rs.prefix+key, value, ttl).Err()
+}
+
+func (rs *RedisStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return rs.client.SetNX(ctx, rs.prefix+key, value, ttl).Result()
+}
+
+func (rs *RedisStore) Delete(ctx context.Context, key string) error {
+	return rs.client.Del(ctx, rs.prefix+key).Err()
+}
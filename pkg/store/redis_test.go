@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRedisStore runs against the Redis server at REDIS_ADDR and is skipped if it is not set.
+func TestRedisStore(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer func() { _ = client.Close() }()
+	s := NewRedisStore(client, "ginkgo-test:")
+
+	t.Run("set and get", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "key", []byte("value"), time.Minute))
+
+		val, exists, err := s.Get(ctx, "key")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, []byte("value"), val)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, exists, err := s.Get(ctx, "missing")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("set nx", func(t *testing.T) {
+		assert.NoError(t, s.Delete(ctx, "once"))
+
+		stored, err := s.SetNX(ctx, "once", []byte("1"), time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, stored)
+
+		stored, err = s.SetNX(ctx, "once", []byte("2"), time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, stored)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, s.Set(ctx, "gone", []byte("value"), time.Minute))
+		assert.NoError(t, s.Delete(ctx, "gone"))
+
+		_, exists, err := s.Get(ctx, "gone")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
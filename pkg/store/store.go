@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a minimal key-value store with per-key expiry, shared by the middlewares
+// that need state across requests (token revocation, sessions, one-time tokens, caching).
+// A zero ttl means the key never expires.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value only if key does not exist yet, reporting whether it was stored.
+	// It must be atomic so it can guard single-use operations.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, key string) error
+}